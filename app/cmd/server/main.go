@@ -14,16 +14,29 @@
 
 // @schemes http https
 
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+// @description                "Bearer " followed by the import API token or a JWT from the configured issuer
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in                         header
+// @name                       X-API-Key
+// @description                API key with the reader, editor, importer or admin role
+
 package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/joho/godotenv"
 	_ "github.com/przemekk6973/swift-code-app/app/docs"
 	"github.com/przemekk6973/swift-code-app/app/internal/adapter/api"
 	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
 	"github.com/przemekk6973/swift-code-app/app/internal/initializer"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
 	"github.com/przemekk6973/swift-code-app/app/internal/util"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -31,6 +44,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -38,13 +53,22 @@ import (
 func main() {
 	_ = godotenv.Load(".env")
 
-	// Init Mongo repo
-	uri := os.Getenv("MONGO_URI")
-	db := os.Getenv("MONGO_DB")
-	coll := os.Getenv("MONGO_COLLECTION")
-	repo, err := persistence.NewMongoRepository(uri, db, coll)
+	// Init repository
+	driver := os.Getenv("STORAGE_DRIVER")
+	repo, err := newRepository(driver)
 	if err != nil {
-		log.Fatalf("failed to connect to mongo: %v", err)
+		log.Fatalf("failed to init %q storage: %v", driver, err)
+	}
+
+	// Cache lookups by code in front of the storage
+	cache, err := newCache()
+	if err != nil {
+		log.Fatalf("failed to init cache: %v", err)
+	}
+	var cached *persistence.CachedRepository
+	if cache != nil {
+		cached = persistence.NewCachedRepository(repo, cache)
+		repo = cached
 	}
 
 	// Load countries map
@@ -60,18 +84,127 @@ func main() {
 		countries = map[string]string{}
 	}
 
+	// Audit log and version history, kept with the codes in Mongo and in memory for other drivers
+	auditLog, err := newAuditLog(driver)
+	if err != nil {
+		log.Fatalf("failed to init audit log: %v", err)
+	}
+	history, err := newHistory(driver)
+	if err != nil {
+		log.Fatalf("failed to init version history: %v", err)
+	}
+
+	// Wire up services
+	recording := []usecases.Option{usecases.WithAuditLog(auditLog), usecases.WithHistory(history)}
+	svc := usecases.NewSwiftService(repo, append(recording, usecases.WithCountries(countries))...)
+	imports := usecases.NewImportService(repo, countries, recording...)
+
 	// Import CSV
 	if csvPath := os.Getenv("CSV_PATH"); csvPath != "" {
-		if _, err := initializer.ImportCSV(repo, csvPath, countries); err != nil {
+		var opts []initializer.Option
+		if reportPath := os.Getenv("IMPORT_REPORT_PATH"); reportPath != "" {
+			opts = append(opts, initializer.WithRejectReport(reportPath))
+		}
+		switch mode := os.Getenv("IMPORT_MODE"); mode {
+		case "", "insert":
+		case "sync":
+			opts = append(opts, initializer.WithSync())
+		default:
+			log.Fatalf("unknown IMPORT_MODE %q (want insert or sync)", mode)
+		}
+		if v := os.Getenv("IMPORT_DRY_RUN"); v != "" {
+			dryRun, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Fatalf("invalid IMPORT_DRY_RUN %q", v)
+			}
+			if dryRun {
+				opts = append(opts, initializer.WithDryRun())
+			}
+		}
+		ctx := util.WithActor(context.Background(), "startup import")
+		if _, err := imports.ImportFile(ctx, csvPath, opts...); err != nil {
 			log.Fatalf("CSV import failed: %v", err)
 		}
 	} else {
 		log.Println("CSV_PATH not set, skipping import")
 	}
 
-	// Wire up service & API
-	svc := usecases.NewSwiftService(repo)
-	router := api.SetupRouter(svc)
+	// API keys and bearer tokens, without them the API is open to everyone
+	var auths []api.Authenticator
+	apiKeys, err := newAPIKeys(driver)
+	if err != nil {
+		log.Fatalf("failed to load API keys: %v", err)
+	}
+	if apiKeys != nil {
+		auths = append(auths, api.NewAPIKeyAuthenticator(apiKeys))
+	}
+	jwtAuth, err := newJWTAuthenticator()
+	if err != nil {
+		log.Fatalf("failed to configure bearer tokens: %v", err)
+	}
+	if jwtAuth != nil {
+		auths = append(auths, jwtAuth)
+	}
+
+	// Wire up API
+	routerOpts := []api.RouterOption{api.WithAuditLog(usecases.NewAuditService(auditLog))}
+	importToken := os.Getenv("IMPORT_API_TOKEN")
+	switch {
+	case len(auths) > 0:
+		routerOpts = append(routerOpts, api.WithAuth(auths...))
+		if importToken != "" {
+			log.Println("authentication configured, IMPORT_API_TOKEN ignored in favour of the importer role")
+		}
+	case importToken == "":
+		log.Println("IMPORT_API_TOKEN not set, /v1/imports disabled")
+		fallthrough
+	default:
+		log.Println("API keys and JWT_JWKS not set, the API is open to everyone")
+	}
+	routerOpts = append(routerOpts, api.WithImports(imports, importToken))
+
+	// Per-client rate limits, counted in memory or shared by the replicas in Mongo
+	rateLimits, readLimit, writeLimit, err := newRateLimits()
+	if err != nil {
+		log.Fatalf("failed to configure rate limits: %v", err)
+	}
+	if rateLimits != nil {
+		routerOpts = append(routerOpts, api.WithRateLimit(rateLimits, readLimit, writeLimit))
+		if v := os.Getenv("RATE_LIMIT_IP"); v != "" {
+			ipLimit, err := util.ParseRateLimit(v)
+			if err != nil {
+				log.Fatalf("failed to configure rate limits: %v", err)
+			}
+			routerOpts = append(routerOpts, api.WithIPRateLimit(ipLimit))
+		}
+	}
+	// only these proxies may give the client IP in X-Forwarded-For
+	proxies, err := util.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	routerOpts = append(routerOpts, api.WithTrustedProxies(proxies...))
+	if cached != nil {
+		routerOpts = append(routerOpts, api.WithCacheStats(cached.Stats))
+	}
+	router := api.SetupRouter(svc, routerOpts...)
+
+	// Purge deleted codes after the retention period
+	retention, err := durationEnv("DELETE_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	interval, err := durationEnv("PURGE_INTERVAL", time.Hour)
+	if err != nil || interval <= 0 {
+		log.Fatalf("invalid PURGE_INTERVAL %q", os.Getenv("PURGE_INTERVAL"))
+	}
+	purgeCtx, stopPurge := context.WithCancel(util.WithActor(context.Background(), "retention purge"))
+	defer stopPurge()
+	if retention > 0 {
+		go purgeDeleted(purgeCtx, svc, retention, interval)
+	} else {
+		log.Println("DELETE_RETENTION is 0, deleted SWIFT codes are kept until restored")
+	}
 
 	// Route for Swagger API
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -98,6 +231,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("shutting down server...")
+	stopPurge()
 
 	// shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -108,12 +242,245 @@ func main() {
 		log.Printf("server forced to shutdown: %v", err)
 	}
 
-	// shutdown Mongo
+	// shutdown storage
 	if closer, ok := repo.(interface{ Close(context.Context) error }); ok {
 		if err := closer.Close(ctx); err != nil {
-			log.Printf("error closing Mongo connection: %v", err)
+			log.Printf("error closing storage: %v", err)
+		}
+	}
+	if closer, ok := auditLog.(interface{ Close(context.Context) error }); ok {
+		if err := closer.Close(ctx); err != nil {
+			log.Printf("error closing audit log: %v", err)
+		}
+	}
+	if closer, ok := history.(interface{ Close(context.Context) error }); ok {
+		if err := closer.Close(ctx); err != nil {
+			log.Printf("error closing version history: %v", err)
+		}
+	}
+	if closer, ok := apiKeys.(interface{ Close(context.Context) error }); ok {
+		if err := closer.Close(ctx); err != nil {
+			log.Printf("error closing API keys: %v", err)
+		}
+	}
+	if closer, ok := rateLimits.(interface{ Close(context.Context) error }); ok {
+		if err := closer.Close(ctx); err != nil {
+			log.Printf("error closing rate limits: %v", err)
 		}
 	}
 
 	log.Println("server exited")
 }
+
+// newRepository creates the port.SwiftRepository selected by STORAGE_DRIVER (mongo by default)
+func newRepository(driver string) (port.SwiftRepository, error) {
+	switch driver {
+	case "", "mongo":
+		uri := os.Getenv("MONGO_URI")
+		db := os.Getenv("MONGO_DB")
+		coll := os.Getenv("MONGO_COLLECTION")
+		var opts []persistence.MongoOption
+		if v := os.Getenv("MONGO_BATCH_SIZE"); v != "" {
+			size, err := strconv.Atoi(v)
+			if err != nil || size < 1 {
+				return nil, fmt.Errorf("invalid MONGO_BATCH_SIZE %q", v)
+			}
+			opts = append(opts, persistence.WithBatchSize(size))
+		}
+		return persistence.NewMongoRepository(uri, db, coll, opts...)
+	case "postgres":
+		return persistence.NewPostgresRepository(os.Getenv("POSTGRES_DSN"))
+	case "file":
+		return persistence.NewFileRepository(os.Getenv("DATA_FILE"))
+	case "memory":
+		log.Println("using in-memory storage, data is lost on exit")
+		return persistence.NewMemoryRepository(), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q (want mongo, postgres, file or memory)", driver)
+	}
+}
+
+// newCache creates the lookup cache named by CACHE, nil when it isn't set
+func newCache() (port.SwiftCodeCache, error) {
+	ttl, err := durationEnv("CACHE_TTL", 5*time.Minute)
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("invalid CACHE_TTL %q", os.Getenv("CACHE_TTL"))
+	}
+	switch backend := os.Getenv("CACHE"); backend {
+	case "":
+		log.Println("CACHE not set, lookups are not cached")
+		return nil, nil
+	case "lru":
+		size := 10000
+		if v := os.Getenv("CACHE_SIZE"); v != "" {
+			if size, err = strconv.Atoi(v); err != nil || size < 1 {
+				return nil, fmt.Errorf("invalid CACHE_SIZE %q", v)
+			}
+		}
+		return persistence.NewLRUCache(size, ttl), nil
+	case "redis":
+		return persistence.NewRedisCache(os.Getenv("REDIS_URL"), ttl)
+	default:
+		return nil, fmt.Errorf("unknown CACHE %q (want lru or redis)", backend)
+	}
+}
+
+// newAuditLog creates the port.AuditRepository for STORAGE_DRIVER: a Mongo collection
+// (MONGO_AUDIT_COLLECTION, auditLog by default) next to the codes, or memory for other drivers
+func newAuditLog(driver string) (port.AuditRepository, error) {
+	switch driver {
+	case "", "mongo":
+		coll := os.Getenv("MONGO_AUDIT_COLLECTION")
+		if coll == "" {
+			coll = "auditLog"
+		}
+		return persistence.NewMongoAuditRepository(os.Getenv("MONGO_URI"), os.Getenv("MONGO_DB"), coll)
+	default:
+		log.Println("audit log kept in memory, it is lost on exit")
+		return persistence.NewMemoryAuditRepository(), nil
+	}
+}
+
+// newHistory creates the port.HistoryRepository for STORAGE_DRIVER: a Mongo collection
+// (MONGO_HISTORY_COLLECTION, swiftCodeHistory by default) next to the codes, or memory for other drivers
+func newHistory(driver string) (port.HistoryRepository, error) {
+	switch driver {
+	case "", "mongo":
+		coll := os.Getenv("MONGO_HISTORY_COLLECTION")
+		if coll == "" {
+			coll = "swiftCodeHistory"
+		}
+		return persistence.NewMongoHistoryRepository(os.Getenv("MONGO_URI"), os.Getenv("MONGO_DB"), coll)
+	default:
+		log.Println("version history kept in memory, it is lost on exit")
+		return persistence.NewMemoryHistoryRepository(), nil
+	}
+}
+
+// newAPIKeys creates the port.APIKeyRepository from API_KEYS_FILE, or from the Mongo collection
+// MONGO_API_KEYS_COLLECTION next to the codes; nil when neither is set
+func newAPIKeys(driver string) (port.APIKeyRepository, error) {
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		return persistence.NewFileAPIKeyRepository(path)
+	}
+	coll := os.Getenv("MONGO_API_KEYS_COLLECTION")
+	switch {
+	case coll == "":
+		return nil, nil
+	case driver != "" && driver != "mongo":
+		return nil, fmt.Errorf("MONGO_API_KEYS_COLLECTION requires STORAGE_DRIVER mongo, got %q", driver)
+	}
+	return persistence.NewMongoAPIKeyRepository(os.Getenv("MONGO_URI"), os.Getenv("MONGO_DB"), coll)
+}
+
+// newJWTAuthenticator validates bearer tokens against the JWKS file or URL in JWT_JWKS; nil when it isn't set.
+// JWT_ROLE_MAP lists claim=role pairs, e.g. "swift.read=reader,swift.write=editor".
+func newJWTAuthenticator() (api.Authenticator, error) {
+	source := os.Getenv("JWT_JWKS")
+	if source == "" {
+		return nil, nil
+	}
+	var keys api.KeySet
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		refresh, err := durationEnv("JWT_JWKS_REFRESH", time.Hour)
+		if err != nil {
+			return nil, err
+		}
+		keys = api.NewRemoteJWKS(source, nil, refresh)
+	} else {
+		var err error
+		if keys, err = api.LoadJWKSFile(source); err != nil {
+			return nil, err
+		}
+	}
+
+	leeway, err := durationEnv("JWT_LEEWAY", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg := api.JWTConfig{
+		Issuer:     os.Getenv("JWT_ISSUER"),
+		Audience:   os.Getenv("JWT_AUDIENCE"),
+		RolesClaim: os.Getenv("JWT_ROLES_CLAIM"),
+		Leeway:     leeway,
+	}
+	if v := os.Getenv("JWT_ROLE_MAP"); v != "" {
+		cfg.RoleMap = map[string]string{}
+		for _, pair := range strings.Split(v, ",") {
+			claim, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || claim == "" || !models.ValidRole(role) {
+				return nil, fmt.Errorf("invalid JWT_ROLE_MAP entry %q (want claim=reader|editor|importer|admin)", pair)
+			}
+			cfg.RoleMap[claim] = role
+		}
+	}
+	return api.NewJWTAuthenticator(keys, cfg), nil
+}
+
+// newRateLimits reads RATE_LIMIT_READ and RATE_LIMIT_WRITE and creates the store named by RATE_LIMIT_STORE;
+// a nil store when neither limit is set
+func newRateLimits() (port.RateLimitStore, models.RateLimit, models.RateLimit, error) {
+	var read, write models.RateLimit
+	var err error
+	if v := os.Getenv("RATE_LIMIT_READ"); v != "" {
+		if read, err = util.ParseRateLimit(v); err != nil {
+			return nil, read, write, err
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_WRITE"); v != "" {
+		if write, err = util.ParseRateLimit(v); err != nil {
+			return nil, read, write, err
+		}
+	}
+	if !read.Enabled() && !write.Enabled() {
+		log.Println("RATE_LIMIT_READ and RATE_LIMIT_WRITE not set, requests are not rate limited")
+		return nil, read, write, nil
+	}
+
+	switch store := os.Getenv("RATE_LIMIT_STORE"); store {
+	case "", "memory":
+		return persistence.NewMemoryRateLimitStore(), read, write, nil
+	case "mongo":
+		coll := os.Getenv("MONGO_RATE_LIMIT_COLLECTION")
+		if coll == "" {
+			coll = "rateLimits"
+		}
+		s, err := persistence.NewMongoRateLimitStore(os.Getenv("MONGO_URI"), os.Getenv("MONGO_DB"), coll)
+		return s, read, write, err
+	default:
+		return nil, read, write, fmt.Errorf("unknown RATE_LIMIT_STORE %q (want memory or mongo)", store)
+	}
+}
+
+// purgeDeleted removes codes deleted more than retention ago now and then every interval, until ctx is done
+func purgeDeleted(ctx context.Context, svc *usecases.SwiftService, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := svc.PurgeDeleted(ctx, retention)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("purge of deleted SWIFT codes failed: %v", err)
+		case n > 0:
+			log.Printf("purged %d SWIFT codes deleted more than %v ago", n, retention)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// durationEnv parses a Go duration (e.g. 720h) from the environment variable, def when unset
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return d, nil
+}
@@ -23,33 +23,59 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/swift-codes": {
-            "post": {
-                "description": "Adds either a headquarter (isHeadquarter=true) or a branch (isHeadquarter=false).",
-                "consumes": [
-                    "application/json"
+        "/v1/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Returns who created, updated, deleted, restored or purged SWIFT codes and who ran imports, oldest first.\nEvery entry has the actor (the authenticated caller, or the X-Actor header without authentication), the request ID (X-Request-ID header) and the code before and after the change.\nWith authentication configured only the admin role can read it; without it the import token is required as a bearer token, and the log is open only when neither is configured.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "swift-codes"
+                    "audit"
                 ],
-                "summary": "Create a new SWIFT code entry",
+                "summary": "Audit log of changes",
                 "parameters": [
                     {
-                        "description": "SWIFT code payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.SwiftCode"
-                        }
+                        "type": "string",
+                        "description": "Only changes of this SWIFT code",
+                        "name": "swiftCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "swift code added",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid SWIFT code, time or limit",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -57,8 +83,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "invalid input or missing HQ for branch",
+                    "401": {
+                        "description": "missing or invalid API key, bearer token or import token",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -66,8 +92,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "409": {
-                        "description": "duplicate code",
+                    "403": {
+                        "description": "admin role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -87,24 +122,123 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/swift-codes/country/{countryISO2code}": {
-            "get": {
-                "description": "Returns all headquarters and branches for a given country ISO2.",
+        "/v1/imports": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Starts a background import of the uploaded CSV (same format as CSV_PATH). Poll the returned job with GET /v1/imports/{id}.",
                 "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Upload a CSV file to import",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "SWIFT codes CSV",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "missing or empty file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid token, API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "import token or importer role missing",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "file too large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the import job; once finished it contains the import summary or the error.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "swift-codes"
+                    "imports"
                 ],
-                "summary": "Retrieve all SWIFT codes for a country",
+                "summary": "Get status of an import",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Country ISO2 code",
-                        "name": "countryISO2code",
+                        "description": "Import job ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -113,11 +247,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CountrySwiftCodesResponse"
+                            "$ref": "#/definitions/models.ImportJob"
                         }
                     },
-                    "400": {
-                        "description": "invalid ISO2 format",
+                    "401": {
+                        "description": "missing or invalid token, API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "import token or importer role missing",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -126,7 +269,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "no SWIFT codes for country",
+                        "description": "import not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -134,8 +277,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "internal server error",
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -146,9 +289,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/swift-codes/{swift-code}": {
+        "/v1/swift-codes": {
             "get": {
-                "description": "Returns the headquarter with its branches if the code is HQ, or a single branch object if branch code.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns one page of headquarters and branches ordered by SWIFT code. Pass nextCursor from the response as cursor to get the next page.",
                 "consumes": [
                     "application/json"
                 ],
@@ -158,25 +309,60 @@ const docTemplate = `{
                 "tags": [
                     "swift-codes"
                 ],
-                "summary": "Retrieve details for a single SWIFT code",
+                "summary": "List SWIFT codes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "SWIFT code (8 or 11 characters)",
-                        "name": "swift-code",
-                        "in": "path",
-                        "required": true
+                        "description": "Country ISO2 code",
+                        "name": "countryISO2",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only headquarters (true) or only branches (false)",
+                        "name": "isHeadquarter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bank name prefix (case-insensitive)",
+                        "name": "bankName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Town name (case-insensitive)",
+                        "name": "town",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, e.g. Europe/Warsaw",
+                        "name": "timeZone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as nextCursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SwiftCode"
+                            "$ref": "#/definitions/models.SwiftCodeListResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid SWIFT code format",
+                        "description": "invalid filter, cursor or limit",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -184,8 +370,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "SWIFT code not found",
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -204,8 +408,16 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Deletes a headquarter (and all its branches) if code ends with XXX, or a single branch otherwise.",
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds either a headquarter (isHeadquarter=true) or a branch (isHeadquarter=false).\nWith a country list loaded, countryISO2 must be in it and countryName must be its name (any case); a missing countryName is filled in.",
                 "consumes": [
                     "application/json"
                 ],
@@ -215,19 +427,21 @@ const docTemplate = `{
                 "tags": [
                     "swift-codes"
                 ],
-                "summary": "Delete a SWIFT code entry",
+                "summary": "Create a new SWIFT code entry",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "SWIFT code to delete",
-                        "name": "swift-code",
-                        "in": "path",
-                        "required": true
+                        "description": "SWIFT code payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCode"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "swift code deleted",
+                        "description": "swift code added",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -236,7 +450,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid SWIFT code format",
+                        "description": "invalid input, unknown country ISO2, country name mismatch or missing HQ for branch",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -244,8 +458,35 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "SWIFT code not found",
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "editor role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "duplicate code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -264,33 +505,1325 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CountrySwiftCodesResponse": {
-            "type": "object",
-            "properties": {
-                "countryISO2": {
-                    "type": "string"
-                },
-                "countryName": {
-                    "type": "string"
-                },
-                "swiftCodes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.SwiftBranch"
-                    }
-                }
-            }
         },
-        "models.SwiftBranch": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "bankName": {
+        "/v1/swift-codes/country/{countryISO2code}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns one page of headquarters and branches for a given country ISO2, ordered by SWIFT code. Pass nextCursor from the response as cursor to get the next page.\nWith asOf the codes are read from the version history as they were at that time; keep the same asOf for every page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Retrieve all SWIFT codes for a country",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Country ISO2 code",
+                        "name": "countryISO2code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to look the codes up at",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as nextCursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CountrySwiftCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ISO2 format, asOf, cursor or limit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no SWIFT codes for country",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/swift-codes/lookup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a result for every requested code, in request order: hq or branch with the code details, notFound, or invalid with the reason. Codes are looked up together, not one request each.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Look up many SWIFT codes at once",
+                "parameters": [
+                    {
+                        "description": "Codes to look up (at most 1000)",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCodeLookupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCodeLookupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid JSON, no codes or too many codes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "request body too large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/swift-codes/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns headquarters and branches whose bank name or address match all words of the query, best matches first. Prefixes and small typos are tolerated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Search SWIFT codes by bank name or address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. bank name or town",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCodeSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "missing or too long query, invalid limit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/swift-codes/{swift-code}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the headquarter with its branches if the code is HQ, or a single branch object if branch code.\nThe ETag header is the version of the headquarter document (shared by its branches); send it back as If-None-Match to get 304 when nothing changed, or as If-Match on writes.\nWith asOf the code is read from the version history as it was at that time, without ETag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Retrieve details for a single SWIFT code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SWIFT code (8 or 11 characters)",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to look the code up at",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCode"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the stored headquarter"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified since the If-None-Match ETag"
+                    },
+                    "400": {
+                        "description": "invalid SWIFT code format or asOf",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "SWIFT code not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces bank name, address, country, town, code type and time zone of an existing headquarter (its branches are kept) or branch. The payload is validated like on create, a missing countryName is filled in from the country list; swiftCode may be omitted, otherwise it must match the path. A branch always shows the country name of its headquarter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Replace a SWIFT code entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SWIFT code to update",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "SWIFT code payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCode"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the stored headquarter"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input, unknown country ISO2 or country name mismatch",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "editor role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "SWIFT code not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match doesn't match the current version",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a headquarter (and all its branches) if code ends with XXX, or a single branch otherwise.\nDeleted codes are hidden but kept, with who deleted them and when, so they can be restored until purged after the retention period.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Delete a SWIFT code entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SWIFT code to delete",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who deletes the code, recorded with it and in the audit log; ignored with authentication, the caller is recorded instead",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "swift code deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid SWIFT code format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "editor role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "SWIFT code not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match doesn't match the current version",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes only the fields present in the payload of an existing headquarter or branch. SWIFT code and isHeadquarter can't be changed. The result is validated like on create.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Update fields of a SWIFT code entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SWIFT code to update",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, or * for any version",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCodePatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCode"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the stored headquarter"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input, unknown country ISO2 or country name mismatch",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "editor role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "SWIFT code not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match doesn't match the current version",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/swift-codes/{swift-code}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every version of a headquarter or branch, oldest first: its details from validFrom until validTo (the start of the next version, none for the current one).\nA deleted version keeps the details the code had when it was deleted. Headquarter versions don't list branches, each branch has its own history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Version history of a SWIFT code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SWIFT code",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCodeHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid SWIFT code format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no history of the SWIFT code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/swift-codes/{swift-code}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Brings back a deleted headquarter with the branches deleted together with it, or a deleted branch. A branch of a deleted headquarter can be restored only after its headquarter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Restore a deleted SWIFT code entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deleted SWIFT code",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SwiftCode"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the stored headquarter"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid SWIFT code format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "editor role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no deleted SWIFT code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "headquarter of the branch is deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/swift-codes/{swift-code}/validate": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Breaks the code down into its ISO 9362 parts (institution, country, location and branch code) and lists every rule it breaks, without looking it up. The country must be in the country list when one is loaded.\nTest codes (location code ending with 0) and passive participants (ending with 1) are flagged. An invalid code is still 200, with valid=false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "swift-codes"
+                ],
+                "summary": "Validate the structure of a SWIFT code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SWIFT code to validate",
+                        "name": "swift-code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BICValidation"
+                        }
+                    },
+                    "401": {
+                        "description": "missing or invalid API key or bearer token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "reader role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "$ref": "#/definitions/models.SwiftCode"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.SwiftCode"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "import": {
+                    "$ref": "#/definitions/models.ImportSummary"
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "swiftCode": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEntry"
+                    }
+                }
+            }
+        },
+        "models.BICPart": {
+            "type": "object",
+            "properties": {
+                "valid": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BICValidation": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "description": "Branch is the 3 character branch code of an 11 character code, XXX for the head office",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BICPart"
+                        }
+                    ]
+                },
+                "code": {
+                    "type": "string"
+                },
+                "country": {
+                    "$ref": "#/definitions/models.BICPart"
+                },
+                "countryName": {
+                    "type": "string"
+                },
+                "institution": {
+                    "description": "Institution is the 4 letter bank code, Country the ISO2 country, Location 2 letters or digits",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BICPart"
+                        }
+                    ]
+                },
+                "isHeadquarter": {
+                    "type": "boolean"
+                },
+                "location": {
+                    "$ref": "#/definitions/models.BICPart"
+                },
+                "passiveParticipant": {
+                    "type": "boolean"
+                },
+                "test": {
+                    "description": "Test is set for test and training codes (location ending with 0),\nPassiveParticipant for codes not connected to the SWIFT network (location ending with 1)",
+                    "type": "boolean"
+                },
+                "valid": {
+                    "type": "boolean"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BICViolation"
+                    }
+                }
+            }
+        },
+        "models.BICViolation": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string",
+                    "enum": [
+                        "length",
+                        "institutionCode",
+                        "countryCode",
+                        "unknownCountry",
+                        "locationCode",
+                        "branchCode"
+                    ]
+                }
+            }
+        },
+        "models.CountrySwiftCodesResponse": {
+            "type": "object",
+            "properties": {
+                "countryISO2": {
+                    "type": "string"
+                },
+                "countryName": {
+                    "type": "string"
+                },
+                "nextCursor": {
+                    "type": "string"
+                },
+                "swiftCodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SwiftBranch"
+                    }
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/models.ImportSummary"
+                }
+            }
+        },
+        "models.ImportSummary": {
+            "type": "object",
+            "properties": {
+                "branchesAdded": {
+                    "type": "integer"
+                },
+                "branchesDuplicate": {
+                    "type": "integer"
+                },
+                "branchesMissingHQ": {
+                    "type": "integer"
+                },
+                "branchesRemoved": {
+                    "type": "integer"
+                },
+                "branchesSkipped": {
+                    "type": "integer"
+                },
+                "branchesUpdated": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "hqAdded": {
+                    "type": "integer"
+                },
+                "hqRemoved": {
+                    "type": "integer"
+                },
+                "hqSkipped": {
+                    "type": "integer"
+                },
+                "hqUpdated": {
+                    "type": "integer"
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "rejectedByReason": {
+                    "$ref": "#/definitions/models.RejectionCounts"
+                }
+            }
+        },
+        "models.RejectionCounts": {
+            "type": "object",
+            "properties": {
+                "countryCodeMismatch": {
+                    "type": "integer"
+                },
+                "countryNameMismatch": {
+                    "type": "integer"
+                },
+                "invalidCountryISO2": {
+                    "type": "integer"
+                },
+                "invalidSwiftCode": {
+                    "type": "integer"
+                },
+                "malformedRow": {
+                    "type": "integer"
+                },
+                "unknownCountry": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SwiftBranch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "bankName": {
+                    "type": "string"
+                },
+                "codeType": {
                     "type": "string"
                 },
                 "countryISO2": {
@@ -304,6 +1837,12 @@ const docTemplate = `{
                 },
                 "swiftCode": {
                     "type": "string"
+                },
+                "timeZone": {
+                    "type": "string"
+                },
+                "townName": {
+                    "type": "string"
                 }
             }
         },
@@ -322,6 +1861,144 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.SwiftBranch"
                     }
                 },
+                "codeType": {
+                    "type": "string"
+                },
+                "countryISO2": {
+                    "type": "string"
+                },
+                "countryName": {
+                    "type": "string"
+                },
+                "isHeadquarter": {
+                    "type": "boolean"
+                },
+                "swiftCode": {
+                    "type": "string"
+                },
+                "timeZone": {
+                    "type": "string"
+                },
+                "townName": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version of the stored HQ document, a branch has the version of its HQ; sent as ETag too",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SwiftCodeHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "swiftCode": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SwiftCodeVersion"
+                    }
+                }
+            }
+        },
+        "models.SwiftCodeListResponse": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "swiftCodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SwiftBranch"
+                    }
+                }
+            }
+        },
+        "models.SwiftCodeLookupRequest": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.SwiftCodeLookupResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SwiftCodeLookupResult"
+                    }
+                }
+            }
+        },
+        "models.SwiftCodeLookupResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "hq",
+                        "branch",
+                        "notFound",
+                        "invalid"
+                    ]
+                },
+                "swiftCode": {
+                    "$ref": "#/definitions/models.SwiftCode"
+                }
+            }
+        },
+        "models.SwiftCodePatch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "bankName": {
+                    "type": "string"
+                },
+                "codeType": {
+                    "type": "string"
+                },
+                "countryISO2": {
+                    "type": "string"
+                },
+                "countryName": {
+                    "type": "string"
+                },
+                "timeZone": {
+                    "type": "string"
+                },
+                "townName": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SwiftCodeSearchHit": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "bankName": {
+                    "type": "string"
+                },
+                "codeType": {
+                    "type": "string"
+                },
                 "countryISO2": {
                     "type": "string"
                 },
@@ -331,11 +2008,66 @@ const docTemplate = `{
                 "isHeadquarter": {
                     "type": "boolean"
                 },
+                "score": {
+                    "type": "number"
+                },
                 "swiftCode": {
                     "type": "string"
+                },
+                "timeZone": {
+                    "type": "string"
+                },
+                "townName": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SwiftCodeSearchResponse": {
+            "type": "object",
+            "properties": {
+                "query": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SwiftCodeSearchHit"
+                    }
+                }
+            }
+        },
+        "models.SwiftCodeVersion": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/models.SwiftCode"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validTo": {
+                    "description": "start of the next version, set in history responses",
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key with the reader, editor, importer or admin role",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "\"Bearer \" followed by the import API token or a JWT from the configured issuer",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
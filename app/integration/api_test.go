@@ -2,18 +2,23 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/adapter/api"
 	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
-	"github.com/przemekk6973/swift-code-app/app/internal/initializer"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
 )
 
 // Integration test covering full API flow with live MongoDB.
@@ -25,9 +30,18 @@ func TestAPI_Integration(t *testing.T) {
 	// Create repo and load test data from CSV
 	repo, err := persistence.NewMongoRepository(uri, "testdb", "swiftCodes")
 	if err != nil {
-		t.Fatalf("repo init: %v", err)
+		t.Skipf("skipping Mongo integration test; cannot connect: %v", err)
 	}
+	runAPIFlow(t, repo)
+}
+
+// Integration test covering full API flow with in-memory storage, no database required.
+func TestAPI_Integration_Memory(t *testing.T) {
+	runAPIFlow(t, persistence.NewMemoryRepository())
+}
 
+// runAPIFlow imports a small CSV into repo and exercises all endpoints
+func runAPIFlow(t *testing.T, repo port.SwiftRepository) {
 	// Example csv
 	csv := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
 PL,TESTPLP1XXX,TestHQ,AddrHQ,POLAND
@@ -41,17 +55,25 @@ PL,TESTPLP1BR1,TestBR,AddrBR,POLAND
 	tmp.WriteString(csv)
 	tmp.Close()
 
-	if _, err := initializer.ImportCSV(repo, tmp.Name(), map[string]string{"PL": "POLAND"}); err != nil {
+	// Start services, import CSV and start router
+	gin.SetMode(gin.TestMode)
+	auditLog := persistence.NewMemoryAuditRepository()
+	recording := []usecases.Option{
+		usecases.WithAuditLog(auditLog),
+		usecases.WithHistory(persistence.NewMemoryHistoryRepository()),
+	}
+	countries := map[string]string{"PL": "POLAND"}
+	svc := usecases.NewSwiftService(repo, append(recording, usecases.WithCountries(countries))...)
+	imports := usecases.NewImportService(repo, countries, recording...)
+	if _, err := imports.ImportFile(context.Background(), tmp.Name()); err != nil {
 		t.Fatalf("import CSV: %v", err)
 	}
+	router := api.SetupRouter(svc,
+		api.WithImports(imports, "test-token"),
+		api.WithAuditLog(usecases.NewAuditService(auditLog)))
 
-	// Start service and router
-	gin.SetMode(gin.TestMode)
-	svc := usecases.NewSwiftService(repo)
-	router := api.SetupRouter(svc)
-
-	// Helper function
-	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+	// Helper functions, writes send If-Match when it isn't empty
+	doIf := func(method, path, ifMatch string, body interface{}) *httptest.ResponseRecorder {
 		var req *http.Request
 		if body != nil {
 			b, _ := json.Marshal(body)
@@ -60,10 +82,16 @@ PL,TESTPLP1BR1,TestBR,AddrBR,POLAND
 		} else {
 			req = httptest.NewRequest(method, path, nil)
 		}
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 		return w
 	}
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		return doIf(method, path, "", body)
+	}
 
 	// GET HQ
 	w := do("GET", "/v1/swift-codes/TESTPLP1XXX", nil)
@@ -104,9 +132,150 @@ PL,TESTPLP1BR1,TestBR,AddrBR,POLAND
 		t.Errorf("expected 2 codes, got %d", len(resp.SwiftCodes))
 	}
 
+	// GET list, one code per page
+	w = do("GET", "/v1/swift-codes?countryISO2=PL&limit=1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET list expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var page models.SwiftCodeListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.SwiftCodes) != 1 || page.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	w = do("GET", "/v1/swift-codes?countryISO2=PL&limit=1&cursor="+page.NextCursor, nil)
+	page = models.SwiftCodeListResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.SwiftCodes) != 1 || page.NextCursor != "" {
+		t.Errorf("unexpected last page: %+v", page)
+	}
+
+	// search with a typo
+	w = do("GET", "/v1/swift-codes/search?q=tesths", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET search expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var found models.SwiftCodeSearchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Results) == 0 || found.Results[0].SwiftCode != "TESTPLP1XXX" {
+		t.Errorf("unexpected search results: %+v", found)
+	}
+
+	// PUT replaces HQ details, branches are kept; the ETag of GET guards it
+	w = do("GET", "/v1/swift-codes/TESTPLP1XXX", nil)
+	etag := w.Header().Get("ETag")
+	var original models.SwiftCode
+	if err := json.Unmarshal(w.Body.Bytes(), &original); err != nil {
+		t.Fatal(err)
+	}
+	beforeUpdate := time.Now().UTC()
+	time.Sleep(time.Millisecond)
+	replacement := models.SwiftCode{
+		BankName:      "TestHQ Renamed",
+		Address:       "AddrHQ 2",
+		CountryISO2:   "PL",
+		CountryName:   "POLAND",
+		IsHeadquarter: true,
+	}
+	if w = do("PUT", "/v1/swift-codes/TESTPLP1XXX", replacement); w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("PUT HQ without If-Match expected 428, got %d: %s", w.Code, w.Body)
+	}
+	w = doIf("PUT", "/v1/swift-codes/TESTPLP1XXX", etag, replacement)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT HQ expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Errorf("PUT HQ kept ETag %s", etag)
+	}
+	if w := doIf("PUT", "/v1/swift-codes/TESTPLP1XXX", etag, replacement); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT HQ with outdated ETag expected 412, got %d: %s", w.Code, w.Body)
+	}
+	hq = models.SwiftCode{}
+	if err := json.Unmarshal(w.Body.Bytes(), &hq); err != nil {
+		t.Fatal(err)
+	}
+	if hq.BankName != "TestHQ Renamed" || len(hq.Branches) != 1 {
+		t.Errorf("unexpected updated HQ: %+v", hq)
+	}
+
+	// PATCH changes one field of a branch
+	w = doIf("PATCH", "/v1/swift-codes/TESTPLP1BR1", "*", map[string]string{"address": "AddrBR 2"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH branch expected 200, got %d: %s", w.Code, w.Body)
+	}
+	br = models.SwiftCode{}
+	if err := json.Unmarshal(w.Body.Bytes(), &br); err != nil {
+		t.Fatal(err)
+	}
+	if br.Address != "AddrBR 2" || br.BankName != "TestBR" {
+		t.Errorf("unexpected patched branch: %+v", br)
+	}
+
+	// asOf looks the HQ and its branch up as they were before the changes
+	asOf := "asOf=" + url.QueryEscape(beforeUpdate.Format(time.RFC3339Nano))
+	w = do("GET", "/v1/swift-codes/TESTPLP1XXX?"+asOf, nil)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Fatalf("GET HQ asOf expected 200 without ETag, got %d: %s", w.Code, w.Body)
+	}
+	hq = models.SwiftCode{}
+	if err := json.Unmarshal(w.Body.Bytes(), &hq); err != nil {
+		t.Fatal(err)
+	}
+	if hq.BankName != original.BankName || len(hq.Branches) != 1 || hq.Branches[0].Address != original.Branches[0].Address {
+		t.Errorf("HQ asOf = %+v; want %+v", hq, original)
+	}
+	w = do("GET", "/v1/swift-codes/country/PL?"+asOf, nil)
+	var country models.CountrySwiftCodesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &country); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(country.SwiftCodes) == 0 {
+		t.Errorf("GET country asOf expected codes, got %d: %s", w.Code, w.Body)
+	}
+	for _, sc := range country.SwiftCodes {
+		if sc.SwiftCode == "TESTPLP1XXX" && sc.BankName != original.BankName {
+			t.Errorf("country asOf has HQ %+v; want bank name %q", sc, original.BankName)
+		}
+	}
+	if w = do("GET", "/v1/swift-codes/TESTPLP1XXX?asOf=yesterday", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("GET HQ with invalid asOf expected 400, got %d", w.Code)
+	}
+
+	// history has the imported and the updated version
+	w = do("GET", "/v1/swift-codes/TESTPLP1XXX/history", nil)
+	var history models.SwiftCodeHistoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(history.Versions); w.Code != http.StatusOK || n != 2 || history.Versions[0].ValidTo == nil ||
+		history.Versions[n-1].Code.BankName != "TestHQ Renamed" {
+		t.Errorf("GET HQ history = %d: %s", w.Code, w.Body)
+	}
+
+	// POST with a country name of another country
+	w = do("POST", "/v1/swift-codes", models.SwiftCode{
+		SwiftCode: "NEWBPLPLXXX", BankName: "New Bank", Address: "New Addr", CountryISO2: "PL", CountryName: "GERMANY", IsHeadquarter: true,
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "does not match ISO2 PL") {
+		t.Fatalf("POST HQ with wrong country name = %d: %s; want 400", w.Code, w.Body)
+	}
+
+	// a branch needs its HQ stored
+	w = do("POST", "/v1/swift-codes", models.SwiftCode{
+		SwiftCode: "NOHQPLPL001", BankName: "Orphan", Address: "Nowhere", CountryISO2: "PL", CountryName: "POLAND",
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "headquarter NOHQPLPLXXX not found") {
+		t.Fatalf("POST branch without HQ = %d: %s; want 400", w.Code, w.Body)
+	}
+
 	// POST new HQ
 	newHQ := models.SwiftCode{
-		SwiftCode:     "NEWPLPPLXXX",
+		SwiftCode:     "NEWBPLPLXXX",
 		BankName:      "New Bank",
 		Address:       "New Addr",
 		CountryISO2:   "PL",
@@ -119,8 +288,140 @@ PL,TESTPLP1BR1,TestBR,AddrBR,POLAND
 	}
 
 	// DELETE new HQ
-	w = do("DELETE", "/v1/swift-codes/NEWPLPPLXXX", nil)
+	w = doIf("DELETE", "/v1/swift-codes/NEWBPLPLXXX", "*", nil)
 	if w.Code != http.StatusOK {
 		t.Fatalf("DELETE HQ expected 200, got %d: %s", w.Code, w.Body)
 	}
+
+	// deleted HQ is hidden until it is restored
+	if w = do("GET", "/v1/swift-codes/NEWBPLPLXXX", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET deleted HQ expected 404, got %d", w.Code)
+	}
+	if w = do("POST", "/v1/swift-codes/NEWBPLPLXXX/restore", nil); w.Code != http.StatusOK {
+		t.Fatalf("restore HQ expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if w = do("POST", "/v1/swift-codes", newHQ); w.Code != http.StatusConflict {
+		t.Errorf("POST restored HQ expected 409, got %d: %s", w.Code, w.Body)
+	}
+	req := httptest.NewRequest("DELETE", "/v1/swift-codes/NEWBPLPLXXX", nil)
+	req.Header.Set("If-Match", "*")
+	req.Header.Set("X-Actor", "alice")
+	req.Header.Set("X-Request-ID", "delete-newplppl")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("X-Request-ID") != "delete-newplppl" {
+		t.Fatalf("DELETE restored HQ expected 200 echoing the request ID, got %d: %s", w.Code, w.Body)
+	}
+
+	// history keeps the deleted HQ
+	w = do("GET", "/v1/swift-codes/NEWBPLPLXXX/history", nil)
+	history = models.SwiftCodeHistoryResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(history.Versions); n != 4 || !history.Versions[n-1].Deleted || history.Versions[n-1].Code.BankName != newHQ.BankName {
+		t.Errorf("deleted HQ history = %+v; want added, deleted, restored and deleted", history.Versions)
+	}
+
+	// audit log has every change of the HQ, oldest first; without authentication the import token guards it
+	readAudit := func(query, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/audit"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	if w = readAudit("", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("GET audit without token expected 401, got %d", w.Code)
+	}
+	if w = readAudit("?swiftCode=NEWBPLPLXXX", "test-token"); w.Code != http.StatusOK {
+		t.Fatalf("GET audit expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var audit models.AuditLogResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &audit); err != nil {
+		t.Fatal(err)
+	}
+	wantActions := []string{models.AuditCreate, models.AuditDelete, models.AuditRestore, models.AuditDelete}
+	if len(audit.Entries) != len(wantActions) {
+		t.Fatalf("audit entries = %+v; want %v", audit.Entries, wantActions)
+	}
+	for i, action := range wantActions {
+		if audit.Entries[i].Action != action {
+			t.Errorf("audit entry %d action = %q; want %q", i, audit.Entries[i].Action, action)
+		}
+	}
+	last := audit.Entries[len(audit.Entries)-1]
+	if last.Actor != "alice" || last.RequestID != "delete-newplppl" || last.Before == nil || last.Before.BankName != newHQ.BankName {
+		t.Errorf("last audit entry = %+v; want delete by alice with the HQ before it", last)
+	}
+	if first := audit.Entries[0]; first.Actor != "anonymous" || first.RequestID == "" {
+		t.Errorf("first audit entry = %+v; want anonymous with a generated request ID", first)
+	}
+	if w = readAudit("?from=yesterday", "test-token"); w.Code != http.StatusBadRequest {
+		t.Errorf("GET audit with invalid from expected 400, got %d", w.Code)
+	}
+
+	// upload CSV, rejected without token
+	upload := func(token string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("file", "upload.csv")
+		part.Write([]byte("COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME\nPL,UPLDPLPWXXX,Uploaded,AddrUp,POLAND\n"))
+		mw.Close()
+		req := httptest.NewRequest("POST", "/v1/imports", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	if w = upload(""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("POST import without token expected 401, got %d", w.Code)
+	}
+	if w = upload("test-token"); w.Code != http.StatusAccepted {
+		t.Fatalf("POST import expected 202, got %d: %s", w.Code, w.Body)
+	}
+	var job models.ImportJob
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); job.Status != models.ImportSucceeded; time.Sleep(20 * time.Millisecond) {
+		if job.Status == models.ImportFailed || time.Now().After(deadline) {
+			t.Fatalf("import did not succeed: %+v", job)
+		}
+		req := httptest.NewRequest("GET", "/v1/imports/"+job.ID, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		job = models.ImportJob{}
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w = do("GET", "/v1/swift-codes/UPLDPLPWXXX", nil); w.Code != http.StatusOK {
+		t.Fatalf("GET uploaded HQ expected 200, got %d: %s", w.Code, w.Body)
+	}
+	doIf("DELETE", "/v1/swift-codes/UPLDPLPWXXX", "*", nil)
+	if entries, _ := auditLog.Find(context.Background(), models.AuditQuery{}); !hasImport(entries, "upload.csv") {
+		t.Errorf("audit log %+v has no entry for the uploaded import", entries)
+	}
+
+	// purge the deleted codes, so the next run on the same database starts clean
+	if _, err := svc.PurgeDeleted(context.Background(), -time.Minute); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+}
+
+// hasImport reports whether entries record an import of the named file
+func hasImport(entries []models.AuditEntry, fileName string) bool {
+	for _, e := range entries {
+		if e.Action == models.AuditImport && e.Import != nil && strings.HasPrefix(e.Details, fileName) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// Authenticator identifies the caller of a request from one kind of credentials
+type Authenticator interface {
+	// Authenticate returns the caller, nil without error when the request carries none of its credentials.
+	// Invalid credentials are an util.AppError, usually 401.
+	Authenticate(r *http.Request) (*models.Principal, error)
+
+	// Challenge is the WWW-Authenticate value sent with 401 responses
+	Challenge() string
+}
+
+// apiKeyAuthenticator checks the X-API-Key header against hashed keys
+type apiKeyAuthenticator struct {
+	keys port.APIKeyRepository
+}
+
+// NewAPIKeyAuthenticator authenticates requests by the X-API-Key header, the caller is named after the key
+func NewAPIKeyAuthenticator(keys port.APIKeyRepository) Authenticator {
+	return apiKeyAuthenticator{keys: keys}
+}
+
+// Authenticate looks up the hash of the key
+func (a apiKeyAuthenticator) Authenticate(r *http.Request) (*models.Principal, error) {
+	key := strings.TrimSpace(r.Header.Get(util.HeaderAPIKey))
+	if key == "" {
+		return nil, nil
+	}
+	k, err := a.keys.FindAPIKey(r.Context(), util.HashAPIKey(key))
+	if errors.Is(err, port.ErrNotFound) {
+		return nil, util.Unauthorized("invalid API key")
+	}
+	if err != nil {
+		return nil, util.Internal("checking API key: %v", err)
+	}
+	return &models.Principal{Subject: k.Name, Roles: k.Roles}, nil
+}
+
+// Challenge names the API key header
+func (a apiKeyAuthenticator) Challenge() string {
+	return `APIKey realm="swift-code-app", header="` + util.HeaderAPIKey + `"`
+}
+
+// requireRole lets through callers that have role, identified by the first authenticator finding credentials.
+// The caller becomes the subject and the actor of the request instead of X-Actor. With no authenticators every request passes.
+func requireRole(auths []Authenticator, role string) gin.HandlerFunc {
+	if len(auths) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		var caller *models.Principal
+		for _, auth := range auths {
+			p, err := auth.Authenticate(c.Request)
+			if err != nil {
+				abortUnauthorized(c, auths, err)
+				return
+			}
+			if p != nil {
+				caller = p
+				break
+			}
+		}
+		if caller == nil {
+			abortUnauthorized(c, auths, util.Unauthorized("authentication required"))
+			return
+		}
+		if !caller.HasRole(role) {
+			err := util.Forbidden("%s role required", role)
+			c.AbortWithStatusJSON(err.StatusCode, gin.H{"message": err.Error()})
+			return
+		}
+		ctx := util.WithSubject(c.Request.Context(), caller.Subject)
+		c.Request = c.Request.WithContext(util.WithActor(ctx, caller.Subject))
+		c.Next()
+	}
+}
+
+// abortUnauthorized responds with the error, 401s challenge the client with every authenticator
+func abortUnauthorized(c *gin.Context, auths []Authenticator, err error) {
+	status := util.StatusCodeFromError(err)
+	if status == http.StatusUnauthorized {
+		for _, auth := range auths {
+			c.Writer.Header().Add("WWW-Authenticate", auth.Challenge())
+		}
+	}
+	c.AbortWithStatusJSON(status, gin.H{"message": err.Error()})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// setupAuthRouter serves an empty memory repository behind API keys named after their roles
+func setupAuthRouter(t *testing.T) (*gin.Engine, *usecases.AuditService) {
+	gin.SetMode(gin.TestMode)
+	keys, err := persistence.NewMemoryAPIKeyRepository(
+		models.APIKey{Name: "reader-client", Hash: util.HashAPIKey("reader-key"), Roles: []string{models.RoleReader}},
+		models.APIKey{Name: "editor-client", Hash: util.HashAPIKey("editor-key"), Roles: []string{models.RoleEditor}},
+		models.APIKey{Name: "importer-client", Hash: util.HashAPIKey("importer-key"), Roles: []string{models.RoleImporter}},
+		models.APIKey{Name: "admin-client", Hash: util.HashAPIKey("admin-key"), Roles: []string{models.RoleAdmin}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := persistence.NewMemoryRepository()
+	auditLog := persistence.NewMemoryAuditRepository()
+	audit := usecases.NewAuditService(auditLog)
+	svc := usecases.NewSwiftService(repo, usecases.WithAuditLog(auditLog))
+	router := SetupRouter(svc,
+		WithImports(usecases.NewImportService(repo, map[string]string{}), "import-token"),
+		WithAuditLog(audit),
+		WithCacheStats(func() models.CacheStats { return models.CacheStats{} }),
+		WithAuth(NewAPIKeyAuthenticator(keys)))
+	return router, audit
+}
+
+func TestRequireRole(t *testing.T) {
+	router, _ := setupAuthRouter(t)
+	hq := `{"swiftCode":"AAAAPLPWXXX","bankName":"Bank","address":"Street","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header string
+		value  string
+		status int
+	}{
+		{"no key", "GET", "/v1/swift-codes", "", "", "", http.StatusUnauthorized},
+		{"invalid key", "GET", "/v1/swift-codes", "", util.HeaderAPIKey, "nope", http.StatusUnauthorized},
+		{"reader reads", "GET", "/v1/swift-codes", "", util.HeaderAPIKey, "reader-key", http.StatusOK},
+		{"reader can't read audit", "GET", "/v1/audit", "", util.HeaderAPIKey, "reader-key", http.StatusForbidden},
+		{"editor can't read audit", "GET", "/v1/audit", "", util.HeaderAPIKey, "editor-key", http.StatusForbidden},
+		{"admin reads audit", "GET", "/v1/audit", "", util.HeaderAPIKey, "admin-key", http.StatusOK},
+		{"no key for metrics", "GET", "/metrics", "", "", "", http.StatusUnauthorized},
+		{"reader can't read metrics", "GET", "/metrics", "", util.HeaderAPIKey, "reader-key", http.StatusForbidden},
+		{"import token doesn't read metrics", "GET", "/metrics", "", "Authorization", "Bearer import-token", http.StatusUnauthorized},
+		{"admin reads metrics", "GET", "/metrics", "", util.HeaderAPIKey, "admin-key", http.StatusOK},
+		{"reader can't add", "POST", "/v1/swift-codes", hq, util.HeaderAPIKey, "reader-key", http.StatusForbidden},
+		{"importer reads", "GET", "/v1/swift-codes/country/PL", "", util.HeaderAPIKey, "importer-key", http.StatusNotFound},
+		{"importer can't delete", "DELETE", "/v1/swift-codes/AAAAPLPWXXX", "", util.HeaderAPIKey, "importer-key", http.StatusForbidden},
+		{"editor adds", "POST", "/v1/swift-codes", hq, util.HeaderAPIKey, "editor-key", http.StatusOK},
+		{"editor can't import", "POST", "/v1/imports", "", util.HeaderAPIKey, "editor-key", http.StatusForbidden},
+		{"importer imports", "POST", "/v1/imports", "", util.HeaderAPIKey, "importer-key", http.StatusBadRequest},
+		{"import token replaced by roles", "POST", "/v1/imports", "", "Authorization", "Bearer import-token", http.StatusUnauthorized},
+		{"health stays open", "GET", "/healthz", "", "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d; want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+				var body map[string]string
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["message"] == "" {
+					t.Errorf("body = %s; want AppError message", w.Body)
+				}
+			}
+			if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "APIKey") {
+				t.Errorf("WWW-Authenticate = %q; want APIKey challenge", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireRole_ActorFromKey(t *testing.T) {
+	router, audit := setupAuthRouter(t)
+	body := []byte(`{"swiftCode":"AAAAPLPWXXX","bankName":"Bank","address":"Street","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true}`)
+	req := httptest.NewRequest("POST", "/v1/swift-codes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(util.HeaderAPIKey, "editor-key")
+	req.Header.Set(util.HeaderActor, "someone else")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	resp, err := audit.ListAuditEntries(context.Background(), models.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 1 || resp.Entries[0].Actor != "editor-client" {
+		t.Errorf("audit entries = %+v; want one by editor-client", resp.Entries)
+	}
+}
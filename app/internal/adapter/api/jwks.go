@@ -0,0 +1,283 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// maxJWKSSize bounds JWKS documents fetched from a URL
+const maxJWKSSize = 1 << 20
+
+// KeySet finds the public keys that sign bearer tokens
+type KeySet interface {
+	// Key returns the key with the ID, or the only key of the set when kid is empty; nil when there is none
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// staticKeySet is a JWKS loaded once
+type staticKeySet map[string]crypto.PublicKey
+
+// Key returns the key with the ID
+func (s staticKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	return s.find(kid), nil
+}
+
+func (s staticKeySet) find(kid string) crypto.PublicKey {
+	if kid == "" && len(s) == 1 {
+		for _, k := range s {
+			return k
+		}
+	}
+	return s[kid]
+}
+
+// ParseJWKS reads the RSA and EC signing keys of a JSON Web Key Set, other keys are skipped
+func ParseJWKS(data []byte) (KeySet, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+	set := staticKeySet{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k.N, k.E)
+		case "EC":
+			key, err = ecKey(k.Crv, k.X, k.Y)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (%q): %w", i+1, k.Kid, err)
+		}
+		if _, dup := set[k.Kid]; dup {
+			return nil, fmt.Errorf("JWKS key %d: duplicate kid %q", i+1, k.Kid)
+		}
+		set[k.Kid] = key
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("JWKS has no RSA or EC signing keys")
+	}
+	return set, nil
+}
+
+// LoadJWKSFile reads a JWKS from a file once, restart to pick up changes
+func LoadJWKSFile(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// remoteKeySet fetches a JWKS from a URL and refreshes it when it is stale or a token names an unknown key
+type remoteKeySet struct {
+	url        string
+	client     *http.Client
+	maxAge     time.Duration
+	minRefresh time.Duration
+
+	// one fetch at a time, concurrent requests wait for it without holding mu
+	fetches singleflight.Group
+
+	mu        sync.Mutex
+	keys      staticKeySet
+	fetchedAt time.Time
+	// after a failed fetch the cached keys are used until retryAt, backoff doubles up to minRefresh
+	retryAt time.Time
+	backoff time.Duration
+	lastErr error
+}
+
+const (
+	// jwksFetchTimeout bounds a fetch shared by concurrent requests, it is not tied to any of them
+	jwksFetchTimeout = 10 * time.Second
+	// jwksRetryBackoff is the wait after the first failed fetch
+	jwksRetryBackoff = time.Second
+)
+
+// NewRemoteJWKS fetches the JWKS at url when first needed and again every maxAge,
+// or on an unknown key ID but at most once a minute so bad tokens can't flood the issuer.
+// When a fetch fails the cached keys are used, even stale, and fetches are retried with backoff.
+func NewRemoteJWKS(url string, client *http.Client, maxAge time.Duration) KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &remoteKeySet{url: url, client: client, maxAge: maxAge, minRefresh: time.Minute}
+}
+
+// Key returns the key with the ID, fetching the JWKS when needed
+func (s *remoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	keys, age := s.keys, time.Since(s.fetchedAt)
+	fresh := keys != nil && age <= s.maxAge
+	if fresh {
+		// the issuer may have rotated its keys, an unknown one refetches after minRefresh
+		if k := keys.find(kid); k != nil || age < s.minRefresh {
+			s.mu.Unlock()
+			return k, nil
+		}
+	}
+	if time.Now().Before(s.retryAt) {
+		err := s.lastErr
+		s.mu.Unlock()
+		return cachedKey(keys, kid, err)
+	}
+	s.mu.Unlock()
+
+	ch := s.fetches.DoChan("", func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+		defer cancel()
+		return nil, s.refresh(fetchCtx)
+	})
+	select {
+	case res := <-ch:
+		s.mu.Lock()
+		keys = s.keys
+		s.mu.Unlock()
+		return cachedKey(keys, kid, res.Err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// cachedKey finds kid in keys, the fetch error is only returned when there are no keys at all
+func cachedKey(keys staticKeySet, kid string, err error) (crypto.PublicKey, error) {
+	if keys == nil {
+		if err == nil {
+			err = fmt.Errorf("fetch JWKS: no keys")
+		}
+		return nil, err
+	}
+	return keys.find(kid), nil
+}
+
+// refresh fetches the keys and records the outcome, a failure keeps the old keys and backs off
+func (s *remoteKeySet) refresh(ctx context.Context) error {
+	set, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.backoff = min(max(2*s.backoff, jwksRetryBackoff), s.minRefresh)
+		s.retryAt = time.Now().Add(s.backoff)
+		s.lastErr = err
+		if s.keys != nil {
+			log.Printf("JWKS refresh failed, using the cached keys for %s: %v", s.backoff, err)
+		}
+		return err
+	}
+	s.keys = set
+	s.fetchedAt = time.Now()
+	s.backoff, s.retryAt, s.lastErr = 0, time.Time{}, nil
+	return nil
+}
+
+// fetch reads and parses the JWKS at the URL
+func (s *remoteKeySet) fetch(ctx context.Context) (staticKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: %s answered %s", s.url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	set, err := ParseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return set.(staticKeySet), nil
+}
+
+// rsaKey decodes the base64url modulus and exponent of an RSA key
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil || len(nb) == 0 {
+		return nil, fmt.Errorf("invalid modulus")
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil || len(eb) == 0 || len(eb) > 4 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(new(big.Int).SetBytes(eb).Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("RSA key shorter than 2048 bits")
+	}
+	return key, nil
+}
+
+// ecKey decodes the curve and base64url coordinates of an EC key
+func ecKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var (
+		curve elliptic.Curve
+		check ecdh.Curve
+	)
+	switch crv {
+	case "P-256":
+		curve, check = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, check = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, check = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil || len(xb) != size {
+		return nil, fmt.Errorf("invalid x coordinate")
+	}
+	yb, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil || len(yb) != size {
+		return nil, fmt.Errorf("invalid y coordinate")
+	}
+	// ecdh checks the point is on the curve
+	point := append(append([]byte{4}, xb...), yb...)
+	if _, err := check.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("point is not on curve %s", crv)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xb), Y: new(big.Int).SetBytes(yb)}, nil
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // SHA-2 for crypto.Hash.New
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// JWTConfig says which bearer tokens are accepted and how their claims grant roles
+type JWTConfig struct {
+	// Issuer and Audience must match the iss and aud claims when set
+	Issuer   string
+	Audience string
+
+	// RolesClaim names the claim listing the permissions of the caller, as an array or a space-separated string
+	// like scope. Dots reach into nested objects, e.g. realm_access.roles. Defaults to roles.
+	RolesClaim string
+
+	// RoleMap translates claim values to roles, values without an entry are ignored.
+	// When empty, values naming a role (reader, editor, importer, admin) grant it.
+	RoleMap map[string]string
+
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+// jwtAuthenticator checks "Authorization: Bearer <JWT>" against the keys of the issuer
+type jwtAuthenticator struct {
+	keys KeySet
+	cfg  JWTConfig
+	now  func() time.Time
+}
+
+// NewJWTAuthenticator authenticates requests by signed bearer tokens, the caller is the sub claim
+func NewJWTAuthenticator(keys KeySet, cfg JWTConfig) Authenticator {
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	return jwtAuthenticator{keys: keys, cfg: cfg, now: time.Now}
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims are the registered claims that are checked, the rest stays raw for the roles
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+// Authenticate verifies the signature and the claims of the bearer token
+func (a jwtAuthenticator) Authenticate(r *http.Request) (*models.Principal, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, util.Unauthorized("malformed bearer token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, util.Unauthorized("malformed bearer token header")
+	}
+	if err := a.verify(r.Context(), header, parts[0]+"."+parts[1], parts[2]); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	var raw map[string]any
+	if decodeSegment(parts[1], &claims) != nil || decodeSegment(parts[1], &raw) != nil {
+		return nil, util.Unauthorized("malformed bearer token claims")
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return &models.Principal{Subject: claims.Subject, Roles: a.roles(raw)}, nil
+}
+
+// Challenge names the bearer scheme
+func (a jwtAuthenticator) Challenge() string {
+	return `Bearer realm="swift-code-app"`
+}
+
+// verify checks the signature with the issuer key named by the header
+func (a jwtAuthenticator) verify(ctx context.Context, header jwtHeader, signed, signature string) error {
+	hash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return util.Unauthorized("unsupported bearer token algorithm %q", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return util.Unauthorized("malformed bearer token signature")
+	}
+	key, err := a.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return util.Internal("looking up bearer token key: %v", err)
+	}
+	if key == nil {
+		return util.Unauthorized("unknown bearer token key %q", header.Kid)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	valid := false
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch header.Alg[:2] {
+		case "RS":
+			valid = rsa.VerifyPKCS1v15(k, hash, digest, sig) == nil
+		case "PS":
+			valid = rsa.VerifyPSS(k, hash, digest, sig, nil) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if header.Alg[:2] == "ES" && len(sig) == 2*size {
+			rs, ss := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+			valid = ecdsa.Verify(k, digest, rs, ss)
+		}
+	}
+	if !valid {
+		return util.Unauthorized("invalid bearer token signature")
+	}
+	return nil
+}
+
+// jwtHashes are the accepted algorithms, HMAC and none are refused as the keys are public
+var jwtHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// checkClaims requires a subject, an unexpired token and the configured issuer and audience
+func (a jwtAuthenticator) checkClaims(c jwtClaims) error {
+	now := a.now()
+	if c.Subject == "" {
+		return util.Unauthorized("bearer token has no subject")
+	}
+	if c.ExpiresAt == nil {
+		return util.Unauthorized("bearer token has no expiry")
+	}
+	if now.After(unixTime(*c.ExpiresAt).Add(a.cfg.Leeway)) {
+		return util.Unauthorized("bearer token expired")
+	}
+	if c.NotBefore != nil && now.Add(a.cfg.Leeway).Before(unixTime(*c.NotBefore)) {
+		return util.Unauthorized("bearer token not valid yet")
+	}
+	if a.cfg.Issuer != "" && c.Issuer != a.cfg.Issuer {
+		return util.Unauthorized("bearer token issuer %q not accepted", c.Issuer)
+	}
+	if a.cfg.Audience != "" && !hasAudience(c.Audience, a.cfg.Audience) {
+		return util.Unauthorized("bearer token not meant for %q", a.cfg.Audience)
+	}
+	return nil
+}
+
+// roles maps the values of the roles claim to roles
+func (a jwtAuthenticator) roles(claims map[string]any) []string {
+	var v any = claims
+	for _, name := range strings.Split(a.cfg.RolesClaim, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[name]
+	}
+
+	var values []string
+	switch v := v.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var roles []string
+	for _, value := range values {
+		role := value
+		if len(a.cfg.RoleMap) > 0 {
+			role = a.cfg.RoleMap[value]
+		}
+		if models.ValidRole(role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// hasAudience reports whether the aud claim, a string or an array, contains want
+func hasAudience(aud json.RawMessage, want string) bool {
+	var one string
+	if json.Unmarshal(aud, &one) == nil {
+		return one == want
+	}
+	var many []string
+	if json.Unmarshal(aud, &many) == nil {
+		for _, a := range many {
+			if a == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON part of a token
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// unixTime converts a NumericDate claim
+func unixTime(sec float64) time.Time {
+	return time.Unix(int64(sec), 0)
+}
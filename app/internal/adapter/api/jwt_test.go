@@ -0,0 +1,399 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// testIssuer stands in for the gateway: it signs tokens and serves its keys as a JWKS
+type testIssuer struct {
+	rsaKey  *rsa.PrivateKey
+	ecKey   *ecdsa.PrivateKey
+	fetches atomic.Int32
+	down    atomic.Bool // the JWKS URL answers 503
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testIssuer{rsaKey: rk, ecKey: ek}
+}
+
+func (i *testIssuer) jwks() []byte {
+	b64 := base64.RawURLEncoding.EncodeToString
+	coord := func(n *big.Int) string { return b64(n.FillBytes(make([]byte, 32))) }
+	data, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(i.rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(i.rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": coord(i.ecKey.X), "y": coord(i.ecKey.Y)},
+		{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"},
+	}})
+	return data
+}
+
+func (i *testIssuer) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i.fetches.Add(1)
+		if i.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(i.jwks())
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// sign returns a token signed with alg, RS256 and ES256 use the issuer keys and anything else gets a bogus signature
+func (i *testIssuer) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch alg {
+	case "RS256":
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		sig = []byte("bogus")
+	}
+	return signed + "." + b64(sig)
+}
+
+func claims(roles ...string) map[string]any {
+	return map[string]any{
+		"sub":   "gateway-user",
+		"iss":   "https://issuer.test",
+		"aud":   []string{"other", "swift-code-app"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}
+}
+
+// setupJWTRouter serves an empty memory repository behind bearer tokens of the test issuer
+func setupJWTRouter(t *testing.T, keys KeySet, cfg JWTConfig) (*gin.Engine, *usecases.AuditService) {
+	gin.SetMode(gin.TestMode)
+	repo := persistence.NewMemoryRepository()
+	auditLog := persistence.NewMemoryAuditRepository()
+	audit := usecases.NewAuditService(auditLog)
+	svc := usecases.NewSwiftService(repo, usecases.WithAuditLog(auditLog))
+	router := SetupRouter(svc,
+		WithImports(usecases.NewImportService(repo, map[string]string{}), ""),
+		WithAuditLog(audit),
+		WithAuth(NewJWTAuthenticator(keys, cfg)))
+	return router, audit
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys, err := ParseJWKS(issuer.jwks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, _ := setupJWTRouter(t, keys, JWTConfig{Issuer: "https://issuer.test", Audience: "swift-code-app"})
+	hq := `{"swiftCode":"AAAAPLPWXXX","bankName":"Bank","address":"Street","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true}`
+
+	expired := claims(models.RoleReader)
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	notYet := claims(models.RoleReader)
+	notYet["nbf"] = time.Now().Add(time.Hour).Unix()
+	otherIssuer := claims(models.RoleReader)
+	otherIssuer["iss"] = "https://evil.test"
+	otherAudience := claims(models.RoleReader)
+	otherAudience["aud"] = "other"
+	noSubject := claims(models.RoleReader)
+	delete(noSubject, "sub")
+	noExpiry := claims(models.RoleReader)
+	delete(noExpiry, "exp")
+	tampered := issuer.sign(t, "RS256", "rsa-1", claims(models.RoleReader))
+	tampered = tampered[:strings.LastIndex(tampered, ".")] + "." + base64.RawURLEncoding.EncodeToString([]byte(strings.Repeat("x", 256)))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		status int
+	}{
+		{"no token", "GET", "/v1/swift-codes", "", "", http.StatusUnauthorized},
+		{"malformed", "GET", "/v1/swift-codes", "", "not-a-jwt", http.StatusUnauthorized},
+		{"RS256 reader reads", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", claims(models.RoleReader)), http.StatusOK},
+		{"ES256 reader reads", "GET", "/v1/swift-codes", "", issuer.sign(t, "ES256", "ec-1", claims(models.RoleReader)), http.StatusOK},
+		{"reader can't add", "POST", "/v1/swift-codes", hq, issuer.sign(t, "RS256", "rsa-1", claims(models.RoleReader)), http.StatusForbidden},
+		{"editor adds", "POST", "/v1/swift-codes", hq, issuer.sign(t, "RS256", "rsa-1", claims(models.RoleEditor)), http.StatusOK},
+		{"editor can't import", "POST", "/v1/imports", "", issuer.sign(t, "RS256", "rsa-1", claims(models.RoleEditor)), http.StatusForbidden},
+		{"admin imports", "POST", "/v1/imports", "", issuer.sign(t, "RS256", "rsa-1", claims(models.RoleAdmin)), http.StatusBadRequest},
+		{"admin deletes", "DELETE", "/v1/swift-codes/AAAAPLPWXXX", "", issuer.sign(t, "RS256", "rsa-1", claims(models.RoleAdmin)), http.StatusPreconditionRequired},
+		{"unknown roles grant nothing", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", claims("superuser")), http.StatusForbidden},
+		{"expired", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", expired), http.StatusUnauthorized},
+		{"not valid yet", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", notYet), http.StatusUnauthorized},
+		{"other issuer", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", otherIssuer), http.StatusUnauthorized},
+		{"other audience", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", otherAudience), http.StatusUnauthorized},
+		{"no subject", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", noSubject), http.StatusUnauthorized},
+		{"no expiry", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-1", noExpiry), http.StatusUnauthorized},
+		{"unknown key", "GET", "/v1/swift-codes", "", issuer.sign(t, "RS256", "rsa-2", claims(models.RoleReader)), http.StatusUnauthorized},
+		{"key of other type", "GET", "/v1/swift-codes", "", issuer.sign(t, "ES256", "rsa-1", claims(models.RoleReader)), http.StatusUnauthorized},
+		{"tampered signature", "GET", "/v1/swift-codes", "", tampered, http.StatusUnauthorized},
+		{"alg none", "GET", "/v1/swift-codes", "", issuer.sign(t, "none", "rsa-1", claims(models.RoleAdmin)), http.StatusUnauthorized},
+		{"HMAC refused", "GET", "/v1/swift-codes", "", issuer.sign(t, "HS256", "secret", claims(models.RoleAdmin)), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d; want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Bearer") {
+				t.Errorf("WWW-Authenticate = %q; want Bearer challenge", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator_RolesClaim(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys, err := ParseJWKS(issuer.jwks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := NewJWTAuthenticator(keys, JWTConfig{
+		RolesClaim: "realm_access.roles",
+		RoleMap:    map[string]string{"swift.read": models.RoleReader, "swift.write": models.RoleEditor},
+	})
+	c := claims()
+	c["realm_access"] = map[string]any{"roles": []string{"swift.write", "reader", "offline_access"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+issuer.sign(t, "RS256", "rsa-1", c))
+
+	p, err := auth.Authenticate(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "gateway-user" || len(p.Roles) != 1 || p.Roles[0] != models.RoleEditor {
+		t.Errorf("principal = %+v; want gateway-user with only the mapped editor role", p)
+	}
+
+	scoped := NewJWTAuthenticator(keys, JWTConfig{RolesClaim: "scope"})
+	c = claims()
+	c["scope"] = "openid reader importer"
+	req.Header.Set("Authorization", "Bearer "+issuer.sign(t, "ES256", "ec-1", c))
+	if p, err = scoped.Authenticate(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Roles) != 2 || p.Roles[0] != models.RoleReader || p.Roles[1] != models.RoleImporter {
+		t.Errorf("roles = %v; want reader and importer from scope", p.Roles)
+	}
+}
+
+func TestJWTAuthenticator_SubjectIsActor(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys, err := ParseJWKS(issuer.jwks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, audit := setupJWTRouter(t, keys, JWTConfig{})
+	body := `{"swiftCode":"AAAAPLPWXXX","bankName":"Bank","address":"Street","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true}`
+	req := httptest.NewRequest("POST", "/v1/swift-codes", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+issuer.sign(t, "RS256", "rsa-1", claims(models.RoleEditor)))
+	req.Header.Set(util.HeaderActor, "someone else")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	resp, err := audit.ListAuditEntries(context.Background(), models.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Entries) != 1 || resp.Entries[0].Actor != "gateway-user" {
+		t.Errorf("audit entries = %+v; want one by gateway-user", resp.Entries)
+	}
+}
+
+func TestRemoteJWKS(t *testing.T) {
+	issuer := newTestIssuer(t)
+	srv := issuer.server(t)
+	keys := NewRemoteJWKS(srv.URL, srv.Client(), time.Hour).(*remoteKeySet)
+	ctx := context.Background()
+
+	if k, err := keys.Key(ctx, "rsa-1"); err != nil || k == nil {
+		t.Fatalf("Key(rsa-1) = %v, %v; want the RSA key", k, err)
+	}
+	if k, err := keys.Key(ctx, "ec-1"); err != nil || k == nil {
+		t.Fatalf("Key(ec-1) = %v, %v; want the EC key", k, err)
+	}
+	if n := issuer.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d; want 1, keys are cached", n)
+	}
+
+	// unknown keys refetch only after minRefresh
+	if k, _ := keys.Key(ctx, "rotated"); k != nil {
+		t.Errorf("Key(rotated) = %v; want nil", k)
+	}
+	if n := issuer.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d; want 1 right after fetching", n)
+	}
+	keys.fetchedAt = time.Now().Add(-2 * time.Minute)
+	keys.Key(ctx, "rotated")
+	if n := issuer.fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d; want 2, unknown key refetches", n)
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	broken := NewRemoteJWKS(missing.URL, missing.Client(), time.Hour)
+	if _, err := broken.Key(ctx, "rsa-1"); err == nil {
+		t.Error("Key on unreachable JWKS succeeded; want error")
+	}
+}
+
+func TestRemoteJWKS_FailedRefresh(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys := NewRemoteJWKS(issuer.server(t).URL, nil, time.Hour).(*remoteKeySet)
+	ctx := context.Background()
+	if k, err := keys.Key(ctx, "rsa-1"); err != nil || k == nil {
+		t.Fatalf("Key(rsa-1) = %v, %v; want the RSA key", k, err)
+	}
+
+	// stale keys are still used while the issuer is down
+	issuer.down.Store(true)
+	keys.fetchedAt = time.Now().Add(-2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		if k, err := keys.Key(ctx, "rsa-1"); err != nil || k == nil {
+			t.Fatalf("Key(rsa-1) with issuer down = %v, %v; want the cached key", k, err)
+		}
+	}
+	if n := issuer.fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d; want 2, retries wait for the backoff", n)
+	}
+
+	// after the backoff the keys are fetched again
+	issuer.down.Store(false)
+	keys.retryAt = time.Now().Add(-time.Second)
+	keys.Key(ctx, "rsa-1")
+	if n := issuer.fetches.Load(); n != 3 || time.Since(keys.fetchedAt) > time.Minute || keys.backoff != 0 {
+		t.Errorf("fetches = %d, fetched at %v, backoff %v; want a successful retry", n, keys.fetchedAt, keys.backoff)
+	}
+}
+
+func TestRemoteJWKS_ConcurrentFetch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.fetches.Add(1)
+		<-release
+		w.Write(issuer.jwks())
+	}))
+	defer srv.Close()
+	keys := NewRemoteJWKS(srv.URL, srv.Client(), time.Hour).(*remoteKeySet)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if k, err := keys.Key(context.Background(), "rsa-1"); err != nil || k == nil {
+				errs <- fmt.Errorf("Key(rsa-1) = %v, %v", k, err)
+			}
+		}()
+	}
+	// a request stops waiting for the fetch when its context ends
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := keys.Key(cancelled, "rsa-1"); err != context.Canceled {
+		t.Errorf("Key with cancelled context = %v; want context.Canceled", err)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := issuer.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d; want 1 shared by concurrent requests", n)
+	}
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	issuer := newTestIssuer(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "jwks.json")
+	if err := os.WriteFile(path, issuer.jwks(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := keys.Key(context.Background(), "ec-1"); k == nil {
+		t.Error("Key(ec-1) = nil; want the EC key")
+	}
+
+	for name, data := range map[string]string{
+		"not json":      `{`,
+		"no keys":       `{"keys":[]}`,
+		"only secrets":  `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+		"short RSA key": `{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`,
+		"off curve":     `{"keys":[{"kty":"EC","crv":"P-256","x":"` + strings.Repeat("A", 43) + `","y":"` + strings.Repeat("B", 43) + `"}]}`,
+	} {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadJWKSFile(path); err == nil {
+			t.Errorf("%s: LoadJWKSFile succeeded; want error", name)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// cacheMetrics serves the lookup cache counters in the Prometheus text format
+func cacheMetrics(stats func() models.CacheStats) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := stats()
+		var b strings.Builder
+		metric := func(name, kind, help string, value any) {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+		}
+		metric("swift_cache_hits_total", "counter", "SWIFT code lookups answered from the cache.", s.Hits)
+		metric("swift_cache_misses_total", "counter", "SWIFT code lookups that went to the repository.", s.Misses)
+		metric("swift_cache_invalidations_total", "counter", "Changes that dropped cached lookups.", s.Invalidations)
+		metric("swift_cache_errors_total", "counter", "Failed cache reads and writes.", s.Errors)
+		metric("swift_cache_evictions_total", "counter", "Lookups dropped from the in-process cache to make room.", s.Evictions)
+		metric("swift_cache_entries", "gauge", "Lookups held by the in-process cache.", s.Entries)
+		c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+	}
+}
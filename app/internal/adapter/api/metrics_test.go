@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+)
+
+func TestCacheMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := usecases.NewSwiftService(persistence.NewMemoryRepository())
+	stats := WithCacheStats(func() models.CacheStats {
+		return models.CacheStats{Hits: 7, Misses: 3, Entries: 2}
+	})
+	// without any credential configured the metrics are open
+	router := SetupRouter(svc, stats)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200", w.Code)
+	}
+	for _, line := range []string{"swift_cache_hits_total 7", "swift_cache_misses_total 3", "# TYPE swift_cache_entries gauge", "swift_cache_entries 2"} {
+		if !strings.Contains(w.Body.String(), line+"\n") {
+			t.Errorf("body lacks %q:\n%s", line, w.Body)
+		}
+	}
+
+	// the import token guards them when there is no authentication
+	router = SetupRouter(svc, WithImports(usecases.NewImportService(persistence.NewMemoryRepository(), nil), "import-token"), stats)
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "import-token": http.StatusOK} {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("status with token %q = %d; want %d", token, w.Code, want)
+		}
+	}
+
+	// without a cache there are no metrics
+	router = SetupRouter(svc)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status without cache = %d; want 404", w.Code)
+	}
+}
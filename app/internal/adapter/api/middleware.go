@@ -0,0 +1,61 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// maxRequestIDLength bounds request IDs taken from clients, longer ones are replaced
+const maxRequestIDLength = 128
+
+// requireToken lets through requests with "Authorization: Bearer <token>".
+// With no token configured every request is refused, so the routes stay closed by default.
+func requireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "endpoint disabled, no API token configured"})
+			return
+		}
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="swift-code-app"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "missing or invalid API token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestContext puts the request ID and the actor named by X-Actor into the request context.
+// The request ID comes from X-Request-ID or is generated, and is echoed back in the response.
+func requestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(util.HeaderRequestID))
+		if id == "" || len(id) > maxRequestIDLength || strings.ContainsFunc(id, func(r rune) bool { return r < ' ' || r > '~' }) {
+			id = newRequestID()
+		}
+		c.Header(util.HeaderRequestID, id)
+
+		ctx := util.WithRequestID(c.Request.Context(), id)
+		if actor := strings.TrimSpace(c.GetHeader(util.HeaderActor)); actor != "" {
+			ctx = util.WithActor(ctx, actor)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// newRequestID returns a random 128-bit hex ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand doesn't fail on supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
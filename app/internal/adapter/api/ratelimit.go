@@ -0,0 +1,63 @@
+package api
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// Route classes limited separately, a client has one bucket for each
+const (
+	rateClassRead  = "read"
+	rateClassWrite = "write"
+	// rateClassIP counts every request of an IP before its credentials are checked
+	rateClassIP = "ip"
+)
+
+// rateLimit takes a token from the bucket of the caller for every request and answers 429 when it is empty.
+// The caller is the authenticated subject, e.g. the API key, or the client IP: after requireRole
+// it limits each client, before it each IP.
+// Responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset, refused ones Retry-After.
+// A failing store lets requests through rather than taking the API down with it.
+func rateLimit(store port.RateLimitStore, class string, limit models.RateLimit) gin.HandlerFunc {
+	if store == nil || !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := strconv.Itoa(limit.Limit) + ";w=" + strconv.Itoa(int(limit.Period.Seconds()))
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if subject := util.Subject(c.Request.Context()); subject != "" {
+			client = "sub:" + subject
+		}
+		res, err := store.Take(c.Request.Context(), class+":"+client, limit)
+		if err != nil {
+			log.Printf("rate limit store failed, letting request through: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.Reset))
+		if !res.Allowed {
+			c.Header("Retry-After", seconds(res.RetryAfter))
+			err := util.TooManyRequests("rate limit of %d %s requests per %s exceeded", limit.Limit, class, limit.Period)
+			c.AbortWithStatusJSON(err.StatusCode, gin.H{"message": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds rounds d up to whole seconds, so clients don't retry too early
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// setupRateLimitRouter allows 2 reads and 1 write per minute
+func setupRateLimitRouter(t *testing.T, store port.RateLimitStore, opts ...RouterOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	svc := usecases.NewSwiftService(persistence.NewMemoryRepository())
+	opts = append(opts, WithRateLimit(store,
+		models.RateLimit{Limit: 2, Period: time.Minute},
+		models.RateLimit{Limit: 1, Period: time.Minute}))
+	return SetupRouter(svc, opts...)
+}
+
+func doRequest(router *gin.Engine, method, path, ip string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":1234"
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	router := setupRateLimitRouter(t, persistence.NewMemoryRateLimitStore())
+
+	for want := 1; want >= 0; want-- {
+		w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d; want 200: %s", w.Code, w.Body)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(want) {
+			t.Errorf("RateLimit-Remaining = %q; want %d", got, want)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Policy") != "2;w=60" {
+			t.Errorf("RateLimit headers = %v; want limit 2 per 60s", w.Header())
+		}
+	}
+
+	w := doRequest(router, "GET", "/v1/swift-codes/AAAAPLPWXXX", "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d; want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" || w.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("Retry-After = %q, RateLimit-Reset = %q; want 30 and 60", w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Reset"))
+	}
+	if !strings.Contains(w.Body.String(), `"message"`) {
+		t.Errorf("body = %s; want AppError message", w.Body)
+	}
+
+	// other clients and the write bucket are unaffected
+	if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.2"); w.Code != http.StatusOK {
+		t.Errorf("other IP status = %d; want 200", w.Code)
+	}
+	if w := doRequest(router, "POST", "/v1/swift-codes", "192.0.2.1"); w.Code != http.StatusBadRequest {
+		t.Errorf("write status = %d; want 400 from the handler", w.Code)
+	}
+	if w := doRequest(router, "POST", "/v1/swift-codes", "192.0.2.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second write status = %d; want 429", w.Code)
+	}
+
+	// health checks aren't limited
+	if w := doRequest(router, "GET", "/healthz", "192.0.2.1"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("healthz status = %d, headers %v; want 200 without rate limit", w.Code, w.Header())
+	}
+}
+
+func TestRateLimit_PerAPIKey(t *testing.T) {
+	keys, err := persistence.NewMemoryAPIKeyRepository(
+		models.APIKey{Name: "batch-job", Hash: util.HashAPIKey("batch-key"), Roles: []string{models.RoleReader}},
+		models.APIKey{Name: "validator", Hash: util.HashAPIKey("validator-key"), Roles: []string{models.RoleReader}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := setupRateLimitRouter(t, persistence.NewMemoryRateLimitStore(), WithAuth(NewAPIKeyAuthenticator(keys)),
+		WithIPRateLimit(models.RateLimit{Limit: 10, Period: time.Minute}))
+
+	// the same IP but different keys
+	for i := 0; i < 2; i++ {
+		doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", util.HeaderAPIKey, "batch-key")
+	}
+	if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", util.HeaderAPIKey, "batch-key"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("batch-job status = %d; want 429", w.Code)
+	}
+	if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", util.HeaderAPIKey, "validator-key"); w.Code != http.StatusOK {
+		t.Errorf("validator status = %d; want 200", w.Code)
+	}
+}
+
+func TestRateLimit_IPBeforeAuth(t *testing.T) {
+	keys, err := persistence.NewMemoryAPIKeyRepository(
+		models.APIKey{Name: "batch-job", Hash: util.HashAPIKey("batch-key"), Roles: []string{models.RoleReader}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := setupRateLimitRouter(t, persistence.NewMemoryRateLimitStore(), WithAuth(NewAPIKeyAuthenticator(keys)),
+		WithIPRateLimit(models.RateLimit{Limit: 3, Period: time.Minute}))
+
+	// guessed keys count against the IP
+	for i := 0; i < 3; i++ {
+		if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", util.HeaderAPIKey, "guess"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("guess %d status = %d; want 401", i+1, w.Code)
+		}
+	}
+	if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", util.HeaderAPIKey, "batch-key"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status after guesses = %d; want 429 before the key is checked", w.Code)
+	}
+	if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.2", util.HeaderAPIKey, "batch-key"); w.Code != http.StatusOK {
+		t.Errorf("other IP status = %d; want 200", w.Code)
+	}
+}
+
+func TestRateLimit_TrustedProxies(t *testing.T) {
+	store := persistence.NewMemoryRateLimitStore()
+	// without trusted proxies X-Forwarded-For can't give every request its own bucket
+	router := setupRateLimitRouter(t, store)
+	for i := 0; i < 2; i++ {
+		doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", "X-Forwarded-For", "198.51.100."+strconv.Itoa(i))
+	}
+	if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", "X-Forwarded-For", "198.51.100.9"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For status = %d; want 429", w.Code)
+	}
+
+	// behind a trusted proxy each forwarded client has its own bucket
+	router = setupRateLimitRouter(t, persistence.NewMemoryRateLimitStore(), WithTrustedProxies("192.0.2.0/24"))
+	for i := 0; i < 3; i++ {
+		if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1", "X-Forwarded-For", "198.51.100."+strconv.Itoa(i)); w.Code != http.StatusOK {
+			t.Errorf("forwarded client %d status = %d; want 200", i, w.Code)
+		}
+	}
+}
+
+// failingRateLimitStore can't reach its shared store
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitResult, error) {
+	return models.RateLimitResult{}, errors.New("store down")
+}
+
+func TestRateLimit_StoreFailureLetsThrough(t *testing.T) {
+	router := setupRateLimitRouter(t, failingRateLimitStore{})
+	for i := 0; i < 3; i++ {
+		if w := doRequest(router, "GET", "/v1/swift-codes", "192.0.2.1"); w.Code != http.StatusOK {
+			t.Fatalf("status = %d; want 200", w.Code)
+		}
+	}
+}
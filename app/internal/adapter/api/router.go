@@ -1,16 +1,94 @@
 package api
 
 import (
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/przemekk6973/swift-code-app/app/internal/adapter/api/v1"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
 	"net/http"
 )
 
+// RouterOption configures optional parts of the API
+type RouterOption func(*routerConfig)
+
+type routerConfig struct {
+	imports     *usecases.ImportService
+	importToken string
+	audit       *usecases.AuditService
+	auth        []Authenticator
+	rateLimits  port.RateLimitStore
+	readLimit   models.RateLimit
+	writeLimit  models.RateLimit
+	ipLimit     models.RateLimit
+	proxies     []string
+	cacheStats  func() models.CacheStats
+}
+
+// WithImports enables /v1/imports, guarded by a bearer token; without WithAuth the token guards
+// /v1/audit and /metrics too
+func WithImports(svc *usecases.ImportService, token string) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.imports = svc
+		cfg.importToken = token
+	}
+}
+
+// WithAuditLog enables /v1/audit
+func WithAuditLog(svc *usecases.AuditService) RouterOption {
+	return func(cfg *routerConfig) { cfg.audit = svc }
+}
+
+// WithAuth requires credentials on /v1 routes: reader for reads, editor for changes, importer for imports
+// and admin for all of them, the audit log and /metrics included.
+// Authenticators are tried in order, without any the API is open to everyone.
+func WithAuth(auths ...Authenticator) RouterOption {
+	return func(cfg *routerConfig) { cfg.auth = append(cfg.auth, auths...) }
+}
+
+// WithRateLimit gives every client a bucket of read and one of write requests kept in store,
+// an empty limit leaves its routes unlimited
+func WithRateLimit(store port.RateLimitStore, read, write models.RateLimit) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.rateLimits = store
+		cfg.readLimit = read
+		cfg.writeLimit = write
+	}
+}
+
+// WithIPRateLimit limits the requests of every IP before its credentials are checked, so failed and guessed
+// credentials are limited too; without it the read limit is used. Needs WithRateLimit for the store.
+func WithIPRateLimit(limit models.RateLimit) RouterOption {
+	return func(cfg *routerConfig) { cfg.ipLimit = limit }
+}
+
+// WithTrustedProxies lets the reverse proxies at the IPs or CIDR ranges give the client IP in X-Forwarded-For,
+// without it the IP of the connection is the client IP
+func WithTrustedProxies(proxies ...string) RouterOption {
+	return func(cfg *routerConfig) { cfg.proxies = append(cfg.proxies, proxies...) }
+}
+
+// WithCacheStats enables /metrics with the counters of the lookup cache
+func WithCacheStats(stats func() models.CacheStats) RouterOption {
+	return func(cfg *routerConfig) { cfg.cacheStats = stats }
+}
+
 // SetupRouter sets all endpoints
-func SetupRouter(svc *usecases.SwiftService) *gin.Engine {
+func SetupRouter(svc *usecases.SwiftService, opts ...RouterOption) *gin.Engine {
+	var cfg routerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.proxies); err != nil {
+		log.Printf("invalid trusted proxies, using the IP of the connection: %v", err)
+		_ = r.SetTrustedProxies(nil)
+	}
 	// tu możesz dodać middleware: CORS, logging, recovery itd.
+	r.Use(requestContext())
 
 	// Health‑check
 	r.GET("/healthz", func(c *gin.Context) {
@@ -21,13 +99,65 @@ func SetupRouter(svc *usecases.SwiftService) *gin.Engine {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	read := requireRole(cfg.auth, models.RoleReader)
+	edit := requireRole(cfg.auth, models.RoleEditor)
+	readLimit := rateLimit(cfg.rateLimits, rateClassRead, cfg.readLimit)
+	writeLimit := rateLimit(cfg.rateLimits, rateClassWrite, cfg.writeLimit)
+	// credentials are checked only within the limit of the IP; an open API limits clients by IP already
+	ipRate := cfg.ipLimit
+	if !ipRate.Enabled() {
+		ipRate = cfg.readLimit
+	}
+	ipLimit := rateLimit(cfg.rateLimits, rateClassIP, ipRate)
+	authLimit := ipLimit
+	if len(cfg.auth) == 0 {
+		authLimit = rateLimit(nil, rateClassIP, ipRate)
+	}
+	// the audit log and the metrics show every caller's activity: only admins see them with authentication,
+	// holders of the import token without it, and everyone only when no credential is configured at all
+	admin, adminLimit := requireRole(cfg.auth, models.RoleAdmin), authLimit
+	if len(cfg.auth) == 0 && cfg.importToken != "" {
+		admin, adminLimit = requireToken(cfg.importToken), ipLimit
+	}
+
+	if cfg.cacheStats != nil {
+		r.GET("/metrics", adminLimit, admin, cacheMetrics(cfg.cacheStats))
+	}
+
 	handler := v1.NewSwiftHandler(svc)
-	group := r.Group("/v1/swift-codes")
+	group := r.Group("/v1/swift-codes", authLimit)
 	{
-		group.GET("/:swift-code", handler.GetSwiftCode)
-		group.GET("/country/:countryISO2code", handler.GetSwiftCodesByCountry)
-		group.POST("", handler.AddSwiftCode)
-		group.DELETE("/:swift-code", handler.DeleteSwiftCode)
+		group.GET("", read, readLimit, handler.ListSwiftCodes)
+		group.GET("/search", read, readLimit, handler.SearchSwiftCodes)
+		group.POST("/lookup", read, readLimit, handler.LookupSwiftCodes)
+		group.GET("/:swift-code", read, readLimit, handler.GetSwiftCode)
+		group.GET("/country/:countryISO2code", read, readLimit, handler.GetSwiftCodesByCountry)
+		group.POST("", edit, writeLimit, handler.AddSwiftCode)
+		group.PUT("/:swift-code", edit, writeLimit, handler.UpdateSwiftCode)
+		group.PATCH("/:swift-code", edit, writeLimit, handler.PatchSwiftCode)
+		group.DELETE("/:swift-code", edit, writeLimit, handler.DeleteSwiftCode)
+		group.POST("/:swift-code/restore", edit, writeLimit, handler.RestoreSwiftCode)
+		group.GET("/:swift-code/history", read, readLimit, handler.GetSwiftCodeHistory)
+		group.GET("/:swift-code/validate", read, readLimit, handler.ValidateSwiftCode)
+	}
+
+	if cfg.imports != nil {
+		imports := v1.NewImportHandler(cfg.imports)
+		// with authentication configured the importer role replaces the import token
+		guard := requireToken(cfg.importToken)
+		if len(cfg.auth) > 0 {
+			guard = requireRole(cfg.auth, models.RoleImporter)
+		}
+		group := r.Group("/v1/imports", ipLimit, guard)
+		{
+			group.POST("", writeLimit, imports.CreateImport)
+			group.GET("/:id", readLimit, imports.GetImport)
+		}
+	}
+
+	if cfg.audit != nil {
+		audit := v1.NewAuditHandler(cfg.audit)
+		r.GET("/v1/audit", adminLimit, admin, readLimit, audit.ListAuditEntries)
 	}
 
 	return r
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+type AuditHandler struct {
+	svc *usecases.AuditService
+}
+
+func NewAuditHandler(svc *usecases.AuditService) *AuditHandler {
+	return &AuditHandler{svc: svc}
+}
+
+// GET /v1/audit
+
+// ListAuditEntries
+// @Summary      Audit log of changes
+// @Description  Returns who created, updated, deleted, restored or purged SWIFT codes and who ran imports, oldest first.
+// @Description  Every entry has the actor (the authenticated caller, or the X-Actor header without authentication), the request ID (X-Request-ID header) and the code before and after the change.
+// @Description  With authentication configured only the admin role can read it; without it the import token is required as a bearer token, and the log is open only when neither is configured.
+// @Tags         audit
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swiftCode  query     string  false  "Only changes of this SWIFT code"
+// @Param        from       query     string  false  "Only entries at or after this RFC 3339 time"
+// @Param        to         query     string  false  "Only entries at or before this RFC 3339 time"
+// @Param        limit      query     int     false  "Maximum number of entries (default 50, max 500)"
+// @Success      200        {object}  models.AuditLogResponse
+// @Failure      400        {object}  map[string]string  "invalid SWIFT code, time or limit"
+// @Failure      401        {object}  map[string]string  "missing or invalid API key, bearer token or import token"
+// @Failure      403        {object}  map[string]string  "admin role required"
+// @Failure      429        {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      500        {object}  map[string]string  "internal server error"
+// @Router       /v1/audit [get]
+func (h *AuditHandler) ListAuditEntries(c *gin.Context) {
+	q := models.AuditQuery{SwiftCode: strings.ToUpper(strings.TrimSpace(c.Query(util.QuerySwiftCode)))}
+	var ok bool
+	if q.From, ok = queryTime(c, util.QueryFrom); !ok {
+		return
+	}
+	if q.To, ok = queryTime(c, util.QueryTo); !ok {
+		return
+	}
+	if q.Limit, ok = queryLimit(c); !ok {
+		return
+	}
+
+	resp, err := h.svc.ListAuditEntries(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// queryTime reads an optional RFC 3339 query parameter, responding 400 when it isn't one
+func queryTime(c *gin.Context, name string) (time.Time, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": name + " must be an RFC 3339 time, e.g. 2025-01-02T15:04:05Z"})
+		return time.Time{}, false
+	}
+	return t, true
+}
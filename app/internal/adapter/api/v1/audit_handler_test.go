@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+)
+
+type stubAudit struct {
+	lastQuery models.AuditQuery
+}
+
+func (s *stubAudit) Append(ctx context.Context, entry models.AuditEntry) error {
+	return nil
+}
+func (s *stubAudit) Find(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	s.lastQuery = q
+	return nil, nil
+}
+
+func TestListAuditEntries(t *testing.T) {
+	audit := &stubAudit{}
+	handler := NewAuditHandler(usecases.NewAuditService(audit))
+	r := gin.New()
+	r.GET("/v1/audit", handler.ListAuditEntries)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/audit"+query, nil))
+		return w
+	}
+
+	w := get("?swiftCode=abcdplpwxxx&from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00%2B02:00&limit=10")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	want := models.AuditQuery{
+		SwiftCode: "ABCDPLPWXXX",
+		From:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC),
+		Limit:     10,
+	}
+	if q := audit.lastQuery; q.SwiftCode != want.SwiftCode || !q.From.Equal(want.From) || !q.To.Equal(want.To) || q.Limit != want.Limit {
+		t.Errorf("query = %+v; want %+v", q, want)
+	}
+
+	for _, query := range []string{"?from=2025-01-01", "?to=now", "?limit=ten", "?swiftCode=BAD"} {
+		if w := get(query); w.Code != http.StatusBadRequest {
+			t.Errorf("GET /v1/audit%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
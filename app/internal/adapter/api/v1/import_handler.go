@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// maxUploadSize limits the size of an uploaded CSV file
+const maxUploadSize = 64 << 20
+
+type ImportHandler struct {
+	svc *usecases.ImportService
+}
+
+func NewImportHandler(svc *usecases.ImportService) *ImportHandler {
+	return &ImportHandler{svc: svc}
+}
+
+// POST /v1/imports
+
+// CreateImport
+// @Summary      Upload a CSV file to import
+// @Description  Starts a background import of the uploaded CSV (same format as CSV_PATH). Poll the returned job with GET /v1/imports/{id}.
+// @Tags         imports
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        file  formData  file  true  "SWIFT codes CSV"
+// @Success      202   {object}  models.ImportJob
+// @Failure      400   {object}  map[string]string  "missing or empty file"
+// @Failure      401   {object}  map[string]string  "missing or invalid token, API key or bearer token"
+// @Failure      403   {object}  map[string]string  "import token or importer role missing"
+// @Failure      429   {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      413   {object}  map[string]string  "file too large"
+// @Failure      500   {object}  map[string]string  "internal server error"
+// @Router       /v1/imports [post]
+func (h *ImportHandler) CreateImport(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+	header, err := c.FormFile(util.FormFile)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "uploaded file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": "multipart form with a CSV file field is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "cannot read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	job, err := h.svc.StartImport(c.Request.Context(), header.Filename, file)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.Header("Location", "/v1/imports/"+job.ID)
+	c.IndentedJSON(http.StatusAccepted, job)
+}
+
+// GET /v1/imports/:id
+
+// GetImport
+// @Summary      Get status of an import
+// @Description  Returns the import job; once finished it contains the import summary or the error.
+// @Tags         imports
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Import job ID"
+// @Success      200  {object}  models.ImportJob
+// @Failure      401  {object}  map[string]string  "missing or invalid token, API key or bearer token"
+// @Failure      403  {object}  map[string]string  "import token or importer role missing"
+// @Failure      429  {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      404  {object}  map[string]string  "import not found"
+// @Router       /v1/imports/{id} [get]
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	job, err := h.svc.GetImport(c.Request.Context(), c.Param(util.ParamImportID))
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, job)
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func setupImportRouter(repo port.SwiftRepository) *gin.Engine {
+	handler := NewImportHandler(usecases.NewImportService(repo, map[string]string{"PL": "POLAND"}))
+	r := gin.New()
+	r.POST("/v1/imports", handler.CreateImport)
+	r.GET("/v1/imports/:id", handler.GetImport)
+	return r
+}
+
+// multipartCSV builds a multipart body with the CSV in the "file" field
+func multipartCSV(t *testing.T, csv string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "codes.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(csv))
+	w.Close()
+	return &body, w.FormDataContentType()
+}
+
+func TestCreateImport_Polled(t *testing.T) {
+	repo := &stubRepo{}
+	router := setupImportRouter(repo)
+
+	body, contentType := multipartCSV(t, "COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME\nPL,AAAAPLPWXXX,Bank A,Addr A,POLAND\n")
+	req := httptest.NewRequest("POST", "/v1/imports", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body)
+	}
+	var job models.ImportJob
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if job.ID == "" || w.Header().Get("Location") != "/v1/imports/"+job.ID {
+		t.Fatalf("unexpected job %+v, location %q", job, w.Header().Get("Location"))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != models.ImportSucceeded && job.Status != models.ImportFailed {
+		if time.Now().After(deadline) {
+			t.Fatalf("import did not finish: %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/imports/"+job.ID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		job = models.ImportJob{}
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if job.Status != models.ImportSucceeded || job.Summary == nil {
+		t.Errorf("unexpected finished job %+v", job)
+	}
+}
+
+func TestCreateImport_BadRequest(t *testing.T) {
+	router := setupImportRouter(&stubRepo{})
+
+	req := httptest.NewRequest("POST", "/v1/imports", bytes.NewBufferString("not a form"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without multipart form, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/imports/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown import, got %d", w.Code)
+	}
+}
@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +12,9 @@ import (
 	"github.com/przemekk6973/swift-code-app/app/internal/util"
 )
 
+// maxLookupBodySize limits a lookup request, room for MaxLookupCodes codes with quotes and whitespace
+const maxLookupBodySize = util.MaxLookupCodes * 64
+
 type SwiftHandler struct {
 	svc *usecases.SwiftService
 }
@@ -23,22 +28,52 @@ func NewSwiftHandler(svc *usecases.SwiftService) *SwiftHandler {
 // GetSwiftCode
 // @Summary      Retrieve details for a single SWIFT code
 // @Description  Returns the headquarter with its branches if the code is HQ, or a single branch object if branch code.
+// @Description  The ETag header is the version of the headquarter document (shared by its branches); send it back as If-None-Match to get 304 when nothing changed, or as If-Match on writes.
+// @Description  With asOf the code is read from the version history as it was at that time, without ETag.
 // @Tags         swift-codes
 // @Accept       json
 // @Produce      json
-// @Param        swift-code   path      string            true  "SWIFT code (8 or 11 characters)"
-// @Success      200          {object}  models.SwiftCode
-// @Failure      400          {object}  map[string]string "invalid SWIFT code format"
-// @Failure      404          {object}  map[string]string "SWIFT code not found"
-// @Failure      500          {object}  map[string]string "internal server error"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code     path      string             true   "SWIFT code (8 or 11 characters)"
+// @Param        asOf           query     string             false  "RFC 3339 time to look the code up at"
+// @Param        If-None-Match  header    string             false  "ETag of a cached response"
+// @Success      200            {object}  models.SwiftCode
+// @Header       200            {string}  ETag               "Version of the stored headquarter"
+// @Success      304            "not modified since the If-None-Match ETag"
+// @Failure      400            {object}  map[string]string  "invalid SWIFT code format or asOf"
+// @Failure      401            {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403            {object}  map[string]string  "reader role required"
+// @Failure      429            {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      404            {object}  map[string]string  "SWIFT code not found"
+// @Failure      500            {object}  map[string]string  "internal server error"
 // @Router       /v1/swift-codes/{swift-code} [get]
 func (h *SwiftHandler) GetSwiftCode(c *gin.Context) {
 	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
+	asOf, ok := queryTime(c, util.QueryAsOf)
+	if !ok {
+		return
+	}
+	if !asOf.IsZero() {
+		swift, err := h.svc.GetSwiftCodeAsOf(c.Request.Context(), code, asOf)
+		if err != nil {
+			c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+			return
+		}
+		c.IndentedJSON(http.StatusOK, swift)
+		return
+	}
 	swift, err := h.svc.GetSwiftCodeDetails(c.Request.Context(), code)
 	if err != nil {
 		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
 		return
 	}
+	etag := util.ETag(swift.Version)
+	c.Header("ETag", etag)
+	if util.MatchesETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.IndentedJSON(http.StatusOK, swift)
 }
 
@@ -46,19 +81,187 @@ func (h *SwiftHandler) GetSwiftCode(c *gin.Context) {
 
 // GetSwiftCodesByCountry
 // @Summary      Retrieve all SWIFT codes for a country
-// @Description  Returns all headquarters and branches for a given country ISO2.
+// @Description  Returns one page of headquarters and branches for a given country ISO2, ordered by SWIFT code. Pass nextCursor from the response as cursor to get the next page.
+// @Description  With asOf the codes are read from the version history as they were at that time; keep the same asOf for every page.
 // @Tags         swift-codes
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Param        countryISO2code  path      string                                  true  "Country ISO2 code"
+// @Param        asOf             query     string                                  false "RFC 3339 time to look the codes up at"
+// @Param        cursor           query     string                                  false "Cursor returned as nextCursor by the previous page"
+// @Param        limit            query     int                                     false "Page size (default 50, max 500)"
 // @Success      200              {object}  models.CountrySwiftCodesResponse
-// @Failure      400              {object}  map[string]string                     "invalid ISO2 format"
+// @Failure      400              {object}  map[string]string                     "invalid ISO2 format, asOf, cursor or limit"
+// @Failure      401              {object}  map[string]string                     "missing or invalid API key or bearer token"
+// @Failure      403              {object}  map[string]string                     "reader role required"
+// @Failure      429              {object}  map[string]string                     "rate limit exceeded, see Retry-After"
 // @Failure      404              {object}  map[string]string                     "no SWIFT codes for country"
 // @Failure      500              {object}  map[string]string                     "internal server error"
 // @Router       /v1/swift-codes/country/{countryISO2code} [get]
 func (h *SwiftHandler) GetSwiftCodesByCountry(c *gin.Context) {
 	iso2 := strings.ToUpper(c.Param(util.ParamCountryISO2))
-	resp, err := h.svc.GetSwiftCodesByCountry(c.Request.Context(), iso2)
+	limit, ok := queryLimit(c)
+	if !ok {
+		return
+	}
+	asOf, ok := queryTime(c, util.QueryAsOf)
+	if !ok {
+		return
+	}
+	var resp models.CountrySwiftCodesResponse
+	var err error
+	if asOf.IsZero() {
+		resp, err = h.svc.GetSwiftCodesByCountry(c.Request.Context(), iso2, c.Query(util.QueryCursor), limit)
+	} else {
+		resp, err = h.svc.GetSwiftCodesByCountryAsOf(c.Request.Context(), iso2, c.Query(util.QueryCursor), limit, asOf)
+	}
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// GET /v1/swift-codes
+
+// ListSwiftCodes
+// @Summary      List SWIFT codes
+// @Description  Returns one page of headquarters and branches ordered by SWIFT code. Pass nextCursor from the response as cursor to get the next page.
+// @Tags         swift-codes
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        countryISO2    query     string  false  "Country ISO2 code"
+// @Param        isHeadquarter  query     bool    false  "Only headquarters (true) or only branches (false)"
+// @Param        bankName       query     string  false  "Bank name prefix (case-insensitive)"
+// @Param        town           query     string  false  "Town name (case-insensitive)"
+// @Param        timeZone       query     string  false  "IANA time zone, e.g. Europe/Warsaw"
+// @Param        cursor         query     string  false  "Cursor returned as nextCursor by the previous page"
+// @Param        limit          query     int     false  "Page size (default 50, max 500)"
+// @Success      200            {object}  models.SwiftCodeListResponse
+// @Failure      400            {object}  map[string]string  "invalid filter, cursor or limit"
+// @Failure      401            {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403            {object}  map[string]string  "reader role required"
+// @Failure      429            {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      500            {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes [get]
+func (h *SwiftHandler) ListSwiftCodes(c *gin.Context) {
+	filter := models.SwiftCodeQuery{
+		CountryISO2:    strings.ToUpper(c.Query(util.QueryCountryISO2)),
+		BankNamePrefix: c.Query(util.QueryBankName),
+		Town:           c.Query(util.QueryTown),
+		TimeZone:       c.Query(util.QueryTimeZone),
+	}
+	if v := c.Query(util.QueryIsHeadquarter); v != "" {
+		isHQ, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "isHeadquarter must be true or false"})
+			return
+		}
+		filter.IsHeadquarter = &isHQ
+	}
+	limit, ok := queryLimit(c)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
+
+	resp, err := h.svc.ListSwiftCodes(c.Request.Context(), filter, c.Query(util.QueryCursor))
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// GET /v1/swift-codes/search
+
+// SearchSwiftCodes
+// @Summary      Search SWIFT codes by bank name or address
+// @Description  Returns headquarters and branches whose bank name or address match all words of the query, best matches first. Prefixes and small typos are tolerated.
+// @Tags         swift-codes
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        q      query     string  true   "Search query, e.g. bank name or town"
+// @Param        limit  query     int     false  "Maximum number of results (default 50, max 500)"
+// @Success      200    {object}  models.SwiftCodeSearchResponse
+// @Failure      400    {object}  map[string]string  "missing or too long query, invalid limit"
+// @Failure      401    {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403    {object}  map[string]string  "reader role required"
+// @Failure      429    {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      500    {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes/search [get]
+func (h *SwiftHandler) SearchSwiftCodes(c *gin.Context) {
+	limit, ok := queryLimit(c)
+	if !ok {
+		return
+	}
+	resp, err := h.svc.SearchSwiftCodes(c.Request.Context(), c.Query(util.QuerySearch), limit)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// GET /v1/swift-codes/:swift-code/validate
+
+// ValidateSwiftCode
+// @Summary      Validate the structure of a SWIFT code
+// @Description  Breaks the code down into its ISO 9362 parts (institution, country, location and branch code) and lists every rule it breaks, without looking it up. The country must be in the country list when one is loaded.
+// @Description  Test codes (location code ending with 0) and passive participants (ending with 1) are flagged. An invalid code is still 200, with valid=false.
+// @Tags         swift-codes
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code  path      string  true  "SWIFT code to validate"
+// @Success      200         {object}  models.BICValidation
+// @Failure      401         {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403         {object}  map[string]string  "reader role required"
+// @Failure      429         {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Router       /v1/swift-codes/{swift-code}/validate [get]
+func (h *SwiftHandler) ValidateSwiftCode(c *gin.Context) {
+	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
+	c.IndentedJSON(http.StatusOK, h.svc.ValidateBIC(code))
+}
+
+// POST /v1/swift-codes/lookup
+
+// LookupSwiftCodes
+// @Summary      Look up many SWIFT codes at once
+// @Description  Returns a result for every requested code, in request order: hq or branch with the code details, notFound, or invalid with the reason. Codes are looked up together, not one request each.
+// @Tags         swift-codes
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        payload  body      models.SwiftCodeLookupRequest  true  "Codes to look up (at most 1000)"
+// @Success      200      {object}  models.SwiftCodeLookupResponse
+// @Failure      400      {object}  map[string]string  "invalid JSON, no codes or too many codes"
+// @Failure      401      {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403      {object}  map[string]string  "reader role required"
+// @Failure      413      {object}  map[string]string  "request body too large"
+// @Failure      429      {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      500      {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes/lookup [post]
+func (h *SwiftHandler) LookupSwiftCodes(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxLookupBodySize)
+	var req models.SwiftCodeLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "request body is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid JSON payload"})
+		return
+	}
+	resp, err := h.svc.LookupSwiftCodes(c.Request.Context(), req.Codes)
 	if err != nil {
 		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
 		return
@@ -71,12 +274,18 @@ func (h *SwiftHandler) GetSwiftCodesByCountry(c *gin.Context) {
 // AddSwiftCode
 // @Summary      Create a new SWIFT code entry
 // @Description  Adds either a headquarter (isHeadquarter=true) or a branch (isHeadquarter=false).
+// @Description  With a country list loaded, countryISO2 must be in it and countryName must be its name (any case); a missing countryName is filled in.
 // @Tags         swift-codes
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Param        payload  body      models.SwiftCode  true  "SWIFT code payload"
 // @Success      200      {object}  map[string]string  "swift code added"
-// @Failure      400      {object}  map[string]string  "invalid input or missing HQ for branch"
+// @Failure      400      {object}  map[string]string  "invalid input, unknown country ISO2, country name mismatch or missing HQ for branch"
+// @Failure      401      {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403      {object}  map[string]string  "editor role required"
+// @Failure      429      {object}  map[string]string  "rate limit exceeded, see Retry-After"
 // @Failure      409      {object}  map[string]string  "duplicate code"
 // @Failure      500      {object}  map[string]string  "internal server error"
 // @Router       /v1/swift-codes [post]
@@ -97,25 +306,213 @@ func (h *SwiftHandler) AddSwiftCode(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "swift code added"})
 }
 
+// PUT /v1/swift-codes/:swift-code
+
+// UpdateSwiftCode
+// @Summary      Replace a SWIFT code entry
+// @Description  Replaces bank name, address, country, town, code type and time zone of an existing headquarter (its branches are kept) or branch. The payload is validated like on create, a missing countryName is filled in from the country list; swiftCode may be omitted, otherwise it must match the path. A branch always shows the country name of its headquarter.
+// @Tags         swift-codes
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code  path      string            true  "SWIFT code to update"
+// @Param        If-Match    header    string            true  "ETag from GET, or * for any version"
+// @Param        payload     body      models.SwiftCode  true  "SWIFT code payload"
+// @Success      200         {object}  models.SwiftCode
+// @Header       200         {string}  ETag  "New version of the stored headquarter"
+// @Failure      400         {object}  map[string]string  "invalid input, unknown country ISO2 or country name mismatch"
+// @Failure      401         {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403         {object}  map[string]string  "editor role required"
+// @Failure      429         {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      404         {object}  map[string]string  "SWIFT code not found"
+// @Failure      412         {object}  map[string]string  "If-Match doesn't match the current version"
+// @Failure      428         {object}  map[string]string  "If-Match header missing"
+// @Failure      500         {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes/{swift-code} [put]
+func (h *SwiftHandler) UpdateSwiftCode(c *gin.Context) {
+	version, err := util.IfMatchVersion(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	var req models.SwiftCode
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid JSON payload"})
+		return
+	}
+	// Ensure uppercase codes
+	req.SwiftCode = strings.ToUpper(req.SwiftCode)
+	req.CountryISO2 = strings.ToUpper(req.CountryISO2)
+
+	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
+	swift, err := h.svc.UpdateSwiftCode(c.Request.Context(), code, version, req)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.Header("ETag", util.ETag(swift.Version))
+	c.IndentedJSON(http.StatusOK, swift)
+}
+
+// PATCH /v1/swift-codes/:swift-code
+
+// PatchSwiftCode
+// @Summary      Update fields of a SWIFT code entry
+// @Description  Changes only the fields present in the payload of an existing headquarter or branch. SWIFT code and isHeadquarter can't be changed. The result is validated like on create.
+// @Tags         swift-codes
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code  path      string                 true  "SWIFT code to update"
+// @Param        If-Match    header    string                 true  "ETag from GET, or * for any version"
+// @Param        payload     body      models.SwiftCodePatch  true  "Fields to change"
+// @Success      200         {object}  models.SwiftCode
+// @Header       200         {string}  ETag  "New version of the stored headquarter"
+// @Failure      400         {object}  map[string]string  "invalid input, unknown country ISO2 or country name mismatch"
+// @Failure      401         {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403         {object}  map[string]string  "editor role required"
+// @Failure      429         {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      404         {object}  map[string]string  "SWIFT code not found"
+// @Failure      412         {object}  map[string]string  "If-Match doesn't match the current version"
+// @Failure      428         {object}  map[string]string  "If-Match header missing"
+// @Failure      500         {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes/{swift-code} [patch]
+func (h *SwiftHandler) PatchSwiftCode(c *gin.Context) {
+	version, err := util.IfMatchVersion(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	var req models.SwiftCodePatch
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid JSON payload"})
+		return
+	}
+	if req.CountryISO2 != nil {
+		iso2 := strings.ToUpper(*req.CountryISO2)
+		req.CountryISO2 = &iso2
+	}
+
+	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
+	swift, err := h.svc.PatchSwiftCode(c.Request.Context(), code, version, req)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.Header("ETag", util.ETag(swift.Version))
+	c.IndentedJSON(http.StatusOK, swift)
+}
+
 // DELETE /v1/swift-codes/:swift-code
 
 // DeleteSwiftCode
 // @Summary      Delete a SWIFT code entry
 // @Description  Deletes a headquarter (and all its branches) if code ends with XXX, or a single branch otherwise.
+// @Description  Deleted codes are hidden but kept, with who deleted them and when, so they can be restored until purged after the retention period.
 // @Tags         swift-codes
 // @Accept       json
 // @Produce      json
-// @Param        swift-code  path      string            true  "SWIFT code to delete"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code  path      string            true   "SWIFT code to delete"
+// @Param        If-Match    header    string            true   "ETag from GET, or * for any version"
+// @Param        X-Actor     header    string            false  "Who deletes the code, recorded with it and in the audit log; ignored with authentication, the caller is recorded instead"
 // @Success      200         {object}  map[string]string  "swift code deleted"
 // @Failure      400         {object}  map[string]string  "invalid SWIFT code format"
+// @Failure      401         {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403         {object}  map[string]string  "editor role required"
+// @Failure      429         {object}  map[string]string  "rate limit exceeded, see Retry-After"
 // @Failure      404         {object}  map[string]string  "SWIFT code not found"
+// @Failure      412         {object}  map[string]string  "If-Match doesn't match the current version"
+// @Failure      428         {object}  map[string]string  "If-Match header missing"
 // @Failure      500         {object}  map[string]string  "internal server error"
 // @Router       /v1/swift-codes/{swift-code} [delete]
 func (h *SwiftHandler) DeleteSwiftCode(c *gin.Context) {
+	version, err := util.IfMatchVersion(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
 	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
-	if err := h.svc.DeleteSwiftCode(c.Request.Context(), code); err != nil {
+	if err := h.svc.DeleteSwiftCode(c.Request.Context(), code, version); err != nil {
 		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "swift code deleted"})
 }
+
+// GET /v1/swift-codes/:swift-code/history
+
+// GetSwiftCodeHistory
+// @Summary      Version history of a SWIFT code
+// @Description  Returns every version of a headquarter or branch, oldest first: its details from validFrom until validTo (the start of the next version, none for the current one).
+// @Description  A deleted version keeps the details the code had when it was deleted. Headquarter versions don't list branches, each branch has its own history.
+// @Tags         swift-codes
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code  path      string  true  "SWIFT code"
+// @Success      200         {object}  models.SwiftCodeHistoryResponse
+// @Failure      400         {object}  map[string]string  "invalid SWIFT code format"
+// @Failure      401         {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403         {object}  map[string]string  "reader role required"
+// @Failure      429         {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      404         {object}  map[string]string  "no history of the SWIFT code"
+// @Failure      500         {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes/{swift-code}/history [get]
+func (h *SwiftHandler) GetSwiftCodeHistory(c *gin.Context) {
+	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
+	resp, err := h.svc.GetSwiftCodeHistory(c.Request.Context(), code)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, resp)
+}
+
+// POST /v1/swift-codes/:swift-code/restore
+
+// RestoreSwiftCode
+// @Summary      Restore a deleted SWIFT code entry
+// @Description  Brings back a deleted headquarter with the branches deleted together with it, or a deleted branch. A branch of a deleted headquarter can be restored only after its headquarter.
+// @Tags         swift-codes
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        swift-code  path      string             true  "Deleted SWIFT code"
+// @Success      200         {object}  models.SwiftCode
+// @Header       200         {string}  ETag               "New version of the stored headquarter"
+// @Failure      400         {object}  map[string]string  "invalid SWIFT code format"
+// @Failure      401         {object}  map[string]string  "missing or invalid API key or bearer token"
+// @Failure      403         {object}  map[string]string  "editor role required"
+// @Failure      429         {object}  map[string]string  "rate limit exceeded, see Retry-After"
+// @Failure      404         {object}  map[string]string  "no deleted SWIFT code"
+// @Failure      409         {object}  map[string]string  "headquarter of the branch is deleted"
+// @Failure      500         {object}  map[string]string  "internal server error"
+// @Router       /v1/swift-codes/{swift-code}/restore [post]
+func (h *SwiftHandler) RestoreSwiftCode(c *gin.Context) {
+	code := strings.ToUpper(c.Param(util.ParamSwiftCode))
+	swift, err := h.svc.RestoreSwiftCode(c.Request.Context(), code)
+	if err != nil {
+		c.JSON(util.StatusCodeFromError(err), gin.H{"message": err.Error()})
+		return
+	}
+	c.Header("ETag", util.ETag(swift.Version))
+	c.IndentedJSON(http.StatusOK, swift)
+}
+
+// queryLimit reads the optional limit query parameter, responding 400 when it isn't a number
+func queryLimit(c *gin.Context) (int, bool) {
+	v := c.Query(util.QueryLimit)
+	if v == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "limit must be a number"})
+		return 0, false
+	}
+	return limit, true
+}
@@ -2,25 +2,33 @@
 package v1
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/usecases"
 	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
 )
 
 // stubRepo implements port.SwiftRepository with controllable behavior.
 type stubRepo struct {
-	getCode    func(ctx context.Context, code string) (models.SwiftCode, error)
-	getCountry func(ctx context.Context, iso2 string) ([]models.SwiftCode, error)
-	addCode    func(ctx context.Context, sc models.SwiftCode) error
-	deleteCode func(ctx context.Context, code string) error
+	getCode     func(ctx context.Context, code string) (models.SwiftCode, error)
+	list        func(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error)
+	search      func(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error)
+	addCode     func(ctx context.Context, sc models.SwiftCode) error
+	updateCode  func(ctx context.Context, sc models.SwiftCode) error
+	deleteCode  func(ctx context.Context, code string, version int64, actor string) error
+	restoreCode func(ctx context.Context, code string) error
 }
 
 func (s *stubRepo) SaveHeadquarters(context.Context, []models.SwiftCode) (models.ImportSummary, error) {
@@ -32,15 +40,46 @@ func (s *stubRepo) SaveBranches(context.Context, []models.SwiftCode) (models.Imp
 func (s *stubRepo) GetByCode(ctx context.Context, code string) (models.SwiftCode, error) {
 	return s.getCode(ctx, code)
 }
-func (s *stubRepo) GetByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, error) {
-	return s.getCountry(ctx, iso2)
+func (s *stubRepo) GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error) {
+	found := make(map[string]models.SwiftCode)
+	for _, code := range codes {
+		sc, err := s.getCode(ctx, code)
+		if err == port.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found[code] = sc
+	}
+	return found, nil
+}
+func (s *stubRepo) List(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	return s.list(ctx, q)
+}
+func (s *stubRepo) Walk(ctx context.Context, fn func(models.SwiftCode) error) error {
+	// not used in handler tests
+	return nil
+}
+func (s *stubRepo) Search(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error) {
+	return s.search(ctx, query, limit)
 }
 func (s *stubRepo) AddBranch(ctx context.Context, hqCode string, br models.SwiftBranch) error {
 	// not used in handler tests
 	return nil
 }
-func (s *stubRepo) Delete(ctx context.Context, code string) error {
-	return s.deleteCode(ctx, code)
+func (s *stubRepo) Update(ctx context.Context, sc models.SwiftCode) error {
+	return s.updateCode(ctx, sc)
+}
+func (s *stubRepo) Delete(ctx context.Context, code string, version int64, actor string) error {
+	return s.deleteCode(ctx, code, version, actor)
+}
+func (s *stubRepo) Restore(ctx context.Context, code string) error {
+	return s.restoreCode(ctx, code)
+}
+func (s *stubRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+	// not used in handler tests
+	return 0, nil
 }
 func (s *stubRepo) Ping(ctx context.Context) error {
 	return nil
@@ -50,10 +89,18 @@ func setupRouterWithStub(repo port.SwiftRepository) *gin.Engine {
 	svc := usecases.NewSwiftService(repo)
 	handler := NewSwiftHandler(svc)
 	r := gin.New()
+	r.GET("/v1/swift-codes", handler.ListSwiftCodes)
+	r.GET("/v1/swift-codes/search", handler.SearchSwiftCodes)
+	r.POST("/v1/swift-codes/lookup", handler.LookupSwiftCodes)
 	r.GET("/v1/swift-codes/:swift-code", handler.GetSwiftCode)
 	r.GET("/v1/swift-codes/country/:countryISO2code", handler.GetSwiftCodesByCountry)
 	r.POST("/v1/swift-codes", handler.AddSwiftCode)
+	r.PUT("/v1/swift-codes/:swift-code", handler.UpdateSwiftCode)
+	r.PATCH("/v1/swift-codes/:swift-code", handler.PatchSwiftCode)
 	r.DELETE("/v1/swift-codes/:swift-code", handler.DeleteSwiftCode)
+	r.POST("/v1/swift-codes/:swift-code/restore", handler.RestoreSwiftCode)
+	r.GET("/v1/swift-codes/:swift-code/history", handler.GetSwiftCodeHistory)
+	r.GET("/v1/swift-codes/:swift-code/validate", handler.ValidateSwiftCode)
 	return r
 }
 
@@ -122,3 +169,430 @@ func TestGetSwiftCode_InternalError(t *testing.T) {
 		t.Fatalf("expected 500, got %d", w.Code)
 	}
 }
+
+func TestListSwiftCodes_Filters(t *testing.T) {
+	var got models.SwiftCodeQuery
+	repo := &stubRepo{
+		list: func(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+			got = q
+			return []models.SwiftCode{{SwiftCode: "ABCDPLPWXXX", CountryISO2: "PL", IsHeadquarter: true}}, nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	req := httptest.NewRequest("GET", "/v1/swift-codes?countryISO2=pl&isHeadquarter=true&bankName=ab&town=Warsaw&timeZone=Europe/Warsaw&limit=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got.CountryISO2 != "PL" || got.IsHeadquarter == nil || !*got.IsHeadquarter ||
+		got.BankNamePrefix != "ab" || got.Town != "Warsaw" || got.TimeZone != "Europe/Warsaw" || got.Limit != 11 {
+		t.Errorf("unexpected repository query: %+v", got)
+	}
+	var resp models.SwiftCodeListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SwiftCodes) != 1 || resp.NextCursor != "" {
+		t.Errorf("unexpected body: %+v", resp)
+	}
+}
+
+func TestListSwiftCodes_BadQuery(t *testing.T) {
+	router := setupRouterWithStub(&stubRepo{})
+	for _, query := range []string{"isHeadquarter=maybe", "limit=ten", "limit=100000"} {
+		req := httptest.NewRequest("GET", "/v1/swift-codes?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestGetSwiftCodesByCountry_Paged(t *testing.T) {
+	var got models.SwiftCodeQuery
+	repo := &stubRepo{
+		list: func(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+			got = q
+			return []models.SwiftCode{
+				{SwiftCode: "ABCDPLPW001", CountryISO2: "PL", CountryName: "POLAND"},
+				{SwiftCode: "ABCDPLPWXXX", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+			}, nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	req := httptest.NewRequest("GET", "/v1/swift-codes/country/pl?limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got.CountryISO2 != "PL" || got.Limit != 2 {
+		t.Errorf("unexpected repository query: %+v", got)
+	}
+	var resp models.CountrySwiftCodesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.CountryName != "POLAND" || len(resp.SwiftCodes) != 1 || resp.NextCursor == "" {
+		t.Errorf("unexpected body: %+v", resp)
+	}
+}
+
+func TestSearchSwiftCodes_Success(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	repo := &stubRepo{
+		search: func(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error) {
+			gotQuery, gotLimit = query, limit
+			return []models.SwiftCodeSearchHit{{
+				SwiftBranch: models.SwiftBranch{SwiftCode: "ABCDPLPWXXX", BankName: "ABC Bank", CountryISO2: "PL", IsHeadquarter: true},
+				Score:       1.5,
+			}}, nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	req := httptest.NewRequest("GET", "/v1/swift-codes/search?q=abc+bank&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if gotQuery != "abc bank" || gotLimit != 5 {
+		t.Errorf("unexpected repository call: %q, %d", gotQuery, gotLimit)
+	}
+	var resp models.SwiftCodeSearchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Query != "abc bank" || len(resp.Results) != 1 || resp.Results[0].SwiftCode != "ABCDPLPWXXX" || resp.Results[0].Score != 1.5 {
+		t.Errorf("unexpected body: %+v", resp)
+	}
+}
+
+func TestSearchSwiftCodes_BadQuery(t *testing.T) {
+	router := setupRouterWithStub(&stubRepo{})
+	for _, query := range []string{"", "q=", "q=bank&limit=ten", "q=bank&limit=-1"} {
+		req := httptest.NewRequest("GET", "/v1/swift-codes/search?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestLookupSwiftCodes(t *testing.T) {
+	repo := &stubRepo{
+		getCode: func(ctx context.Context, code string) (models.SwiftCode, error) {
+			switch code {
+			case "ABCDPLPWXXX":
+				return models.SwiftCode{SwiftCode: code, BankName: "ABC Bank", IsHeadquarter: true}, nil
+			case "ABCDPLPW001":
+				return models.SwiftCode{SwiftCode: code, BankName: "ABC Branch"}, nil
+			}
+			return models.SwiftCode{}, port.ErrNotFound
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	body := `{"codes": ["abcdplpwxxx", "ABCDPLPW001", "ZZZZPLPWXXX", "AB-CD"]}`
+	req := httptest.NewRequest("POST", "/v1/swift-codes/lookup", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var resp models.SwiftCodeLookupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range resp.Results {
+		got = append(got, r.Code+"="+r.Status)
+	}
+	want := []string{"ABCDPLPWXXX=hq", "ABCDPLPW001=branch", "ZZZZPLPWXXX=notFound", "AB-CD=invalid"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v; want %v", got, want)
+	}
+	if resp.Results[1].SwiftCode == nil || resp.Results[1].SwiftCode.BankName != "ABC Branch" {
+		t.Errorf("branch result = %+v; want its details", resp.Results[1])
+	}
+
+	for _, body := range []string{`{"codes": []}`, `{"codes": "ABCDPLPWXXX"}`, `not json`} {
+		req := httptest.NewRequest("POST", "/v1/swift-codes/lookup", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+
+	// the largest accepted lookup fits, a body far beyond it isn't read
+	for size, status := range map[int]int{util.MaxLookupCodes: http.StatusOK, 10 * util.MaxLookupCodes: http.StatusRequestEntityTooLarge} {
+		codes := make([]string, size)
+		for i := range codes {
+			codes[i] = "ZZZZPLPWXXX"
+		}
+		body, _ := json.MarshalIndent(map[string][]string{"codes": codes}, "", "    ")
+		req := httptest.NewRequest("POST", "/v1/swift-codes/lookup", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("%d codes: expected %d, got %d", size, status, w.Code)
+		}
+	}
+}
+
+func TestValidateSwiftCode(t *testing.T) {
+	router := setupRouterWithStub(&stubRepo{})
+
+	for code, valid := range map[string]bool{"brexplpwxxx": true, "BREXPLP0": true, "BR3XPLPWX01": false} {
+		req := httptest.NewRequest("GET", "/v1/swift-codes/"+code+"/validate", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", code, w.Code)
+		}
+		var resp models.BICValidation
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Valid != valid || resp.Code != strings.ToUpper(code) || resp.Violations == nil {
+			t.Errorf("%s: unexpected breakdown %+v", code, resp)
+		}
+	}
+}
+
+func TestUpdateSwiftCode_Success(t *testing.T) {
+	var updated models.SwiftCode
+	repo := &stubRepo{
+		updateCode: func(ctx context.Context, sc models.SwiftCode) error {
+			updated = sc
+			return nil
+		},
+		getCode: func(ctx context.Context, code string) (models.SwiftCode, error) {
+			return updated, nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	body := `{"bankName":"New Bank","address":"New Addr","countryISO2":"pl","countryName":"POLAND","isHeadquarter":true}`
+	req := httptest.NewRequest("PUT", "/v1/swift-codes/abcdplpwxxx", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"4"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if updated.SwiftCode != "ABCDPLPWXXX" || updated.CountryISO2 != "PL" || updated.BankName != "New Bank" || updated.Version != 4 {
+		t.Errorf("unexpected repository update: %+v", updated)
+	}
+	var resp models.SwiftCode
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.BankName != "New Bank" {
+		t.Errorf("unexpected body: %+v", resp)
+	}
+}
+
+func TestUpdateSwiftCode_BadRequest(t *testing.T) {
+	router := setupRouterWithStub(&stubRepo{})
+	for name, body := range map[string]string{
+		"invalid JSON":      `{`,
+		"other code":        `{"swiftCode":"ZZZZPLPWXXX","countryISO2":"PL","isHeadquarter":true}`,
+		"branch flag on HQ": `{"countryISO2":"PL","isHeadquarter":false}`,
+		"invalid country":   `{"countryISO2":"POL","isHeadquarter":true}`,
+	} {
+		req := httptest.NewRequest("PUT", "/v1/swift-codes/ABCDPLPWXXX", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+}
+
+func TestPatchSwiftCode(t *testing.T) {
+	current := models.SwiftCode{SwiftCode: "ABCDPLPW001", BankName: "Bank", Address: "Old Addr", CountryISO2: "PL", CountryName: "POLAND"}
+	var updated models.SwiftCode
+	repo := &stubRepo{
+		getCode: func(ctx context.Context, code string) (models.SwiftCode, error) {
+			if code != current.SwiftCode {
+				return models.SwiftCode{}, port.ErrNotFound
+			}
+			return current, nil
+		},
+		updateCode: func(ctx context.Context, sc models.SwiftCode) error {
+			updated = sc
+			return nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	patch := func(code, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/v1/swift-codes/"+code, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := patch("ABCDPLPW001", `{"address":"New Addr","timeZone":"Europe/Warsaw"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	want := current
+	want.Address, want.TimeZone = "New Addr", "Europe/Warsaw"
+	if !reflect.DeepEqual(updated, want) {
+		t.Errorf("repository update = %+v; want %+v", updated, want)
+	}
+
+	if w := patch("ABCDPLPW001", `{"countryISO2":"P1"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid country: expected 400, got %d", w.Code)
+	}
+	if w := patch("ZZZZPLPW001", `{"address":"New Addr"}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown code: expected 404, got %d", w.Code)
+	}
+}
+
+func TestGetSwiftCode_ETag(t *testing.T) {
+	repo := &stubRepo{
+		getCode: func(ctx context.Context, code string) (models.SwiftCode, error) {
+			return models.SwiftCode{SwiftCode: code, CountryISO2: "PL", IsHeadquarter: true, Version: 3}, nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/swift-codes/ABCDPLPWXXX", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+		t.Fatalf("expected 200 with ETag \"3\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w = get(`"3"`); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != `"3"` {
+		t.Errorf("current ETag: expected empty 304 with ETag, got %d %q", w.Code, w.Body)
+	}
+	if w = get(`"2"`); w.Code != http.StatusOK {
+		t.Errorf("outdated ETag: expected 200, got %d", w.Code)
+	}
+}
+
+func TestDeleteSwiftCode_IfMatch(t *testing.T) {
+	var gotVersion int64
+	var gotActor string
+	repo := &stubRepo{
+		getCode: func(ctx context.Context, code string) (models.SwiftCode, error) {
+			return models.SwiftCode{SwiftCode: code, IsHeadquarter: true, Version: 3}, nil
+		},
+		deleteCode: func(ctx context.Context, code string, version int64, actor string) error {
+			gotVersion, gotActor = version, actor
+			if version == 2 {
+				return port.ErrVersionMismatch
+			}
+			return nil
+		},
+	}
+	router := setupRouterWithStub(repo)
+
+	del := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/v1/swift-codes/ABCDPLPWXXX", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := del(""); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("no If-Match: expected 428, got %d", w.Code)
+	}
+	if w := del(`"2"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("outdated If-Match: expected 412, got %d", w.Code)
+	}
+	if w := del(`"3"`); w.Code != http.StatusOK || gotVersion != 3 || gotActor != "anonymous" {
+		t.Errorf("current If-Match: expected 200 deleting version 3 anonymously, got %d deleting %d by %q", w.Code, gotVersion, gotActor)
+	}
+	if w := del("*"); w.Code != http.StatusOK || gotVersion != 0 {
+		t.Errorf("If-Match *: expected 200 deleting any version, got %d deleting %d", w.Code, gotVersion)
+	}
+}
+
+func TestRestoreSwiftCode(t *testing.T) {
+	branch := models.SwiftCode{SwiftCode: "ABCDPLPW001", BankName: "Branch", CountryISO2: "PL", Version: 5}
+	restoreErr := port.ErrHQNotFound
+	router := setupRouterWithStub(&stubRepo{
+		restoreCode: func(ctx context.Context, code string) error {
+			return restoreErr
+		},
+		getCode: func(ctx context.Context, code string) (models.SwiftCode, error) {
+			return branch, nil
+		},
+	})
+	restore := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/v1/swift-codes/abcdplpw001/restore", nil))
+		return w
+	}
+
+	if w := restore(); w.Code != http.StatusConflict {
+		t.Errorf("branch of deleted HQ: expected 409, got %d: %s", w.Code, w.Body)
+	}
+	restoreErr = port.ErrNotFound
+	if w := restore(); w.Code != http.StatusNotFound {
+		t.Errorf("not deleted: expected 404, got %d", w.Code)
+	}
+	restoreErr = nil
+	w := restore()
+	var got models.SwiftCode
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected 200 JSON, got %d: %s", w.Code, w.Body)
+	}
+	if got.SwiftCode != branch.SwiftCode || w.Header().Get("ETag") != `"5"` {
+		t.Errorf("restored %+v with ETag %q; want %s with \"5\"", got, w.Header().Get("ETag"), branch.SwiftCode)
+	}
+}
+
+func TestGetSwiftCode_AsOf(t *testing.T) {
+	// the stub service records no history
+	router := setupRouterWithStub(&stubRepo{})
+	for _, path := range []string{
+		"/v1/swift-codes/ABCDPLPWXXX?asOf=2025-13-01T00:00:00Z",
+		"/v1/swift-codes/country/PL?asOf=yesterday",
+		"/v1/swift-codes/ABCDPLPWXXX?asOf=2025-01-01T00:00:00Z",
+		"/v1/swift-codes/country/PL?asOf=2025-01-01T00:00:00Z",
+		"/v1/swift-codes/ABCDPLPWXXX/history",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
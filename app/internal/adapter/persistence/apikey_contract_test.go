@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// testAPIKeys are the keys the API key stores are opened with in tests
+var testAPIKeys = []models.APIKey{
+	{Name: "ci", Hash: util.HashAPIKey("ci-secret"), Roles: []string{models.RoleImporter}},
+	{Name: "backoffice", Hash: util.HashAPIKey("backoffice-secret"), Roles: []string{models.RoleReader, models.RoleEditor}},
+}
+
+func TestAPIKeyRepository_Contract(t *testing.T) {
+	runAdapters(t, map[string]func(t *testing.T) port.APIKeyRepository{
+		"memory": openMemoryAPIKeyRepository,
+		"file":   openFileAPIKeyRepository,
+		"mongo":  openMongoAPIKeyRepository,
+	}, func(t *testing.T, keys port.APIKeyRepository) {
+		ctx := context.Background()
+
+		k, err := keys.FindAPIKey(ctx, util.HashAPIKey("backoffice-secret"))
+		if err != nil {
+			t.Fatalf("FindAPIKey failed: %v", err)
+		}
+		if k.Name != "backoffice" || len(k.Roles) != 2 || k.Roles[1] != models.RoleEditor {
+			t.Errorf("FindAPIKey = %+v; want backoffice with reader and editor", k)
+		}
+
+		if _, err := keys.FindAPIKey(ctx, util.HashAPIKey("unknown")); !errors.Is(err, port.ErrNotFound) {
+			t.Errorf("FindAPIKey(unknown) err = %v; want ErrNotFound", err)
+		}
+		// the key itself is never a valid lookup
+		if _, err := keys.FindAPIKey(ctx, "ci-secret"); !errors.Is(err, port.ErrNotFound) {
+			t.Errorf("FindAPIKey(plain key) err = %v; want ErrNotFound", err)
+		}
+	})
+}
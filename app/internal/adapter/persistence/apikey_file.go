@@ -0,0 +1,24 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// NewFileAPIKeyRepository loads API keys from a JSON array of {"name", "hash", "roles"} objects.
+// The file is read once, restart to pick up changes.
+func NewFileAPIKeyRepository(path string) (port.APIKeyRepository, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []models.APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return NewMemoryAPIKeyRepository(keys...)
+}
@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// openFileAPIKeyRepository reads testAPIKeys from a temporary file
+func openFileAPIKeyRepository(t *testing.T) port.APIKeyRepository {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data, _ := json.Marshal(testAPIKeys)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewFileAPIKeyRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestFileAPIKeyRepository_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileAPIKeyRepository(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	path := filepath.Join(dir, "keys.json")
+	os.WriteFile(path, []byte(`{"name": "not an array"}`), 0o600)
+	if _, err := NewFileAPIKeyRepository(path); err == nil {
+		t.Error("expected error for malformed file")
+	}
+}
@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MemoryAPIKeyRepository implements port.APIKeyRepository over a fixed set of keys
+type MemoryAPIKeyRepository struct {
+	byHash map[string]models.APIKey
+}
+
+// NewMemoryAPIKeyRepository checks the keys and keeps them in memory
+func NewMemoryAPIKeyRepository(keys ...models.APIKey) (port.APIKeyRepository, error) {
+	byHash := make(map[string]models.APIKey, len(keys))
+	for i, k := range keys {
+		if err := validateAPIKey(k); err != nil {
+			return nil, fmt.Errorf("API key %d: %w", i+1, err)
+		}
+		if _, dup := byHash[k.Hash]; dup {
+			return nil, fmt.Errorf("API key %q: duplicate hash", k.Name)
+		}
+		byHash[k.Hash] = k
+	}
+	return &MemoryAPIKeyRepository{byHash: byHash}, nil
+}
+
+// FindAPIKey returns the key with the hash
+func (r *MemoryAPIKeyRepository) FindAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	k, ok := r.byHash[hash]
+	if !ok {
+		return models.APIKey{}, port.ErrNotFound
+	}
+	return k, nil
+}
+
+// validateAPIKey requires a name, a hex SHA-256 hash and at least one known role
+func validateAPIKey(k models.APIKey) error {
+	if k.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if b, err := hex.DecodeString(k.Hash); err != nil || len(b) != 32 {
+		return fmt.Errorf("%q: hash must be a hex SHA-256", k.Name)
+	}
+	if len(k.Roles) == 0 {
+		return fmt.Errorf("%q: at least one role is required", k.Name)
+	}
+	for _, role := range k.Roles {
+		if !models.ValidRole(role) {
+			return fmt.Errorf("%q: unknown role %q (want %s, %s, %s or %s)", k.Name, role, models.RoleReader, models.RoleEditor, models.RoleImporter, models.RoleAdmin)
+		}
+	}
+	return nil
+}
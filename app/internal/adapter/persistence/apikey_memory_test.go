@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// openMemoryAPIKeyRepository holds testAPIKeys
+func openMemoryAPIKeyRepository(t *testing.T) port.APIKeyRepository {
+	keys, err := NewMemoryAPIKeyRepository(testAPIKeys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestMemoryAPIKeyRepository_Invalid(t *testing.T) {
+	hash := util.HashAPIKey("secret")
+	tests := []struct {
+		name string
+		keys []models.APIKey
+	}{
+		{"no name", []models.APIKey{{Hash: hash, Roles: []string{models.RoleReader}}}},
+		{"plain key", []models.APIKey{{Name: "a", Hash: "secret", Roles: []string{models.RoleReader}}}},
+		{"no roles", []models.APIKey{{Name: "a", Hash: hash}}},
+		{"unknown role", []models.APIKey{{Name: "a", Hash: hash, Roles: []string{"superuser"}}}},
+		{"duplicate", []models.APIKey{
+			{Name: "a", Hash: hash, Roles: []string{models.RoleReader}},
+			{Name: "b", Hash: hash, Roles: []string{models.RoleEditor}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMemoryAPIKeyRepository(tt.keys...); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
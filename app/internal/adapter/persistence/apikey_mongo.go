@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MongoAPIKeyRepository implements port.APIKeyRepository for MongoDB, keys are managed directly in the collection
+type MongoAPIKeyRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoAPIKeyRepository creates connection with MongoDB and ensures the hashes are unique
+func NewMongoAPIKeyRepository(uri, dbName, collName string) (port.APIKeyRepository, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+	_, err = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoAPIKeyRepository{client: client, collection: coll}, nil
+}
+
+// FindAPIKey returns the key with the hash
+func (r *MongoAPIKeyRepository) FindAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	var k models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&k)
+	if err == mongo.ErrNoDocuments {
+		return models.APIKey{}, port.ErrNotFound
+	}
+	return k, err
+}
+
+// Close closes MongoDB connection
+func (r *MongoAPIKeyRepository) Close(ctx context.Context) error {
+	return r.client.Disconnect(ctx)
+}
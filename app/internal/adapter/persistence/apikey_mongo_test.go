@@ -0,0 +1,31 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+const testAPIKeyCollection = "test_api_keys"
+
+// openMongoAPIKeyRepository connects to a collection holding testAPIKeys only: skips the test if Mongo isn't running
+func openMongoAPIKeyRepository(t *testing.T) port.APIKeyRepository {
+	keys, err := NewMongoAPIKeyRepository(testURI, testDB, testAPIKeyCollection)
+	if err != nil {
+		t.Skipf("skipping Mongo tests; cannot connect: %v", err)
+	}
+	repo := keys.(*MongoAPIKeyRepository)
+	ctx := context.Background()
+	t.Cleanup(func() { repo.Close(ctx) })
+	// clean slate, keeping the unique index
+	repo.collection.DeleteMany(ctx, bson.M{})
+	for _, k := range testAPIKeys {
+		if _, err := repo.collection.InsertOne(ctx, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
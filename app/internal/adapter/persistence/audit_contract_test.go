@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestAuditRepository_Contract(t *testing.T) {
+	runAdapters(t, map[string]func(t *testing.T) port.AuditRepository{
+		"memory": func(*testing.T) port.AuditRepository { return NewMemoryAuditRepository() },
+		"mongo":  openMongoAuditRepository,
+	}, func(t *testing.T, log port.AuditRepository) {
+		ctx := context.Background()
+		start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		before := &models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, Version: 1}
+		after := &models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A2", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true, Version: 2}
+		entries := []models.AuditEntry{
+			{Timestamp: start, Action: models.AuditCreate, SwiftCode: "AAAAPLPWXXX", Actor: "alice", RequestID: "r1", After: before},
+			{Timestamp: start.Add(time.Minute), Action: models.AuditImport, Actor: "startup", Import: &models.ImportSummary{HQAdded: 2}},
+			{Timestamp: start.Add(2 * time.Minute), Action: models.AuditUpdate, SwiftCode: "AAAAPLPWXXX", Actor: "bob", Before: before, After: after},
+			{Timestamp: start.Add(3 * time.Minute), Action: models.AuditDelete, SwiftCode: "BBBBDEFFXXX", Actor: "bob"},
+		}
+		for _, e := range entries {
+			if err := log.Append(ctx, e); err != nil {
+				t.Fatalf("Append failed: %v", err)
+			}
+		}
+
+		tests := []struct {
+			name  string
+			query models.AuditQuery
+			want  []string // actions, oldest first
+		}{
+			{"all", models.AuditQuery{}, []string{models.AuditCreate, models.AuditImport, models.AuditUpdate, models.AuditDelete}},
+			{"by code", models.AuditQuery{SwiftCode: "AAAAPLPWXXX"}, []string{models.AuditCreate, models.AuditUpdate}},
+			{"bounds inclusive", models.AuditQuery{From: start.Add(time.Minute), To: start.Add(2 * time.Minute)}, []string{models.AuditImport, models.AuditUpdate}},
+			{"code and from", models.AuditQuery{SwiftCode: "AAAAPLPWXXX", From: start.Add(time.Second)}, []string{models.AuditUpdate}},
+			{"limit", models.AuditQuery{Limit: 2}, []string{models.AuditCreate, models.AuditImport}},
+			{"no match", models.AuditQuery{SwiftCode: "CCCCGB2LXXX"}, nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := log.Find(ctx, tt.query)
+				if err != nil {
+					t.Fatalf("Find failed: %v", err)
+				}
+				var got []string
+				for _, e := range found {
+					got = append(got, e.Action)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("actions = %v; want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("actions = %v; want %v", got, tt.want)
+					}
+				}
+			})
+		}
+
+		t.Run("snapshots round trip", func(t *testing.T) {
+			found, err := log.Find(ctx, models.AuditQuery{SwiftCode: "AAAAPLPWXXX", From: start.Add(time.Second)})
+			if err != nil || len(found) != 1 {
+				t.Fatalf("Find = %v, %v", found, err)
+			}
+			e := found[0]
+			if !e.Timestamp.Equal(start.Add(2*time.Minute)) || e.Actor != "bob" {
+				t.Errorf("entry = %+v", e)
+			}
+			if e.Before == nil || e.Before.BankName != "Bank A" || e.After == nil || e.After.BankName != "Bank A2" || e.After.Version != 2 {
+				t.Errorf("snapshots = %+v, %+v", e.Before, e.After)
+			}
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MemoryAuditRepository implements port.AuditRepository in process memory, entries are lost on restart
+type MemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []models.AuditEntry
+}
+
+// NewMemoryAuditRepository creates empty in-memory audit log
+func NewMemoryAuditRepository() port.AuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+// Append stores an entry after the existing ones
+func (r *MemoryAuditRepository) Append(ctx context.Context, entry models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// Find returns matching entries in the order they were appended
+func (r *MemoryAuditRepository) Find(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []models.AuditEntry
+	for _, e := range r.entries {
+		if q.Limit > 0 && len(found) == q.Limit {
+			break
+		}
+		if q.SwiftCode != "" && e.SwiftCode != q.SwiftCode ||
+			!q.From.IsZero() && e.Timestamp.Before(q.From) ||
+			!q.To.IsZero() && e.Timestamp.After(q.To) {
+			continue
+		}
+		found = append(found, e)
+	}
+	return found, nil
+}
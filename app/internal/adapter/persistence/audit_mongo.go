@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MongoAuditRepository implements port.AuditRepository for MongoDB, one document per entry
+type MongoAuditRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoAuditRepository creates connection with MongoDB and inits the audit collection
+func NewMongoAuditRepository(uri, dbName, collName string) (port.AuditRepository, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+	_, err = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "swiftCode", Value: 1}, {Key: "timestamp", Value: 1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoAuditRepository{client: client, collection: coll}, nil
+}
+
+// Append inserts an entry, Mongo keeps timestamps in milliseconds
+func (r *MongoAuditRepository) Append(ctx context.Context, entry models.AuditEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// Find returns matching entries sorted by timestamp, entries of the same millisecond in insertion order
+func (r *MongoAuditRepository) Find(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	filter := bson.M{}
+	if q.SwiftCode != "" {
+		filter["swiftCode"] = q.SwiftCode
+	}
+	timestamp := bson.M{}
+	if !q.From.IsZero() {
+		timestamp["$gte"] = q.From
+	}
+	if !q.To.IsZero() {
+		timestamp["$lte"] = q.To
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var entries []models.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Close closes MongoDB connection
+func (r *MongoAuditRepository) Close(ctx context.Context) error {
+	return r.client.Disconnect(ctx)
+}
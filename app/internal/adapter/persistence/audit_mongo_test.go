@@ -0,0 +1,23 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+const testAuditCollection = "test_audit"
+
+// openMongoAuditRepository connects to an empty audit collection: skips the test if Mongo isn't running
+func openMongoAuditRepository(t *testing.T) port.AuditRepository {
+	log, err := NewMongoAuditRepository(testURI, testDB, testAuditCollection)
+	if err != nil {
+		t.Skipf("skipping Mongo tests; cannot connect: %v", err)
+	}
+	repo := log.(*MongoAuditRepository)
+	t.Cleanup(func() { repo.Close(context.Background()) })
+	// clean slate
+	repo.collection.Drop(context.Background())
+	return repo
+}
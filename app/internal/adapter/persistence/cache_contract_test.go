@@ -0,0 +1,65 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestSwiftCodeCache_Contract(t *testing.T) {
+	runAdapters(t, map[string]func(t *testing.T) port.SwiftCodeCache{
+		"lru":   func(*testing.T) port.SwiftCodeCache { return NewLRUCache(100, time.Minute) },
+		"redis": openRedisCache,
+	}, func(t *testing.T, cache port.SwiftCodeCache) {
+		ctx := context.Background()
+		hq := models.CachedLookup{Found: true, Code: models.SwiftCode{
+			SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", CountryISO2: "PL", IsHeadquarter: true, Version: 3,
+			Branches: []models.SwiftBranch{{SwiftCode: "AAAAPLPW001", BankName: "Branch A1"}},
+		}}
+		branch := models.CachedLookup{Found: true, Code: models.SwiftCode{SwiftCode: "AAAAPLPW001", BankName: "Branch A1", Version: 3}}
+		other := models.CachedLookup{Found: false}
+
+		if _, ok, err := cache.Get(ctx, "AAAAPLPWXXX"); ok || err != nil {
+			t.Fatalf("Get on empty cache = %v, %v; want miss", ok, err)
+		}
+		for code, lookup := range map[string]models.CachedLookup{"AAAAPLPWXXX": hq, "AAAAPLPW001": branch, "BBBBDEFFXXX": other} {
+			if err := cache.Set(ctx, code, lookup); err != nil {
+				t.Fatalf("Set(%s) failed: %v", code, err)
+			}
+		}
+
+		got, ok, err := cache.Get(ctx, "aaaaplpwxxx")
+		if err != nil || !ok {
+			t.Fatalf("Get HQ = %v, %v; want hit", ok, err)
+		}
+		if !got.Found || got.Code.Version != 3 || len(got.Code.Branches) != 1 || got.Code.Branches[0].SwiftCode != "AAAAPLPW001" {
+			t.Errorf("Get HQ = %+v; want %+v", got, hq)
+		}
+		if got, ok, _ := cache.Get(ctx, "BBBBDEFFXXX"); !ok || got.Found {
+			t.Errorf("Get not found code = %+v, %v; want cached miss", got, ok)
+		}
+
+		// the HQ family goes together, other families stay
+		if err := cache.Invalidate(ctx, "AAAAPLPW"); err != nil {
+			t.Fatalf("Invalidate failed: %v", err)
+		}
+		for _, code := range []string{"AAAAPLPWXXX", "AAAAPLPW001"} {
+			if _, ok, _ := cache.Get(ctx, code); ok {
+				t.Errorf("Get(%s) after Invalidate hit; want miss", code)
+			}
+		}
+		if _, ok, _ := cache.Get(ctx, "BBBBDEFFXXX"); !ok {
+			t.Error("Get(BBBBDEFFXXX) after invalidating another family missed; want hit")
+		}
+
+		if err := cache.Clear(ctx); err != nil {
+			t.Fatalf("Clear failed: %v", err)
+		}
+		if _, ok, _ := cache.Get(ctx, "BBBBDEFFXXX"); ok {
+			t.Error("Get after Clear hit; want miss")
+		}
+	})
+}
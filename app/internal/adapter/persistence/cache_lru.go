@@ -0,0 +1,151 @@
+package persistence
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// LRUCache implements port.SwiftCodeCache in process memory, least recently used lookups go first when it is full.
+// Every replica has its own, so changes made through another replica show after the TTL only.
+type LRUCache struct {
+	mu        sync.Mutex
+	capacity  int
+	ttl       time.Duration
+	order     *list.List // front is the most recently used *lruEntry
+	byCode    map[string]*list.Element
+	byFamily  map[string]map[string]*list.Element
+	evictions uint64
+	now       func() time.Time
+}
+
+// lruEntry is a cached lookup and when it expires
+type lruEntry struct {
+	code    string
+	lookup  models.CachedLookup
+	expires time.Time
+}
+
+// NewLRUCache creates empty cache holding up to capacity lookups for ttl each
+func NewLRUCache(capacity int, ttl time.Duration) port.SwiftCodeCache {
+	return &LRUCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		byCode:   map[string]*list.Element{},
+		byFamily: map[string]map[string]*list.Element{},
+		now:      time.Now,
+	}
+}
+
+// Get returns an unexpired lookup and marks it as recently used
+func (c *LRUCache) Get(ctx context.Context, code string) (models.CachedLookup, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.byCode[strings.ToUpper(code)]
+	if !ok {
+		return models.CachedLookup{}, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		return models.CachedLookup{}, false, nil
+	}
+	c.order.MoveToFront(el)
+	return copyLookup(e.lookup), true, nil
+}
+
+// Set stores the lookup, evicting the least recently used one when full
+func (c *LRUCache) Set(ctx context.Context, code string, lookup models.CachedLookup) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	code = strings.ToUpper(code)
+	e := &lruEntry{code: code, lookup: copyLookup(lookup), expires: c.now().Add(c.ttl)}
+	if el, ok := c.byCode[code]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return nil
+	}
+	for c.order.Len() >= c.capacity && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+	el := c.order.PushFront(e)
+	c.byCode[code] = el
+	family := cacheFamily(code)
+	if c.byFamily[family] == nil {
+		c.byFamily[family] = map[string]*list.Element{}
+	}
+	c.byFamily[family][code] = el
+	return nil
+}
+
+// Invalidate drops the lookups of the families
+func (c *LRUCache) Invalidate(ctx context.Context, families ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, family := range families {
+		for _, el := range c.byFamily[strings.ToUpper(family)] {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+// Clear drops every lookup
+func (c *LRUCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.byCode = map[string]*list.Element{}
+	c.byFamily = map[string]map[string]*list.Element{}
+	return nil
+}
+
+// Len returns how many lookups are cached, expired ones included until they are used or evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Evictions returns how many lookups were dropped to make room
+func (c *LRUCache) Evictions() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.evictions
+}
+
+func (c *LRUCache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*lruEntry)
+	delete(c.byCode, e.code)
+	family := cacheFamily(e.code)
+	delete(c.byFamily[family], e.code)
+	if len(c.byFamily[family]) == 0 {
+		delete(c.byFamily, family)
+	}
+}
+
+// cacheFamily returns the HQ family of a code, its first 8 characters
+func cacheFamily(code string) string {
+	code = strings.ToUpper(code)
+	if len(code) > 8 {
+		return code[:8]
+	}
+	return code
+}
+
+// copyLookup copies the branches so callers can't change what is cached
+func copyLookup(l models.CachedLookup) models.CachedLookup {
+	if l.Code.Branches != nil {
+		l.Code.Branches = append([]models.SwiftBranch(nil), l.Code.Branches...)
+	}
+	return l
+}
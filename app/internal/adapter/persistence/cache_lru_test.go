@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2, time.Minute).(*LRUCache)
+	ctx := context.Background()
+	found := models.CachedLookup{Found: true}
+
+	cache.Set(ctx, "AAAAPLPWXXX", found)
+	cache.Set(ctx, "BBBBDEFFXXX", found)
+	cache.Get(ctx, "AAAAPLPWXXX")
+	cache.Set(ctx, "CCCCGB2LXXX", found)
+
+	if _, ok, _ := cache.Get(ctx, "BBBBDEFFXXX"); ok {
+		t.Error("least recently used BBBBDEFFXXX kept; want evicted")
+	}
+	if _, ok, _ := cache.Get(ctx, "AAAAPLPWXXX"); !ok {
+		t.Error("recently used AAAAPLPWXXX evicted; want kept")
+	}
+	if cache.Len() != 2 || cache.Evictions() != 1 {
+		t.Errorf("Len = %d, Evictions = %d; want 2 and 1", cache.Len(), cache.Evictions())
+	}
+	// evicted entries leave their family index too
+	if _, ok := cache.byFamily["BBBBDEFF"]; ok {
+		t.Error("family of evicted entry kept")
+	}
+}
+
+func TestLRUCache_Expires(t *testing.T) {
+	cache := NewLRUCache(10, time.Minute).(*LRUCache)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	cache.Set(ctx, "AAAAPLPWXXX", models.CachedLookup{Found: true})
+	now = now.Add(59 * time.Second)
+	if _, ok, _ := cache.Get(ctx, "AAAAPLPWXXX"); !ok {
+		t.Error("Get before TTL missed; want hit")
+	}
+	now = now.Add(time.Second)
+	if _, ok, _ := cache.Get(ctx, "AAAAPLPWXXX"); ok {
+		t.Error("Get at TTL hit; want miss")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len = %d; want expired entry removed", cache.Len())
+	}
+}
+
+func TestLRUCache_CopiesBranches(t *testing.T) {
+	cache := NewLRUCache(10, time.Minute)
+	ctx := context.Background()
+	lookup := models.CachedLookup{Found: true, Code: models.SwiftCode{Branches: []models.SwiftBranch{{SwiftCode: "AAAAPLPW001"}}}}
+	cache.Set(ctx, "AAAAPLPWXXX", lookup)
+	lookup.Code.Branches[0].SwiftCode = "changed"
+
+	got, _, _ := cache.Get(ctx, "AAAAPLPWXXX")
+	got.Code.Branches[0].BankName = "changed too"
+	again, _, _ := cache.Get(ctx, "AAAAPLPWXXX")
+	if again.Code.Branches[0].SwiftCode != "AAAAPLPW001" || again.Code.Branches[0].BankName != "" {
+		t.Errorf("cached branches = %+v; want unchanged by callers", again.Code.Branches)
+	}
+}
@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// redisCachePrefix starts the keys of the cache, one hash per HQ family with a field per code
+const redisCachePrefix = "swiftcache:"
+
+// RedisCache implements port.SwiftCodeCache on Redis or a compatible server shared by all replicas,
+// so a change made through any of them drops the cached lookups for all
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisCache connects to the redis:// URL, lookups expire ttl after their family was last cached
+func NewRedisCache(url string, ttl time.Duration) (port.SwiftCodeCache, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisCache{client: client, ttl: ttl}, nil
+}
+
+// Get reads the field of the code from the hash of its family
+func (c *RedisCache) Get(ctx context.Context, code string) (models.CachedLookup, bool, error) {
+	code = strings.ToUpper(code)
+	data, err := c.client.HGet(ctx, redisCacheKey(code), code).Bytes()
+	if err == redis.Nil {
+		return models.CachedLookup{}, false, nil
+	}
+	if err != nil {
+		return models.CachedLookup{}, false, err
+	}
+	var lookup models.CachedLookup
+	if err := json.Unmarshal(data, &lookup); err != nil {
+		return models.CachedLookup{}, false, err
+	}
+	return lookup, true, nil
+}
+
+// Set writes the field of the code and renews the expiry of its family
+func (c *RedisCache) Set(ctx context.Context, code string, lookup models.CachedLookup) error {
+	code = strings.ToUpper(code)
+	data, err := json.Marshal(lookup)
+	if err != nil {
+		return err
+	}
+	key := redisCacheKey(code)
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, code, data)
+		pipe.PExpire(ctx, key, c.ttl)
+		return nil
+	})
+	return err
+}
+
+// Invalidate deletes the hashes of the families
+func (c *RedisCache) Invalidate(ctx context.Context, families ...string) error {
+	const batch = 500
+	for len(families) > 0 {
+		n := min(batch, len(families))
+		keys := make([]string, n)
+		for i, family := range families[:n] {
+			keys[i] = redisCacheKey(family)
+		}
+		if err := c.client.Del(ctx, keys...).Err(); err != nil {
+			return err
+		}
+		families = families[n:]
+	}
+	return nil
+}
+
+// Clear deletes every hash of the cache, leaving other keys of the server alone
+func (c *RedisCache) Clear(ctx context.Context) error {
+	iter := c.client.Scan(ctx, 0, redisCachePrefix+"*", 500).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 500 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return c.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+// Close closes the connections to Redis
+func (c *RedisCache) Close(ctx context.Context) error {
+	return c.client.Close()
+}
+
+func redisCacheKey(code string) string {
+	return redisCachePrefix + cacheFamily(code)
+}
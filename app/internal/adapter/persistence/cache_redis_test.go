@@ -0,0 +1,27 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+const testRedisURL = "redis://localhost:6379/15"
+
+// openRedisCache connects to an empty Redis database: skips the test if Redis isn't running
+func openRedisCache(t *testing.T) port.SwiftCodeCache {
+	cache, err := NewRedisCache(testRedisURL, time.Minute)
+	if err != nil {
+		t.Skipf("skipping Redis tests; cannot connect: %v", err)
+	}
+	c := cache.(*RedisCache)
+	ctx := context.Background()
+	t.Cleanup(func() { c.Close(ctx) })
+	// clean slate
+	if err := c.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
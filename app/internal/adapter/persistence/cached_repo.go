@@ -0,0 +1,234 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// CachedRepository implements port.SwiftRepository by caching GetByCode and GetByCodes of another repository,
+// codes that weren't found included. Every change drops the lookups of the HQ families it touches
+// and a purge drops them all. The other reads go straight to the repository.
+// A failing cache is logged and counted, the repository answers instead.
+type CachedRepository struct {
+	port.SwiftRepository
+	cache port.SwiftCodeCache
+
+	// epoch changes with every invalidation, a lookup that raced with one isn't cached
+	epoch         atomic.Uint64
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+	errors        atomic.Uint64
+}
+
+// NewCachedRepository wraps repo with cache
+func NewCachedRepository(repo port.SwiftRepository, cache port.SwiftCodeCache) *CachedRepository {
+	return &CachedRepository{SwiftRepository: repo, cache: cache}
+}
+
+// GetByCode answers from the cache, or looks the code up and caches the result
+func (r *CachedRepository) GetByCode(ctx context.Context, code string) (models.SwiftCode, error) {
+	lookup, ok, err := r.cache.Get(ctx, code)
+	if err != nil {
+		r.cacheFailed("read", err)
+	}
+	if ok {
+		r.hits.Add(1)
+		if !lookup.Found {
+			return models.SwiftCode{}, port.ErrNotFound
+		}
+		return lookup.Code, nil
+	}
+	r.misses.Add(1)
+
+	epoch := r.epoch.Load()
+	sc, err := r.SwiftRepository.GetByCode(ctx, code)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return sc, err
+	}
+	if r.epoch.Load() == epoch {
+		if err := r.cache.Set(ctx, code, models.CachedLookup{Found: err == nil, Code: sc}); err != nil {
+			r.cacheFailed("write", err)
+		}
+	}
+	return sc, err
+}
+
+// GetByCodes answers what it can from the cache and looks the rest up with a single batch
+func (r *CachedRepository) GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error) {
+	found := make(map[string]models.SwiftCode, len(codes))
+	var missed []string
+	for _, code := range codes {
+		lookup, ok, err := r.cache.Get(ctx, code)
+		if err != nil {
+			r.cacheFailed("read", err)
+		}
+		if !ok {
+			r.misses.Add(1)
+			missed = append(missed, code)
+			continue
+		}
+		r.hits.Add(1)
+		if lookup.Found {
+			found[code] = lookup.Code
+		}
+	}
+	if len(missed) == 0 {
+		return found, nil
+	}
+
+	epoch := r.epoch.Load()
+	fetched, err := r.SwiftRepository.GetByCodes(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+	cache := r.epoch.Load() == epoch
+	for _, code := range missed {
+		sc, ok := fetched[code]
+		if ok {
+			found[code] = sc
+		}
+		if !cache {
+			continue
+		}
+		if err := r.cache.Set(ctx, code, models.CachedLookup{Found: ok, Code: sc}); err != nil {
+			r.cacheFailed("write", err)
+			// one failure is enough, the cache is likely down
+			cache = false
+		}
+	}
+	return found, nil
+}
+
+// SaveHeadquarters saves and drops the families of the HQs
+func (r *CachedRepository) SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
+	defer r.invalidateCodes(ctx, hqs)
+	return r.SwiftRepository.SaveHeadquarters(ctx, hqs)
+}
+
+// SaveBranches saves and drops the families of the branches
+func (r *CachedRepository) SaveBranches(ctx context.Context, branches []models.SwiftCode) (models.ImportSummary, error) {
+	defer r.invalidateCodes(ctx, branches)
+	return r.SwiftRepository.SaveBranches(ctx, branches)
+}
+
+// AddBranch adds and drops the family of the HQ
+func (r *CachedRepository) AddBranch(ctx context.Context, hqCode string, branch models.SwiftBranch) error {
+	defer r.invalidate(ctx, cacheFamily(hqCode), cacheFamily(branch.SwiftCode))
+	return r.SwiftRepository.AddBranch(ctx, hqCode, branch)
+}
+
+// Update updates and drops the family, branches show the country and version of their HQ
+func (r *CachedRepository) Update(ctx context.Context, sc models.SwiftCode) error {
+	defer r.invalidate(ctx, cacheFamily(sc.SwiftCode))
+	return r.SwiftRepository.Update(ctx, sc)
+}
+
+// Delete deletes and drops the family
+func (r *CachedRepository) Delete(ctx context.Context, code string, version int64, actor string) error {
+	defer r.invalidate(ctx, cacheFamily(code))
+	return r.SwiftRepository.Delete(ctx, code, version, actor)
+}
+
+// Restore restores and drops the family
+func (r *CachedRepository) Restore(ctx context.Context, code string) error {
+	defer r.invalidate(ctx, cacheFamily(code))
+	return r.SwiftRepository.Restore(ctx, code)
+}
+
+// Purge purges and drops every lookup, the purged codes aren't known
+func (r *CachedRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.SwiftRepository.Purge(ctx, before)
+	if n > 0 {
+		r.clear(ctx)
+	}
+	return n, err
+}
+
+// Batch lets the wrapped repository batch the changes, each change still drops its lookups.
+// A failed batch is undone, so every lookup is dropped, the undone codes aren't known.
+func (r *CachedRepository) Batch(ctx context.Context, fn func(ctx context.Context) error) error {
+	b, ok := r.SwiftRepository.(port.Batcher)
+	if !ok {
+		return fn(ctx)
+	}
+	err := b.Batch(ctx, fn)
+	if err != nil {
+		r.clear(ctx)
+	}
+	return err
+}
+
+// Stats returns the counters of the cache
+func (r *CachedRepository) Stats() models.CacheStats {
+	stats := models.CacheStats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Invalidations: r.invalidations.Load(),
+		Errors:        r.errors.Load(),
+	}
+	if lru, ok := r.cache.(*LRUCache); ok {
+		stats.Entries = lru.Len()
+		stats.Evictions = lru.Evictions()
+	}
+	return stats
+}
+
+// Close closes the cache and the repository
+func (r *CachedRepository) Close(ctx context.Context) error {
+	var errs []error
+	if closer, ok := r.cache.(interface{ Close(context.Context) error }); ok {
+		errs = append(errs, closer.Close(ctx))
+	}
+	if closer, ok := r.SwiftRepository.(interface{ Close(context.Context) error }); ok {
+		errs = append(errs, closer.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// invalidateCodes drops the families of saved codes, once each
+func (r *CachedRepository) invalidateCodes(ctx context.Context, codes []models.SwiftCode) {
+	seen := make(map[string]bool, len(codes))
+	families := make([]string, 0, len(codes))
+	for _, sc := range codes {
+		if f := cacheFamily(sc.SwiftCode); !seen[f] {
+			seen[f] = true
+			families = append(families, f)
+		}
+	}
+	r.invalidate(ctx, families...)
+}
+
+// invalidate drops the families even when the change failed, it may have been applied in part
+func (r *CachedRepository) invalidate(ctx context.Context, families ...string) {
+	if len(families) == 0 {
+		return
+	}
+	r.epoch.Add(1)
+	r.invalidations.Add(1)
+	// a cancelled request must not leave stale lookups behind
+	ctx = context.WithoutCancel(ctx)
+	if err := r.cache.Invalidate(ctx, families...); err != nil {
+		r.cacheFailed("invalidate", err)
+	}
+}
+
+// clear drops every lookup
+func (r *CachedRepository) clear(ctx context.Context) {
+	r.epoch.Add(1)
+	r.invalidations.Add(1)
+	if err := r.cache.Clear(context.WithoutCancel(ctx)); err != nil {
+		r.cacheFailed("clear", err)
+	}
+}
+
+func (r *CachedRepository) cacheFailed(op string, err error) {
+	r.errors.Add(1)
+	log.Printf("swift code cache %s failed: %v", op, err)
+}
@@ -0,0 +1,122 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestCachedRepository_Contract(t *testing.T) {
+	runRepositoryContract(t, func(t *testing.T) port.SwiftRepository {
+		return NewCachedRepository(NewMemoryRepository(), NewLRUCache(100, time.Minute))
+	})
+}
+
+func TestCachedRepository_ReadThrough(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCachedRepository(NewMemoryRepository(), NewLRUCache(100, time.Minute))
+	hq := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", Address: "Addr A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.GetByCode(ctx, "AAAAPLPWXXX"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.GetByCode(ctx, "AAAAPLPW001"); !errors.Is(err, port.ErrNotFound) {
+		t.Fatalf("GetByCode missing branch err = %v; want ErrNotFound", err)
+	}
+	if _, err := repo.GetByCode(ctx, "AAAAPLPW001"); !errors.Is(err, port.ErrNotFound) {
+		t.Fatalf("GetByCode cached missing branch err = %v; want ErrNotFound", err)
+	}
+	if s := repo.Stats(); s.Hits != 3 || s.Misses != 2 || s.Entries != 2 {
+		t.Errorf("Stats = %+v; want 3 hits, 2 misses and 2 entries", s)
+	}
+
+	// adding a branch drops the HQ with its old branch list and the cached miss of the branch
+	branch := models.SwiftBranch{SwiftCode: "AAAAPLPW001", BankName: "Branch A1", Address: "Addr A1", CountryISO2: "PL"}
+	if err := repo.AddBranch(ctx, "AAAAPLPWXXX", branch); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByCode(ctx, "AAAAPLPWXXX")
+	if err != nil || len(got.Branches) != 1 || got.Version != 2 {
+		t.Errorf("GetByCode HQ after AddBranch = %+v, %v; want version 2 with the branch", got, err)
+	}
+	if _, err := repo.GetByCode(ctx, "AAAAPLPW001"); err != nil {
+		t.Errorf("GetByCode branch after AddBranch err = %v; want found", err)
+	}
+
+	// deleting the HQ hides the branch too
+	if err := repo.Delete(ctx, "AAAAPLPWXXX", 0, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByCode(ctx, "AAAAPLPW001"); !errors.Is(err, port.ErrNotFound) {
+		t.Errorf("GetByCode branch after deleting HQ err = %v; want ErrNotFound", err)
+	}
+	if s := repo.Stats(); s.Invalidations != 3 {
+		t.Errorf("Invalidations = %d; want 3 for save, add and delete", s.Invalidations)
+	}
+}
+
+// failingCache can't reach its server
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, code string) (models.CachedLookup, bool, error) {
+	return models.CachedLookup{}, false, errors.New("cache down")
+}
+func (failingCache) Set(ctx context.Context, code string, lookup models.CachedLookup) error {
+	return errors.New("cache down")
+}
+func (failingCache) Invalidate(ctx context.Context, families ...string) error {
+	return errors.New("cache down")
+}
+func (failingCache) Clear(ctx context.Context) error { return errors.New("cache down") }
+
+func TestCachedRepository_CacheFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCachedRepository(NewMemoryRepository(), failingCache{})
+	hq := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", Address: "Addr A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); err != nil {
+		t.Fatalf("SaveHeadquarters err = %v; want the repository to answer", err)
+	}
+	if _, err := repo.GetByCode(ctx, "AAAAPLPWXXX"); err != nil {
+		t.Fatalf("GetByCode err = %v; want the repository to answer", err)
+	}
+	if s := repo.Stats(); s.Errors != 3 {
+		t.Errorf("Errors = %d; want 3 for invalidate, read and write", s.Errors)
+	}
+}
+
+func TestCachedRepository_GetByCodesFetchesOnlyMisses(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCachedRepository(NewMemoryRepository(), NewLRUCache(100, time.Minute))
+	hq := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", Address: "Addr A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByCode(ctx, hq.SwiftCode); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetByCodes(ctx, []string{hq.SwiftCode, "ZZZZPLPWXXX"})
+	if err != nil || len(got) != 1 || got[hq.SwiftCode].BankName != "Bank A" {
+		t.Fatalf("GetByCodes = %v, %v; want only the HQ", got, err)
+	}
+	if stats := repo.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("stats = %+v; want 1 hit and 2 misses", stats)
+	}
+
+	// the unknown code is cached as missing now
+	if _, err := repo.GetByCodes(ctx, []string{"ZZZZPLPWXXX"}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := repo.Stats(); stats.Hits != 2 {
+		t.Errorf("hits = %d; want 2", stats.Hits)
+	}
+}
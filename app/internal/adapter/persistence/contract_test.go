@@ -0,0 +1,804 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// repoFactory returns an empty repository for a single contract subtest.
+type repoFactory func(t *testing.T) port.SwiftRepository
+
+// runAdapters runs check against each of adapters in a subtest named after it. Open hands out
+// an empty adapter and skips the subtest when its server isn't reachable.
+func runAdapters[T any](t *testing.T, adapters map[string]func(t *testing.T) T, check func(t *testing.T, adapter T)) {
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		open := adapters[name]
+		t.Run(name, func(t *testing.T) { check(t, open(t)) })
+	}
+}
+
+// runRepositoryContract checks the behaviour every port.SwiftRepository adapter must share.
+func runRepositoryContract(t *testing.T, newRepo repoFactory) {
+	ctx := context.Background()
+
+	hq := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", Address: "Addr A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	branch := models.SwiftCode{SwiftCode: "AAAAPLPW001", BankName: "Branch A1", Address: "Addr A1", CountryISO2: "PL", CountryName: "POLAND"}
+
+	t.Run("SaveHeadquarters counts added and skipped", func(t *testing.T) {
+		repo := newRepo(t)
+		other := models.SwiftCode{SwiftCode: "BBBBDEFFXXX", BankName: "Bank B", Address: "Addr B", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true}
+
+		summary, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq, other, hq})
+		if err != nil {
+			t.Fatalf("SaveHeadquarters failed: %v", err)
+		}
+		if summary.HQAdded != 2 || summary.HQSkipped != 1 {
+			t.Errorf("summary = %+v; want HQAdded=2,HQSkipped=1", summary)
+		}
+
+		summary, err = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.HQAdded != 0 || summary.HQSkipped != 1 {
+			t.Errorf("repeated summary = %+v; want HQAdded=0,HQSkipped=1", summary)
+		}
+
+		got, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil {
+			t.Fatalf("GetByCode HQ failed: %v", err)
+		}
+		if got.SwiftCode != hq.SwiftCode || got.BankName != hq.BankName || !got.IsHeadquarter || len(got.Branches) != 0 {
+			t.Errorf("GetByCode HQ = %+v; want %+v", got, hq)
+		}
+	})
+
+	t.Run("SaveBranches counts added, duplicate and missing HQ", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); err != nil {
+			t.Fatal(err)
+		}
+		orphan := models.SwiftCode{SwiftCode: "ZZZZPLPW001", BankName: "Orphan", Address: "Addr Z", CountryISO2: "PL", CountryName: "POLAND"}
+
+		summary, err := repo.SaveBranches(ctx, []models.SwiftCode{branch, orphan, branch})
+		if err != nil {
+			t.Fatalf("SaveBranches failed: %v", err)
+		}
+		want := models.ImportSummary{BranchesAdded: 1, BranchesDuplicate: 1, BranchesMissingHQ: 1}
+		if summary != want {
+			t.Errorf("summary = %+v; want %+v", summary, want)
+		}
+	})
+
+	t.Run("SaveBranches keeps the stored branch when re-imported with changed details", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch})
+
+		changed := branch
+		changed.BankName = "Renamed Branch"
+		changed.TownName = "KRAKOW"
+		summary, err := repo.SaveBranches(ctx, []models.SwiftCode{changed})
+		if err != nil {
+			t.Fatalf("SaveBranches failed: %v", err)
+		}
+		if want := (models.ImportSummary{BranchesDuplicate: 1}); summary != want {
+			t.Errorf("summary = %+v; want %+v", summary, want)
+		}
+		got, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Branches) != 1 || got.Branches[0].BankName != branch.BankName || got.Branches[0].TownName != branch.TownName {
+			t.Errorf("branches after re-import = %+v; want the stored one only", got.Branches)
+		}
+	})
+
+	t.Run("GetByCode returns branch with HQ country", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch})
+
+		got, err := repo.GetByCode(ctx, branch.SwiftCode)
+		if err != nil {
+			t.Fatalf("GetByCode branch failed: %v", err)
+		}
+		want := models.SwiftCode{
+			SwiftCode:   branch.SwiftCode,
+			BankName:    branch.BankName,
+			Address:     branch.Address,
+			CountryISO2: hq.CountryISO2,
+			CountryName: hq.CountryName,
+		}
+		if got.SwiftCode != want.SwiftCode || got.BankName != want.BankName || got.Address != want.Address ||
+			got.CountryISO2 != want.CountryISO2 || got.CountryName != want.CountryName || got.IsHeadquarter || len(got.Branches) != 0 {
+			t.Errorf("GetByCode branch = %+v; want %+v", got, want)
+		}
+
+		hqDoc, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hqDoc.Branches) != 1 || hqDoc.Branches[0].SwiftCode != branch.SwiftCode {
+			t.Errorf("HQ branches = %+v; want [%s]", hqDoc.Branches, branch.SwiftCode)
+		}
+
+		if _, err := repo.GetByCode(ctx, "NONEPLPWXXX"); err != port.ErrNotFound {
+			t.Errorf("GetByCode unknown error = %v; want ErrNotFound", err)
+		}
+	})
+
+	t.Run("town, code type and time zone are persisted", func(t *testing.T) {
+		repo := newRepo(t)
+		located := hq
+		located.TownName, located.CodeType, located.TimeZone = "WARSZAWA", "BIC11", "Europe/Warsaw"
+		locatedBranch := branch
+		locatedBranch.TownName, locatedBranch.CodeType, locatedBranch.TimeZone = "KRAKOW", "BIC11", "Europe/Warsaw"
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{located})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{locatedBranch})
+
+		same := func(sc models.SwiftCode, want models.SwiftCode) bool {
+			return sc.TownName == want.TownName && sc.CodeType == want.CodeType && sc.TimeZone == want.TimeZone
+		}
+		gotHQ, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !same(gotHQ, located) || len(gotHQ.Branches) != 1 || gotHQ.Branches[0].TownName != "KRAKOW" ||
+			gotHQ.Branches[0].CodeType != "BIC11" || gotHQ.Branches[0].TimeZone != "Europe/Warsaw" {
+			t.Errorf("GetByCode HQ = %+v", gotHQ)
+		}
+		gotBranch, err := repo.GetByCode(ctx, branch.SwiftCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !same(gotBranch, locatedBranch) {
+			t.Errorf("GetByCode branch = %+v", gotBranch)
+		}
+		list, _ := repo.List(ctx, models.SwiftCodeQuery{Town: "krakow"})
+		if len(list) != 1 || !same(list[0], locatedBranch) {
+			t.Errorf("List by town = %+v", list)
+		}
+	})
+
+	t.Run("List by country flattens HQs and branches", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch})
+
+		all, err := repo.List(ctx, models.SwiftCodeQuery{CountryISO2: "PL"})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(all) != 2 {
+			t.Fatalf("List returned %d items; want 2", len(all))
+		}
+		for _, sc := range all {
+			if sc.CountryName != hq.CountryName {
+				t.Errorf("entry %s has country name %q; want %q", sc.SwiftCode, sc.CountryName, hq.CountryName)
+			}
+		}
+
+		if all, err := repo.List(ctx, models.SwiftCodeQuery{CountryISO2: "DE"}); err != nil || len(all) != 0 {
+			t.Errorf("List of empty country = %v, %v; want nothing", all, err)
+		}
+	})
+
+	t.Run("AddBranch requires HQ and rejects duplicates", func(t *testing.T) {
+		repo := newRepo(t)
+		br := models.SwiftBranch{SwiftCode: branch.SwiftCode, BankName: branch.BankName, Address: branch.Address, CountryISO2: "PL", CountryName: "POLAND"}
+
+		if err := repo.AddBranch(ctx, hq.SwiftCode, br); err != port.ErrHQNotFound {
+			t.Errorf("AddBranch without HQ error = %v; want ErrHQNotFound", err)
+		}
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		if err := repo.AddBranch(ctx, hq.SwiftCode, br); err != nil {
+			t.Fatalf("AddBranch failed: %v", err)
+		}
+		if err := repo.AddBranch(ctx, hq.SwiftCode, br); err != port.ErrBranchDuplicate {
+			t.Errorf("AddBranch duplicate error = %v; want ErrBranchDuplicate", err)
+		}
+		renamed := br
+		renamed.BankName = "Renamed Branch"
+		if err := repo.AddBranch(ctx, hq.SwiftCode, renamed); err != port.ErrBranchDuplicate {
+			t.Errorf("AddBranch duplicate code error = %v; want ErrBranchDuplicate", err)
+		}
+		if _, err := repo.GetByCode(ctx, br.SwiftCode); err != nil {
+			t.Errorf("GetByCode added branch failed: %v", err)
+		}
+	})
+
+	t.Run("Update replaces HQ and branch details", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch})
+
+		newHQ := hq
+		newHQ.BankName, newHQ.Address, newHQ.TownName, newHQ.TimeZone = "Bank A New", "New Addr", "WARSZAWA", "Europe/Warsaw"
+		if err := repo.Update(ctx, newHQ); err != nil {
+			t.Fatalf("Update HQ failed: %v", err)
+		}
+		newBranch := branch
+		newBranch.BankName, newBranch.CodeType = "Branch A1 New", "BIC11"
+		if err := repo.Update(ctx, newBranch); err != nil {
+			t.Fatalf("Update branch failed: %v", err)
+		}
+
+		got, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.BankName != newHQ.BankName || got.Address != newHQ.Address || got.TownName != newHQ.TownName ||
+			got.TimeZone != newHQ.TimeZone || len(got.Branches) != 1 {
+			t.Errorf("updated HQ = %+v; want %+v with its branch", got, newHQ)
+		}
+		got, err = repo.GetByCode(ctx, branch.SwiftCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.BankName != newBranch.BankName || got.CodeType != newBranch.CodeType || got.Address != branch.Address {
+			t.Errorf("updated branch = %+v; want %+v", got, newBranch)
+		}
+
+		missing := models.SwiftCode{SwiftCode: "ZZZZPLPW001", BankName: "Missing"}
+		if err := repo.Update(ctx, missing); err != port.ErrNotFound {
+			t.Errorf("Update missing branch error = %v; want ErrNotFound", err)
+		}
+		missing.SwiftCode = "ZZZZPLPWXXX"
+		if err := repo.Update(ctx, missing); err != port.ErrNotFound {
+			t.Errorf("Update missing HQ error = %v; want ErrNotFound", err)
+		}
+	})
+
+	t.Run("versions increase and guard Update and Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		version := func(code string) int64 {
+			t.Helper()
+			got, err := repo.GetByCode(ctx, code)
+			if err != nil {
+				t.Fatalf("GetByCode %s failed: %v", code, err)
+			}
+			return got.Version
+		}
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		if v := version(hq.SwiftCode); v != 1 {
+			t.Fatalf("new HQ version = %d; want 1", v)
+		}
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch})
+		if v, bv := version(hq.SwiftCode), version(branch.SwiftCode); v != 2 || bv != 2 {
+			t.Fatalf("versions after adding a branch twice = %d, branch %d; want 2", v, bv)
+		}
+		second := models.SwiftBranch{SwiftCode: "AAAAPLPW002", BankName: "Branch A2", CountryISO2: "PL"}
+		if err := repo.AddBranch(ctx, hq.SwiftCode, second); err != nil {
+			t.Fatal(err)
+		}
+		if v := version(hq.SwiftCode); v != 3 {
+			t.Fatalf("version after AddBranch = %d; want 3", v)
+		}
+
+		stale := hq
+		stale.Version = 2
+		if err := repo.Update(ctx, stale); err != port.ErrVersionMismatch {
+			t.Errorf("Update with stale version error = %v; want ErrVersionMismatch", err)
+		}
+		current := hq
+		current.Version = 3
+		if err := repo.Update(ctx, current); err != nil {
+			t.Fatalf("Update with current version failed: %v", err)
+		}
+		updated := branch
+		updated.Version = 4
+		if err := repo.Update(ctx, updated); err != nil {
+			t.Fatalf("Update branch with current version failed: %v", err)
+		}
+		if v := version(hq.SwiftCode); v != 5 {
+			t.Fatalf("version after two updates = %d; want 5", v)
+		}
+
+		if err := repo.Delete(ctx, branch.SwiftCode, 4, "tester"); err != port.ErrVersionMismatch {
+			t.Errorf("Delete branch with stale version error = %v; want ErrVersionMismatch", err)
+		}
+		if err := repo.Delete(ctx, branch.SwiftCode, 5, "tester"); err != nil {
+			t.Fatalf("Delete branch with current version failed: %v", err)
+		}
+		if err := repo.Delete(ctx, hq.SwiftCode, 5, "tester"); err != port.ErrVersionMismatch {
+			t.Errorf("Delete HQ with stale version error = %v; want ErrVersionMismatch", err)
+		}
+		if err := repo.Delete(ctx, hq.SwiftCode, 6, "tester"); err != nil {
+			t.Fatalf("Delete HQ with current version failed: %v", err)
+		}
+		if err := repo.Delete(ctx, hq.SwiftCode, 6, "tester"); err != port.ErrNotFound {
+			t.Errorf("Delete deleted HQ error = %v; want ErrNotFound", err)
+		}
+		if err := repo.Update(ctx, current); err != port.ErrNotFound {
+			t.Errorf("Update deleted HQ error = %v; want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Delete removes branch or cascades HQ", func(t *testing.T) {
+		repo := newRepo(t)
+		second := models.SwiftCode{SwiftCode: "AAAAPLPW002", BankName: "Branch A2", Address: "Addr A2", CountryISO2: "PL", CountryName: "POLAND"}
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch, second})
+
+		if err := repo.Delete(ctx, branch.SwiftCode, 0, "tester"); err != nil {
+			t.Fatalf("Delete branch failed: %v", err)
+		}
+		if _, err := repo.GetByCode(ctx, branch.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("deleted branch lookup error = %v; want ErrNotFound", err)
+		}
+		if err := repo.Delete(ctx, branch.SwiftCode, 0, "tester"); err != port.ErrNotFound {
+			t.Errorf("second branch delete error = %v; want ErrNotFound", err)
+		}
+
+		if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != nil {
+			t.Fatalf("Delete HQ failed: %v", err)
+		}
+		if _, err := repo.GetByCode(ctx, second.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("branch of deleted HQ lookup error = %v; want ErrNotFound", err)
+		}
+		if all, _ := repo.List(ctx, models.SwiftCodeQuery{CountryISO2: "PL"}); len(all) != 0 {
+			t.Errorf("List after HQ delete = %v; want nothing", all)
+		}
+		if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != port.ErrNotFound {
+			t.Errorf("second HQ delete error = %v; want ErrNotFound", err)
+		}
+	})
+
+	t.Run("deleted codes are hidden until restored or purged", func(t *testing.T) {
+		repo := newRepo(t)
+		second := models.SwiftCode{SwiftCode: "AAAAPLPW002", BankName: "Branch A2", Address: "Addr A2", CountryISO2: "PL", CountryName: "POLAND"}
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch, second})
+
+		if err := repo.Delete(ctx, branch.SwiftCode, 0, "tester"); err != nil {
+			t.Fatalf("Delete branch failed: %v", err)
+		}
+		got, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil || len(got.Branches) != 1 || len(got.DeletedBranches) != 0 {
+			t.Fatalf("HQ after branch delete = %+v, %v; want only %s", got, err, second.SwiftCode)
+		}
+
+		if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != nil {
+			t.Fatalf("Delete HQ failed: %v", err)
+		}
+		if list, _ := repo.List(ctx, models.SwiftCodeQuery{}); len(list) != 0 {
+			t.Errorf("List after delete = %+v; want empty", list)
+		}
+		if hits, _ := repo.Search(ctx, "branch", 10); len(hits) != 0 {
+			t.Errorf("Search after delete = %+v; want empty", hits)
+		}
+		if err := repo.Update(ctx, second); err != port.ErrNotFound {
+			t.Errorf("Update branch of deleted HQ error = %v; want ErrNotFound", err)
+		}
+		if summary, _ := repo.SaveBranches(ctx, []models.SwiftCode{second}); summary.BranchesMissingHQ != 1 {
+			t.Errorf("saving branch of deleted HQ summary = %+v; want BranchesMissingHQ=1", summary)
+		}
+		if err := repo.Restore(ctx, branch.SwiftCode); err != port.ErrHQNotFound {
+			t.Errorf("Restore branch of deleted HQ error = %v; want ErrHQNotFound", err)
+		}
+
+		if err := repo.Restore(ctx, hq.SwiftCode); err != nil {
+			t.Fatalf("Restore HQ failed: %v", err)
+		}
+		got, err = repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil || len(got.Branches) != 1 || got.Branches[0].SwiftCode != second.SwiftCode || got.Version != 6 {
+			t.Errorf("restored HQ = %+v, %v; want branch %s only and version 6", got, err, second.SwiftCode)
+		}
+		if err := repo.Restore(ctx, hq.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("Restore of live HQ error = %v; want ErrNotFound", err)
+		}
+		if err := repo.Restore(ctx, branch.SwiftCode); err != nil {
+			t.Fatalf("Restore branch failed: %v", err)
+		}
+		restored, err := repo.GetByCode(ctx, branch.SwiftCode)
+		if err != nil || restored.BankName != branch.BankName || restored.Version != 7 {
+			t.Errorf("restored branch = %+v, %v; want %s at version 7", restored, err, branch.BankName)
+		}
+
+		// only codes deleted before the cutoff are purged
+		if err := repo.Delete(ctx, branch.SwiftCode, 0, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := repo.Purge(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Errorf("Purge of nothing old enough = %d, %v; want 0", n, err)
+		}
+		if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := repo.Purge(ctx, time.Now().Add(time.Minute)); err != nil || n != 2 {
+			t.Errorf("Purge = %d, %v; want the HQ and branch purged", n, err)
+		}
+		if err := repo.Restore(ctx, hq.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("Restore purged HQ error = %v; want ErrNotFound", err)
+		}
+		if summary, _ := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); summary.HQAdded != 1 {
+			t.Errorf("saving purged HQ summary = %+v; want HQAdded=1", summary)
+		}
+	})
+
+	t.Run("saving a deleted code again replaces it", func(t *testing.T) {
+		repo := newRepo(t)
+		second := models.SwiftCode{SwiftCode: "AAAAPLPW002", BankName: "Branch A2", Address: "Addr A2", CountryISO2: "PL", CountryName: "POLAND"}
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{hq})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{branch, second})
+
+		if err := repo.Delete(ctx, branch.SwiftCode, 0, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		renamed := branch
+		renamed.BankName = "Branch A renamed"
+		if summary, err := repo.SaveBranches(ctx, []models.SwiftCode{renamed}); err != nil || summary.BranchesAdded != 1 {
+			t.Fatalf("saving deleted branch again = %+v, %v; want BranchesAdded=1", summary, err)
+		}
+		if got, err := repo.GetByCode(ctx, branch.SwiftCode); err != nil || got.BankName != renamed.BankName {
+			t.Errorf("saved again branch = %+v, %v; want %s", got, err, renamed.BankName)
+		}
+		if err := repo.Restore(ctx, branch.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("Restore of replaced branch error = %v; want ErrNotFound", err)
+		}
+
+		before, _ := repo.GetByCode(ctx, hq.SwiftCode)
+		if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		moved := hq
+		moved.Address = "New Addr"
+		summary, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{moved})
+		if err != nil || summary.HQAdded != 1 || summary.HQSkipped != 0 {
+			t.Fatalf("saving deleted HQ again = %+v, %v; want HQAdded=1", summary, err)
+		}
+		// the branches deleted together with the HQ are gone, the version goes on
+		got, err := repo.GetByCode(ctx, hq.SwiftCode)
+		if err != nil || got.Address != moved.Address || len(got.Branches) != 0 || got.Version <= before.Version+1 {
+			t.Errorf("saved again HQ = %+v, %v; want %s without branches after version %d", got, err, moved.Address, before.Version+1)
+		}
+		if _, err := repo.GetByCode(ctx, second.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("branch of replaced HQ error = %v; want ErrNotFound", err)
+		}
+		if err := repo.Restore(ctx, hq.SwiftCode); err != port.ErrNotFound {
+			t.Errorf("Restore of replaced HQ error = %v; want ErrNotFound", err)
+		}
+		if summary, _ := repo.SaveBranches(ctx, []models.SwiftCode{second}); summary.BranchesAdded != 1 {
+			t.Errorf("saving branch of replaced HQ summary = %+v; want BranchesAdded=1", summary)
+		}
+		if summary, _ := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); summary.HQSkipped != 1 {
+			t.Errorf("saving live HQ again summary = %+v; want HQSkipped=1", summary)
+		}
+	})
+
+	t.Run("List pages and filters flattened codes", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{
+			hq,
+			{SwiftCode: "BBBBDEFFXXX", BankName: "Berliner Bank", Address: "Unter den Linden 1, BERLIN", TownName: "BERLIN", TimeZone: "Europe/Berlin", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+		})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{
+			branch,
+			{SwiftCode: "BBBBDEFF001", BankName: "Berliner Bank Hamburg", Address: "Jungfernstieg 2, HAMBURG", TownName: "HAMBURG", TimeZone: "Europe/Berlin", CountryISO2: "DE", CountryName: "GERMANY"},
+		})
+
+		codes := func(list []models.SwiftCode) []string {
+			out := []string{}
+			for _, sc := range list {
+				out = append(out, sc.SwiftCode)
+			}
+			return out
+		}
+		isHQ, isBranch := true, false
+		cases := []struct {
+			name  string
+			query models.SwiftCodeQuery
+			want  []string
+		}{
+			{"all", models.SwiftCodeQuery{}, []string{"AAAAPLPW001", "AAAAPLPWXXX", "BBBBDEFF001", "BBBBDEFFXXX"}},
+			{"first page", models.SwiftCodeQuery{Limit: 2}, []string{"AAAAPLPW001", "AAAAPLPWXXX"}},
+			{"next page", models.SwiftCodeQuery{After: "AAAAPLPWXXX", Limit: 2}, []string{"BBBBDEFF001", "BBBBDEFFXXX"}},
+			{"past the end", models.SwiftCodeQuery{After: "BBBBDEFFXXX"}, []string{}},
+			{"after a branch", models.SwiftCodeQuery{After: "BBBBDEFF001"}, []string{"BBBBDEFFXXX"}},
+			{"after a short cursor", models.SwiftCodeQuery{After: "AAAAPLPX"}, []string{"BBBBDEFF001", "BBBBDEFFXXX"}},
+			{"country", models.SwiftCodeQuery{CountryISO2: "DE"}, []string{"BBBBDEFF001", "BBBBDEFFXXX"}},
+			{"headquarters", models.SwiftCodeQuery{IsHeadquarter: &isHQ}, []string{"AAAAPLPWXXX", "BBBBDEFFXXX"}},
+			{"bank name prefix", models.SwiftCodeQuery{BankNamePrefix: "berliner bank h"}, []string{"BBBBDEFF001"}},
+			{"town", models.SwiftCodeQuery{Town: "berlin"}, []string{"BBBBDEFFXXX"}},
+			{"town is not a substring match", models.SwiftCodeQuery{Town: "berl"}, []string{}},
+			{"time zone", models.SwiftCodeQuery{TimeZone: "Europe/Berlin"}, []string{"BBBBDEFF001", "BBBBDEFFXXX"}},
+			{"combined", models.SwiftCodeQuery{CountryISO2: "PL", BankNamePrefix: "Branch"}, []string{"AAAAPLPW001"}},
+			{"branches by bank name", models.SwiftCodeQuery{IsHeadquarter: &isBranch, BankNamePrefix: "berliner"}, []string{"BBBBDEFF001"}},
+			{"headquarters by town", models.SwiftCodeQuery{IsHeadquarter: &isHQ, Town: "hamburg"}, []string{}},
+			{"branches in a country", models.SwiftCodeQuery{IsHeadquarter: &isBranch, CountryISO2: "PL"}, []string{"AAAAPLPW001"}},
+		}
+		for _, tc := range cases {
+			got, err := repo.List(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("%s: List failed: %v", tc.name, err)
+			}
+			if g := codes(got); !reflect.DeepEqual(g, tc.want) {
+				t.Errorf("%s: List = %v; want %v", tc.name, g, tc.want)
+			}
+		}
+
+		got, _ := repo.List(ctx, models.SwiftCodeQuery{CountryISO2: "PL"})
+		for _, sc := range got {
+			if sc.CountryName != "POLAND" || len(sc.Branches) != 0 || sc.IsHeadquarter != strings.HasSuffix(sc.SwiftCode, "XXX") {
+				t.Errorf("unexpected list entry %+v", sc)
+			}
+		}
+	})
+
+	t.Run("Walk visits live codes in code order", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{
+			{SwiftCode: "BBBBDEFFXXX", BankName: "Berliner Bank", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+			hq,
+			{SwiftCode: "CCCCDEFFXXX", BankName: "Commerzbank", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+		})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{
+			{SwiftCode: "BBBBDEFFZZZ", BankName: "Berliner Bank Z", CountryISO2: "DE", CountryName: "GERMANY"},
+			branch,
+			{SwiftCode: "BBBBDEFF001", BankName: "Berliner Bank Hamburg", CountryISO2: "DE", CountryName: "GERMANY"},
+			{SwiftCode: "CCCCDEFF001", BankName: "Commerzbank Bonn", CountryISO2: "DE", CountryName: "GERMANY"},
+		})
+		if err := repo.Delete(ctx, "CCCCDEFFXXX", 0, "tester"); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []models.SwiftCode
+		err := repo.Walk(ctx, func(sc models.SwiftCode) error {
+			got = append(got, sc)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Walk failed: %v", err)
+		}
+		want, _ := repo.List(ctx, models.SwiftCodeQuery{})
+		if len(want) != 5 || !reflect.DeepEqual(got, want) {
+			t.Errorf("Walk = %+v; want %+v", got, want)
+		}
+
+		stop := errors.New("stop")
+		n := 0
+		err = repo.Walk(ctx, func(models.SwiftCode) error {
+			n++
+			return stop
+		})
+		if !errors.Is(err, stop) || n != 1 {
+			t.Errorf("Walk after an error = %v after %d codes; want stop after 1", err, n)
+		}
+	})
+
+	t.Run("Search ranks by bank name and address with typos", func(t *testing.T) {
+		repo := newRepo(t)
+		_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{
+			{SwiftCode: "BBBBDEFFXXX", BankName: "Berliner Bank", Address: "Unter den Linden 1, BERLIN", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+			{SwiftCode: "CCCCDEFFXXX", BankName: "Commerzbank", Address: "Kaiserplatz, FRANKFURT", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+		})
+		_, _ = repo.SaveBranches(ctx, []models.SwiftCode{
+			{SwiftCode: "BBBBDEFF001", BankName: "Berliner Bank Hamburg", Address: "Jungfernstieg 2, HAMBURG", CountryISO2: "DE", CountryName: "GERMANY"},
+		})
+
+		cases := []struct {
+			query string
+			want  []string
+		}{
+			{"berliner bank", []string{"BBBBDEFFXXX", "BBBBDEFF001"}},
+			{"berlnier", []string{"BBBBDEFF001", "BBBBDEFFXXX"}},
+			{"hamburg", []string{"BBBBDEFF001"}},
+			{"frankfurt commerz", []string{"CCCCDEFFXXX"}},
+			{"munich", []string{}},
+		}
+		for _, tc := range cases {
+			hits, err := repo.Search(ctx, tc.query, 10)
+			if err != nil {
+				t.Fatalf("%q: Search failed: %v", tc.query, err)
+			}
+			got := []string{}
+			for _, h := range hits {
+				got = append(got, h.SwiftCode)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%q: Search = %v; want %v", tc.query, got, tc.want)
+			}
+		}
+
+		hits, _ := repo.Search(ctx, "bank", 1)
+		if len(hits) != 1 || hits[0].Score <= 0 || hits[0].CountryName != "GERMANY" {
+			t.Errorf("unexpected limited search result %+v", hits)
+		}
+	})
+
+	t.Run("Search finds the best match among more candidates than the cap", func(t *testing.T) {
+		repo := newRepo(t)
+		hqs := make([]models.SwiftCode, 0, searchCandidates+1)
+		for i := 0; i < searchCandidates; i++ {
+			hqs = append(hqs, models.SwiftCode{SwiftCode: fmt.Sprintf("%04dPLPWXXX", i), BankName: "Zepter Bank", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true})
+		}
+		// last in code and insertion order
+		hqs = append(hqs, models.SwiftCode{SwiftCode: "ZZZZPLPWXXX", BankName: "Zephyr Bank", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true})
+		if _, err := repo.SaveHeadquarters(ctx, hqs); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, query := range []string{"zephyr", "zephir"} {
+			hits, err := repo.Search(ctx, query, 1)
+			if err != nil || len(hits) != 1 || hits[0].SwiftCode != "ZZZZPLPWXXX" {
+				t.Errorf("%q: Search = %+v, %v; want ZZZZPLPWXXX", query, hits, err)
+			}
+		}
+	})
+
+	t.Run("GetByCodes matches GetByCode for each code", func(t *testing.T) {
+		repo := newRepo(t)
+		other := models.SwiftCode{SwiftCode: "BBBBDEFFXXX", BankName: "Bank B", Address: "Addr B", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true}
+		deleted := models.SwiftCode{SwiftCode: "AAAAPLPW002", BankName: "Branch A2", Address: "Addr A2", CountryISO2: "PL", CountryName: "POLAND"}
+		if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq, other}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.SaveBranches(ctx, []models.SwiftCode{branch, deleted}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(ctx, deleted.SwiftCode, 0, "tester"); err != nil {
+			t.Fatal(err)
+		}
+
+		codes := []string{hq.SwiftCode, branch.SwiftCode, other.SwiftCode, deleted.SwiftCode, "ZZZZPLPWXXX"}
+		got, err := repo.GetByCodes(ctx, codes)
+		if err != nil {
+			t.Fatalf("GetByCodes failed: %v", err)
+		}
+		if len(got) != 3 {
+			t.Errorf("GetByCodes found %d codes; want 3 without the deleted and unknown ones", len(got))
+		}
+		for _, code := range codes[:3] {
+			want, err := repo.GetByCode(ctx, code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got[code], want) {
+				t.Errorf("GetByCodes[%s] = %+v; want %+v", code, got[code], want)
+			}
+		}
+
+		empty, err := repo.GetByCodes(ctx, nil)
+		if err != nil || len(empty) != 0 {
+			t.Errorf("GetByCodes(nil) = %v, %v; want empty", empty, err)
+		}
+	})
+
+	t.Run("SaveHeadquarters and GetByCode", func(t *testing.T) {
+		testSaveHeadquartersAndGetByCode(t, newRepo(t))
+	})
+
+	t.Run("SaveBranches, List and Delete", func(t *testing.T) {
+		testSaveBranchesAndListAndDelete(t, newRepo(t))
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		if err := newRepo(t).Ping(ctx); err != nil {
+			t.Errorf("Ping failed: %v", err)
+		}
+	})
+}
+
+// testSaveHeadquartersAndGetByCode covers HQ import counters and lookup
+func testSaveHeadquartersAndGetByCode(t *testing.T, repo port.SwiftRepository) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hqs := []models.SwiftCode{
+		{SwiftCode: "AAAAUS33XXX", BankName: "Bank A", Address: "Addr A", CountryISO2: "US", CountryName: "USA", IsHeadquarter: true},
+		{SwiftCode: "BBBBDEFFXXX", BankName: "Bank B", Address: "Addr B", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+	}
+	summary, err := repo.SaveHeadquarters(ctx, hqs)
+	if err != nil {
+		t.Fatalf("SaveHeadquarters failed: %v", err)
+	}
+	if summary.HQAdded != 2 || summary.HQSkipped != 0 {
+		t.Errorf("first import summary = %+v; want HQAdded=2,HQSkipped=0", summary)
+	}
+
+	// repeated import should skip both
+	summary, err = repo.SaveHeadquarters(ctx, hqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.HQAdded != 0 || summary.HQSkipped != 2 {
+		t.Errorf("second import summary = %+v; want HQAdded=0,HQSkipped=2", summary)
+	}
+
+	// fetch one HQ
+	got, err := repo.GetByCode(ctx, "AAAAUS33XXX")
+	if err != nil {
+		t.Fatalf("GetByCode HQ failed: %v", err)
+	}
+	if got.SwiftCode != "AAAAUS33XXX" || !got.IsHeadquarter {
+		t.Errorf("GetByCode HQ = %+v; want code=AAAAUS33XXX,isHQ=true", got)
+	}
+}
+
+// testSaveBranchesAndListAndDelete covers branch import counters, country listing and deletes
+func testSaveBranchesAndListAndDelete(t *testing.T, repo port.SwiftRepository) {
+	ctx := context.Background()
+
+	// First insert a HQ to attach branches to
+	_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{
+		{SwiftCode: "CCCCGB2LXXX", BankName: "Bank C", Address: "Addr C", CountryISO2: "GB", CountryName: "UK", IsHeadquarter: true},
+	})
+
+	// Branch with no HQ
+	branches := []models.SwiftCode{
+		{SwiftCode: "ZZZZGB2LAB1", BankName: "Branch Z", Address: "Addr Z", CountryISO2: "GB", CountryName: "UK", IsHeadquarter: false},
+	}
+	summary, err := repo.SaveBranches(ctx, branches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.BranchesMissingHQ != 1 {
+		t.Errorf("expected BranchesMissingHQ=1; got %+v", summary)
+	}
+
+	// Valid branch
+	branches = []models.SwiftCode{
+		{SwiftCode: "CCCCGB2LAB1", BankName: "Branch C1", Address: "Addr C1", CountryISO2: "GB", CountryName: "UK", IsHeadquarter: false},
+	}
+	summary, err = repo.SaveBranches(ctx, branches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.BranchesAdded != 1 {
+		t.Errorf("expected BranchesAdded=1; got %+v", summary)
+	}
+
+	// Duplicate branch
+	summary, err = repo.SaveBranches(ctx, branches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.BranchesDuplicate != 1 {
+		t.Errorf("expected BranchesDuplicate=1; got %+v", summary)
+	}
+
+	// listing the country should return 1 HQ + its branch = 2 entries
+	gb := models.SwiftCodeQuery{CountryISO2: "GB"}
+	all, err := repo.List(ctx, gb)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("List returned %d items; want 2", len(all))
+	}
+
+	// Delete branch
+	if err := repo.Delete(ctx, "CCCCGB2LAB1", 0, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	// now only HQ remains
+	all, _ = repo.List(ctx, gb)
+	if len(all) != 1 {
+		t.Errorf("after branch delete, got %d items; want 1", len(all))
+	}
+
+	// Delete HQ (and its branches, none now)
+	if err := repo.Delete(ctx, "CCCCGB2LXXX", 0, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	// country now empty
+	if all, _ := repo.List(ctx, gb); len(all) != 0 {
+		t.Errorf("after HQ delete, got %d items; want 0", len(all))
+	}
+}
@@ -0,0 +1,259 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// fileFormatVersion is written to the data file to allow format changes later
+const fileFormatVersion = 1
+
+// fileData is the on-disk layout: HQ documents with embedded branches, in insertion order;
+// deleted HQs and branches are kept with their deleted mark until purged
+type fileData struct {
+	Version      int                `json:"version"`
+	Headquarters []models.SwiftCode `json:"headquarters"`
+}
+
+// FileRepository implements port.SwiftRepository backed by a single local data file.
+// Reads are served from memory (MemoryRepository); every change rewrites the file atomically,
+// so data survives restarts without an external database. A change that can't be written
+// is undone in memory too, so it isn't served after the write failed.
+// Changes made within Batch are written once, when the batch ends.
+type FileRepository struct {
+	*MemoryRepository
+	path string
+	mu   sync.Mutex // serializes changes with their file writes
+	// HQ documents changed since the last write as they were then, nil for new ones
+	pending map[string]*memoryDoc
+}
+
+// fileBatchKey marks the context of a Batch with its repository
+type fileBatchKey struct{}
+
+// NewFileRepository loads data from path (if it exists) and persists changes back to it
+func NewFileRepository(path string) (port.SwiftRepository, error) {
+	if path == "" {
+		return nil, fmt.Errorf("data file path is empty")
+	}
+	repo := &FileRepository{MemoryRepository: newMemoryRepository(), path: path, pending: make(map[string]*memoryDoc)}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read data file: %w", err)
+	}
+	var data fileData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("decode data file: %w", err)
+	}
+	if data.Version != fileFormatVersion {
+		return nil, fmt.Errorf("unsupported data file version %d", data.Version)
+	}
+	for _, hq := range data.Headquarters {
+		// HQs saved before versions were added start at version 1
+		if hq.Version == 0 {
+			hq.Version = 1
+		}
+		repo.insert(hq)
+	}
+	return repo, nil
+}
+
+// SaveHeadquarters saves HQ list and persists it when anything was added
+func (r *FileRepository) SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
+	var summary models.ImportSummary
+	err := r.change(ctx, func() []string { return r.families(codesOf(hqs)...) }, func() (bool, error) {
+		var err error
+		summary, err = r.MemoryRepository.SaveHeadquarters(ctx, hqs)
+		return summary.HQAdded > 0, err
+	})
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	return summary, nil
+}
+
+// SaveBranches saves branches and persists them when anything was added
+func (r *FileRepository) SaveBranches(ctx context.Context, branches []models.SwiftCode) (models.ImportSummary, error) {
+	var summary models.ImportSummary
+	err := r.change(ctx, func() []string { return r.families(codesOf(branches)...) }, func() (bool, error) {
+		var err error
+		summary, err = r.MemoryRepository.SaveBranches(ctx, branches)
+		return summary.BranchesAdded > 0, err
+	})
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	return summary, nil
+}
+
+// AddBranch adds branch for existing HQ and persists it
+func (r *FileRepository) AddBranch(ctx context.Context, hqCode string, branch models.SwiftBranch) error {
+	return r.change(ctx, func() []string { return []string{hqCode} }, func() (bool, error) {
+		return true, r.MemoryRepository.AddBranch(ctx, hqCode, branch)
+	})
+}
+
+// Update replaces details of an HQ or branch and persists it
+func (r *FileRepository) Update(ctx context.Context, sc models.SwiftCode) error {
+	return r.change(ctx, func() []string { return r.families(sc.SwiftCode) }, func() (bool, error) {
+		return true, r.MemoryRepository.Update(ctx, sc)
+	})
+}
+
+// Delete marks by SWIFT as deleted (HQ cascades its branches) and persists it
+func (r *FileRepository) Delete(ctx context.Context, code string, version int64, actor string) error {
+	return r.change(ctx, func() []string { return r.families(code) }, func() (bool, error) {
+		return true, r.MemoryRepository.Delete(ctx, code, version, actor)
+	})
+}
+
+// Restore brings back a deleted HQ or branch and persists it
+func (r *FileRepository) Restore(ctx context.Context, code string) error {
+	touched := func() []string {
+		if strings.HasSuffix(code, "XXX") {
+			return []string{code}
+		}
+		return r.withDeleted(code)
+	}
+	return r.change(ctx, touched, func() (bool, error) {
+		return true, r.MemoryRepository.Restore(ctx, code)
+	})
+}
+
+// Purge removes codes deleted before the given time and persists it when anything was removed
+func (r *FileRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	var purged int
+	err := r.change(ctx, func() []string { return r.withDeleted("") }, func() (bool, error) {
+		var err error
+		purged, err = r.MemoryRepository.Purge(ctx, before)
+		return purged > 0, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// Batch runs fn and writes the changes made with its context once, when fn returns, instead of
+// after every change; a failed write undoes them all. Other changes are still written right away,
+// together with the batch changes made so far.
+func (r *FileRepository) Batch(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(context.WithValue(ctx, fileBatchKey{}, r))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		return err
+	}
+	if werr := r.write(r.snapshot()); werr != nil {
+		r.undo(r.pending)
+		err = errors.Join(err, werr)
+	}
+	r.pending = make(map[string]*memoryDoc)
+	return err
+}
+
+// change backs up the HQ documents touched returns, runs mutate and writes the result when it changed
+// anything. When the write fails only this change is undone, from the backup of the documents it touched.
+// Within a batch the write waits for the end of the batch.
+func (r *FileRepository) change(ctx context.Context, touched func() []string, mutate func() (bool, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inBatch := ctx.Value(fileBatchKey{}) == r
+	codes := touched()
+	if inBatch {
+		// only the state at the last write is needed
+		fresh := codes[:0]
+		for _, code := range codes {
+			if _, ok := r.pending[code]; !ok {
+				fresh = append(fresh, code)
+			}
+		}
+		codes = fresh
+	}
+	before := r.backup(codes)
+	changed, err := mutate()
+	if err != nil || !changed {
+		return err
+	}
+	var added []string
+	for code, doc := range before {
+		if _, ok := r.pending[code]; !ok {
+			r.pending[code] = doc
+			added = append(added, code)
+		}
+	}
+	if inBatch {
+		return nil
+	}
+	if err := r.write(r.snapshot()); err != nil {
+		r.undo(before)
+		for _, code := range added {
+			delete(r.pending, code)
+		}
+		return err
+	}
+	// the write took every change made so far, batches included
+	r.pending = make(map[string]*memoryDoc)
+	return nil
+}
+
+// codesOf returns the SWIFT codes of the list
+func codesOf(list []models.SwiftCode) []string {
+	codes := make([]string, len(list))
+	for i, sc := range list {
+		codes[i] = sc.SwiftCode
+	}
+	return codes
+}
+
+// Ping checks that the data file directory is still accessible
+func (r *FileRepository) Ping(ctx context.Context) error {
+	_, err := os.Stat(filepath.Dir(r.path))
+	return err
+}
+
+// write writes hqs to a temp file and renames it over the data file,
+// so a crash never leaves a half-written file behind; caller holds r.mu
+func (r *FileRepository) write(hqs []models.SwiftCode) error {
+	raw, err := json.Marshal(fileData{Version: fileFormatVersion, Headquarters: hqs})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write data file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write data file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write data file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write data file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("write data file: %w", err)
+	}
+	return nil
+}
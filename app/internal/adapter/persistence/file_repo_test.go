@@ -0,0 +1,206 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestFileRepository_Contract(t *testing.T) {
+	runRepositoryContract(t, func(t *testing.T) port.SwiftRepository {
+		repo, err := NewFileRepository(filepath.Join(t.TempDir(), "swift.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+func TestFileRepository_SurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swift.json")
+
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{
+		{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "BBBBPLPWXXX", BankName: "Bank B", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+	})
+	_, _ = repo.SaveBranches(ctx, []models.SwiftCode{
+		{SwiftCode: "AAAAPLPW001", BankName: "Branch A1", CountryISO2: "PL"},
+		{SwiftCode: "BBBBPLPW001", BankName: "Branch B1", CountryISO2: "PL"},
+	})
+	if err := repo.Delete(ctx, "BBBBPLPWXXX", 0, "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	hq, err := reopened.GetByCode(ctx, "AAAAPLPWXXX")
+	if err != nil {
+		t.Fatalf("GetByCode after restart failed: %v", err)
+	}
+	if len(hq.Branches) != 1 || hq.Branches[0].SwiftCode != "AAAAPLPW001" || hq.Version != 2 {
+		t.Errorf("HQ after restart = %+v; want one branch AAAAPLPW001 and version 2", hq)
+	}
+	if _, err := reopened.GetByCode(ctx, "BBBBPLPW001"); err != port.ErrNotFound {
+		t.Errorf("branch of deleted HQ after restart error = %v; want ErrNotFound", err)
+	}
+	// the deleted HQ is kept in the file with its branches
+	if err := reopened.Restore(ctx, "BBBBPLPWXXX"); err != nil {
+		t.Fatalf("Restore after restart failed: %v", err)
+	}
+	if _, err := reopened.GetByCode(ctx, "BBBBPLPW001"); err != nil {
+		t.Errorf("branch of restored HQ lookup failed: %v", err)
+	}
+}
+
+func TestFileRepository_RejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swift.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileRepository(path); err == nil {
+		t.Error("expected error for corrupt data file, got nil")
+	}
+}
+
+func TestFileRepository_LoadsUnversionedHeadquarters(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "swift.json")
+	// written before HQs had versions
+	data := `{"version":1,"headquarters":[{"swiftCode":"AAAAPLPWXXX","bankName":"Bank A","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,` +
+		`"branches":[{"swiftCode":"AAAAPLPW001","bankName":"Branch A1","countryISO2":"PL"}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"AAAAPLPWXXX", "AAAAPLPW001"} {
+		if sc, err := repo.GetByCode(ctx, code); err != nil || sc.Version != 1 {
+			t.Errorf("%s = %+v, %v; want version 1", code, sc, err)
+		}
+	}
+	// version 1 is the ETag clients get, writes with it succeed
+	if err := repo.Update(ctx, models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A2", CountryISO2: "PL", Version: 1}); err != nil {
+		t.Errorf("Update with version 1 failed: %v", err)
+	}
+}
+
+func TestFileRepository_UndoesChangesThatCantBeWritten(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewFileRepository(filepath.Join(dir, "swift.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hq := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); err != nil {
+		t.Fatal(err)
+	}
+
+	// without the directory no temp file can be written
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{{SwiftCode: "BBBBPLPWXXX", BankName: "Bank B", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}}); err == nil {
+		t.Error("SaveHeadquarters without a writable file succeeded")
+	}
+	if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err == nil {
+		t.Error("Delete without a writable file succeeded")
+	}
+	if _, err := repo.GetByCode(ctx, "BBBBPLPWXXX"); err != port.ErrNotFound {
+		t.Errorf("unwritten HQ lookup error = %v; want ErrNotFound", err)
+	}
+	if got, err := repo.GetByCode(ctx, hq.SwiftCode); err != nil || got.Version != 1 {
+		t.Errorf("HQ after unwritten delete = %+v, %v; want it live at version 1", got, err)
+	}
+
+	// once the file can be written again changes go through
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != nil {
+		t.Errorf("Delete after the directory is back failed: %v", err)
+	}
+}
+
+func TestFileRepository_BatchWritesOnce(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "swift.json")
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hq := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Bank A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{hq}); err != nil {
+		t.Fatal(err)
+	}
+	stored := func(code string) bool {
+		reopened, err := NewFileRepository(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = reopened.GetByCode(ctx, code)
+		return err == nil
+	}
+
+	batcher := repo.(port.Batcher)
+	err = batcher.Batch(ctx, func(ctx context.Context) error {
+		_, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{{SwiftCode: "BBBBPLPWXXX", BankName: "Bank B", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}})
+		if err == nil {
+			_, err = repo.SaveBranches(ctx, []models.SwiftCode{{SwiftCode: "BBBBPLPW001", BankName: "Branch B1", CountryISO2: "PL"}})
+		}
+		if stored("BBBBPLPWXXX") {
+			t.Error("batch change was written before the batch ended")
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if !stored("BBBBPLPWXXX") || !stored("BBBBPLPW001") {
+		t.Error("batch changes weren't written when the batch ended")
+	}
+
+	// a batch that can't be written is undone, earlier changes stay
+	err = batcher.Batch(ctx, func(ctx context.Context) error {
+		if err := repo.Delete(ctx, hq.SwiftCode, 0, "tester"); err != nil {
+			return err
+		}
+		if _, err := repo.SaveHeadquarters(ctx, []models.SwiftCode{{SwiftCode: "CCCCPLPWXXX", BankName: "Bank C", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}}); err != nil {
+			return err
+		}
+		return os.RemoveAll(dir)
+	})
+	if err == nil {
+		t.Fatal("Batch without a writable file succeeded")
+	}
+	if _, err := repo.GetByCode(ctx, "CCCCPLPWXXX"); err != port.ErrNotFound {
+		t.Errorf("unwritten batch HQ lookup error = %v; want ErrNotFound", err)
+	}
+	if got, err := repo.GetByCode(ctx, hq.SwiftCode); err != nil || got.Version != 1 {
+		t.Errorf("HQ after unwritten batch delete = %+v, %v; want it live at version 1", got, err)
+	}
+	if got, err := repo.GetByCode(ctx, "BBBBPLPWXXX"); err != nil || len(got.Branches) != 1 {
+		t.Errorf("HQ of the written batch = %+v, %v; want it with its branch", got, err)
+	}
+}
@@ -0,0 +1,103 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestHistoryRepository_Contract(t *testing.T) {
+	runAdapters(t, map[string]func(t *testing.T) port.HistoryRepository{
+		"memory": func(*testing.T) port.HistoryRepository { return NewMemoryHistoryRepository() },
+		"mongo":  openMongoHistoryRepository,
+	}, func(t *testing.T, history port.HistoryRepository) {
+		ctx := context.Background()
+		t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		at := func(d int) time.Time { return t0.Add(time.Duration(d) * time.Hour) }
+		version := func(d int, code, bank, iso2 string, deleted bool) models.SwiftCodeVersion {
+			return models.SwiftCodeVersion{ValidFrom: at(d), Deleted: deleted, Code: models.SwiftCode{
+				SwiftCode: code, BankName: bank, CountryISO2: iso2, IsHeadquarter: code[8:] == "XXX",
+			}}
+		}
+		// HQ A renamed at 2, its branch deleted at 3; HQ B moved to DE at 2; branch C added at 4
+		err := history.Record(ctx, []models.SwiftCodeVersion{
+			version(0, "AAAAPLPWXXX", "Bank A", "PL", false),
+			version(0, "AAAAPLPW001", "Branch A1", "PL", false),
+			version(1, "BBBBPLPWXXX", "Bank B", "PL", false),
+		})
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		err = history.Record(ctx, []models.SwiftCodeVersion{
+			version(2, "AAAAPLPWXXX", "Bank A Renamed", "PL", false),
+			version(2, "BBBBPLPWXXX", "Bank B", "DE", false),
+			version(3, "AAAAPLPW001", "Branch A1", "PL", true),
+			version(4, "AAAAPLPW002", "Branch A2", "PL", false),
+		})
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+
+		t.Run("History lists versions oldest first", func(t *testing.T) {
+			versions, err := history.History(ctx, "AAAAPLPWXXX")
+			if err != nil || len(versions) != 2 {
+				t.Fatalf("History = %+v, %v; want 2 versions", versions, err)
+			}
+			if versions[0].Code.BankName != "Bank A" || versions[1].Code.BankName != "Bank A Renamed" || !versions[1].ValidFrom.Equal(at(2)) {
+				t.Errorf("versions = %+v", versions)
+			}
+			if versions, err := history.History(ctx, "ZZZZPLPWXXX"); err != nil || len(versions) != 0 {
+				t.Errorf("History of unknown code = %+v, %v; want none", versions, err)
+			}
+		})
+
+		t.Run("Latest returns the last version of each code", func(t *testing.T) {
+			latest, err := history.Latest(ctx, []string{"AAAAPLPWXXX", "AAAAPLPW001", "ZZZZPLPWXXX"})
+			if err != nil || len(latest) != 2 {
+				t.Fatalf("Latest = %+v, %v; want 2 codes", latest, err)
+			}
+			if latest["AAAAPLPWXXX"].Code.BankName != "Bank A Renamed" || !latest["AAAAPLPW001"].Deleted {
+				t.Errorf("Latest = %+v; want Bank A Renamed and the deleted branch", latest)
+			}
+			if latest, err := history.Latest(ctx, nil); err != nil || len(latest) != 0 {
+				t.Errorf("Latest of no codes = %+v, %v; want none", latest, err)
+			}
+		})
+
+		tests := []struct {
+			name  string
+			query models.HistoryQuery
+			want  string // code:bank of the returned versions
+		}{
+			{"before anything", models.HistoryQuery{AsOf: at(-1)}, "[]"},
+			{"code at start", models.HistoryQuery{AsOf: at(0), SwiftCode: "AAAAPLPWXXX"}, "[AAAAPLPWXXX:Bank A]"},
+			{"code just before change", models.HistoryQuery{AsOf: at(2).Add(-time.Second), SwiftCode: "AAAAPLPWXXX"}, "[AAAAPLPWXXX:Bank A]"},
+			{"code at change", models.HistoryQuery{AsOf: at(2), SwiftCode: "AAAAPLPWXXX"}, "[AAAAPLPWXXX:Bank A Renamed]"},
+			{"bank before branch deleted", models.HistoryQuery{AsOf: at(2), BankPrefix: "AAAAPLPW"}, "[AAAAPLPW001:Branch A1 AAAAPLPWXXX:Bank A Renamed]"},
+			{"bank after branch deleted and added", models.HistoryQuery{AsOf: at(5), BankPrefix: "AAAAPLPW"}, "[AAAAPLPW002:Branch A2 AAAAPLPWXXX:Bank A Renamed]"},
+			{"country before move", models.HistoryQuery{AsOf: at(1), CountryISO2: "PL"}, "[AAAAPLPW001:Branch A1 AAAAPLPWXXX:Bank A BBBBPLPWXXX:Bank B]"},
+			{"country after move", models.HistoryQuery{AsOf: at(5), CountryISO2: "PL"}, "[AAAAPLPW002:Branch A2 AAAAPLPWXXX:Bank A Renamed]"},
+			{"moved to country", models.HistoryQuery{AsOf: at(5), CountryISO2: "DE"}, "[BBBBPLPWXXX:Bank B]"},
+			{"page", models.HistoryQuery{AsOf: at(1), CountryISO2: "PL", After: "AAAAPLPW001", Limit: 1}, "[AAAAPLPWXXX:Bank A]"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				versions, err := history.AsOf(ctx, tt.query)
+				if err != nil {
+					t.Fatalf("AsOf failed: %v", err)
+				}
+				got := make([]string, len(versions))
+				for i, v := range versions {
+					got[i] = v.Code.SwiftCode + ":" + v.Code.BankName
+				}
+				if fmt.Sprint(got) != tt.want {
+					t.Errorf("AsOf = %v; want %s", got, tt.want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MemoryHistoryRepository implements port.HistoryRepository in process memory, versions are lost on restart
+type MemoryHistoryRepository struct {
+	mu       sync.RWMutex
+	versions map[string][]models.SwiftCodeVersion // code -> versions in recording order
+}
+
+// NewMemoryHistoryRepository creates empty in-memory history
+func NewMemoryHistoryRepository() port.HistoryRepository {
+	return &MemoryHistoryRepository{versions: make(map[string][]models.SwiftCodeVersion)}
+}
+
+// Record appends versions after the recorded ones
+func (r *MemoryHistoryRepository) Record(ctx context.Context, versions []models.SwiftCodeVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range versions {
+		r.versions[v.Code.SwiftCode] = append(r.versions[v.Code.SwiftCode], v)
+	}
+	return nil
+}
+
+// History returns the versions of code sorted by ValidFrom, recording order within the same time
+func (r *MemoryHistoryRepository) History(ctx context.Context, code string) ([]models.SwiftCodeVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := append([]models.SwiftCodeVersion(nil), r.versions[code]...)
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].ValidFrom.Before(versions[j].ValidFrom) })
+	return versions, nil
+}
+
+// Latest returns the last version of each code, the last recorded one on ties
+func (r *MemoryHistoryRepository) Latest(ctx context.Context, codes []string) (map[string]models.SwiftCodeVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	latest := make(map[string]models.SwiftCodeVersion, len(codes))
+	for _, code := range codes {
+		for i, v := range r.versions[code] {
+			if i == 0 || !v.ValidFrom.Before(latest[code].ValidFrom) {
+				latest[code] = v
+			}
+		}
+	}
+	return latest, nil
+}
+
+// AsOf returns the versions valid at q.AsOf of the matching codes
+func (r *MemoryHistoryRepository) AsOf(ctx context.Context, q models.HistoryQuery) ([]models.SwiftCodeVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var codes []string
+	for code := range r.versions {
+		if (q.SwiftCode == "" || code == q.SwiftCode) && strings.HasPrefix(code, q.BankPrefix) && code > q.After {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	var found []models.SwiftCodeVersion
+	for _, code := range codes {
+		if q.Limit > 0 && len(found) == q.Limit {
+			break
+		}
+		v, ok := validAt(r.versions[code], q)
+		if ok && !v.Deleted && (q.CountryISO2 == "" || v.Code.CountryISO2 == q.CountryISO2) {
+			found = append(found, v)
+		}
+	}
+	return found, nil
+}
+
+// validAt returns the latest of versions starting at or before q.AsOf, the last recorded one on ties
+func validAt(versions []models.SwiftCodeVersion, q models.HistoryQuery) (models.SwiftCodeVersion, bool) {
+	var valid models.SwiftCodeVersion
+	found := false
+	for _, v := range versions {
+		if !v.ValidFrom.After(q.AsOf) && (!found || !v.ValidFrom.Before(valid.ValidFrom)) {
+			valid, found = v, true
+		}
+	}
+	return valid, found
+}
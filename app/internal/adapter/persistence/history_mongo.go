@@ -0,0 +1,149 @@
+package persistence
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MongoHistoryRepository implements port.HistoryRepository for MongoDB, one document per version
+type MongoHistoryRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewMongoHistoryRepository creates connection with MongoDB and inits the history collection
+func NewMongoHistoryRepository(uri, dbName, collName string) (port.HistoryRepository, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+	_, err = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "code.swiftCode", Value: 1}, {Key: "validFrom", Value: 1}}},
+		{Keys: bson.D{{Key: "code.countryISO2", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoHistoryRepository{client: client, collection: coll}, nil
+}
+
+// Record inserts versions in one unordered bulk write
+func (r *MongoHistoryRepository) Record(ctx context.Context, versions []models.SwiftCodeVersion) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(versions))
+	for i, v := range versions {
+		docs[i] = v
+	}
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// History returns the versions of code sorted by ValidFrom, insertion order within the same millisecond
+func (r *MongoHistoryRepository) History(ctx context.Context, code string) ([]models.SwiftCodeVersion, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "validFrom", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"code.swiftCode": code}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.SwiftCodeVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Latest picks the last version of every code in one aggregation
+func (r *MongoHistoryRepository) Latest(ctx context.Context, codes []string) (map[string]models.SwiftCodeVersion, error) {
+	latest := make(map[string]models.SwiftCodeVersion, len(codes))
+	if len(codes) == 0 {
+		return latest, nil
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"code.swiftCode": bson.M{"$in": codes}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "code.swiftCode", Value: 1}, {Key: "validFrom", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$code.swiftCode", "version": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceWith", Value: "$version"}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.SwiftCodeVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		latest[v.Code.SwiftCode] = v
+	}
+	return latest, nil
+}
+
+// AsOf picks the latest version of every matching code in an aggregation, so only one page is loaded
+func (r *MongoHistoryRepository) AsOf(ctx context.Context, q models.HistoryQuery) ([]models.SwiftCodeVersion, error) {
+	code := bson.M{}
+	if q.SwiftCode != "" {
+		code["$eq"] = q.SwiftCode
+	}
+	if q.BankPrefix != "" {
+		code["$regex"] = "^" + regexp.QuoteMeta(q.BankPrefix)
+	}
+	if q.After != "" {
+		code["$gt"] = q.After
+	}
+	if q.CountryISO2 != "" {
+		// every code ever in the country, the version valid at AsOf decides whether it is in it then
+		codes, err := r.collection.Distinct(ctx, "code.swiftCode", bson.M{"code.countryISO2": q.CountryISO2})
+		if err != nil {
+			return nil, err
+		}
+		code["$in"] = codes
+	}
+	match := bson.M{"validFrom": bson.M{"$lte": q.AsOf}}
+	if len(code) > 0 {
+		match["code.swiftCode"] = code
+	}
+
+	valid := bson.M{"deleted": bson.M{"$ne": true}}
+	if q.CountryISO2 != "" {
+		valid["code.countryISO2"] = q.CountryISO2
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "code.swiftCode", Value: 1}, {Key: "validFrom", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$code.swiftCode", "version": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceWith", Value: "$version"}},
+		{{Key: "$match", Value: valid}},
+		{{Key: "$sort", Value: bson.D{{Key: "code.swiftCode", Value: 1}}}},
+	}
+	if q.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: q.Limit}})
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	var versions []models.SwiftCodeVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Close closes MongoDB connection
+func (r *MongoHistoryRepository) Close(ctx context.Context) error {
+	return r.client.Disconnect(ctx)
+}
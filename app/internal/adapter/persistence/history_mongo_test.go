@@ -0,0 +1,25 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+const testHistoryCollection = "test_history"
+
+// openMongoHistoryRepository connects to an empty history collection: skips the test if Mongo isn't running
+func openMongoHistoryRepository(t *testing.T) port.HistoryRepository {
+	history, err := NewMongoHistoryRepository(testURI, testDB, testHistoryCollection)
+	if err != nil {
+		t.Skipf("skipping Mongo tests; cannot connect: %v", err)
+	}
+	repo := history.(*MongoHistoryRepository)
+	t.Cleanup(func() { repo.Close(context.Background()) })
+	// clean slate
+	repo.collection.DeleteMany(context.Background(), bson.M{})
+	return repo
+}
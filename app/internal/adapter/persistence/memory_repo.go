@@ -0,0 +1,642 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// MemoryRepository implements port.SwiftRepository in process memory.
+// It mirrors MongoRepository semantics (HQ documents with embedded branches,
+// deleted ones kept with a mark) and is safe for concurrent use.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	seq    int64
+	docs   map[string]*memoryDoc
+	owners map[string]map[string]struct{} // branch code -> HQ codes embedding it
+}
+
+// memoryDoc is a stored HQ document; seq keeps insertion order like Mongo's natural order
+type memoryDoc struct {
+	seq int64
+	hq  models.SwiftCode
+}
+
+// NewMemoryRepository creates empty in-memory repository
+func NewMemoryRepository() port.SwiftRepository {
+	return newMemoryRepository()
+}
+
+func newMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		docs:   make(map[string]*memoryDoc),
+		owners: make(map[string]map[string]struct{}),
+	}
+}
+
+// SaveHeadquarters inserts HQs that don't exist yet and replaces deleted ones, live ones are skipped
+func (r *MemoryRepository) SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summary models.ImportSummary
+	for _, hq := range hqs {
+		doc, ok := r.docs[hq.SwiftCode]
+		switch {
+		case !ok:
+			hq.Version = 1
+			r.insert(hq)
+		case doc.hq.DeletedAt == nil:
+			summary.HQSkipped++
+			continue
+		default:
+			// the deleted HQ goes with the branches deleted together with it,
+			// the version goes on so ETags from before the delete don't match
+			hq.Version = doc.hq.Version + 1
+			r.replace(doc, hq)
+		}
+		summary.HQAdded++
+	}
+	return summary, nil
+}
+
+// SaveBranches add branches, checking if HQ exists
+func (r *MemoryRepository) SaveBranches(ctx context.Context, branches []models.SwiftCode) (models.ImportSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summary models.ImportSummary
+	for _, br := range branches {
+		// get HQ with prefix of 8 characters
+		hqCode := strings.ToUpper(br.SwiftCode[:8] + "XXX")
+		doc := r.live(hqCode)
+		if doc == nil {
+			summary.BranchesMissingHQ++
+			continue
+		}
+		if !r.appendBranch(doc, models.SwiftBranch{
+			SwiftCode:     br.SwiftCode,
+			BankName:      br.BankName,
+			Address:       br.Address,
+			TownName:      br.TownName,
+			CodeType:      br.CodeType,
+			TimeZone:      br.TimeZone,
+			CountryISO2:   br.CountryISO2,
+			IsHeadquarter: false,
+		}) {
+			summary.BranchesDuplicate++
+			continue
+		}
+		summary.BranchesAdded++
+	}
+	return summary, nil
+}
+
+// GetByCode gets SwiftCode (HQ or branch) by code
+func (r *MemoryRepository) GetByCode(ctx context.Context, code string) (models.SwiftCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if sc, ok := r.lookup(code); ok {
+		return sc, nil
+	}
+	return models.SwiftCode{}, port.ErrNotFound
+}
+
+// GetByCodes looks the codes up under a single lock
+func (r *MemoryRepository) GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found := make(map[string]models.SwiftCode, len(codes))
+	for _, code := range codes {
+		if sc, ok := r.lookup(code); ok {
+			found[code] = sc
+		}
+	}
+	return found, nil
+}
+
+// lookup finds a live HQ or branch, a branch with the country and version of its HQ. Caller holds a lock.
+func (r *MemoryRepository) lookup(code string) (models.SwiftCode, bool) {
+	if doc := r.live(code); doc != nil {
+		return visible(doc.hq), true
+	}
+
+	owner := r.owner(code)
+	if owner == nil {
+		return models.SwiftCode{}, false
+	}
+	for _, br := range owner.hq.Branches {
+		if br.SwiftCode == code {
+			return models.SwiftCode{
+				SwiftCode:     br.SwiftCode,
+				BankName:      br.BankName,
+				Address:       br.Address,
+				TownName:      br.TownName,
+				CodeType:      br.CodeType,
+				TimeZone:      br.TimeZone,
+				CountryISO2:   owner.hq.CountryISO2,
+				CountryName:   owner.hq.CountryName,
+				IsHeadquarter: false,
+				Version:       owner.hq.Version,
+			}, true
+		}
+	}
+	return models.SwiftCode{}, false
+}
+
+// List returns HQs and branches matching the query, ordered by SWIFT code
+func (r *MemoryRepository) List(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := []models.SwiftCode{}
+	for _, doc := range r.docs {
+		if doc.hq.DeletedAt != nil {
+			continue
+		}
+		for _, sc := range flatten(doc.hq) {
+			if sc.SwiftCode > q.After && matchesQuery(sc, q) {
+				results = append(results, sc)
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].SwiftCode < results[j].SwiftCode })
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+// Walk calls fn for a sorted snapshot of HQs and branches, taken so fn may use the repository
+func (r *MemoryRepository) Walk(ctx context.Context, fn func(models.SwiftCode) error) error {
+	entries, err := r.List(ctx, models.SwiftCodeQuery{})
+	if err != nil {
+		return err
+	}
+	for _, sc := range entries {
+		if err := fn(sc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search scores every HQ and branch against the query
+func (r *MemoryRepository) Search(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []models.SwiftCode
+	for _, doc := range r.docs {
+		if doc.hq.DeletedAt == nil {
+			entries = append(entries, flatten(doc.hq)...)
+		}
+	}
+	return rankSearchHits(entries, util.SearchTerms(query), limit), nil
+}
+
+// AddBranch add branch to existing HQ
+func (r *MemoryRepository) AddBranch(ctx context.Context, hqCode string, br models.SwiftBranch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	doc := r.live(hqCode)
+	if doc == nil {
+		return port.ErrHQNotFound
+	}
+	if !r.appendBranch(doc, br) {
+		return port.ErrBranchDuplicate
+	}
+	return nil
+}
+
+// Update replaces details of an HQ or of a branch in the first HQ embedding it
+func (r *MemoryRepository) Update(ctx context.Context, sc models.SwiftCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if doc := r.live(sc.SwiftCode); doc != nil {
+		if sc.Version != 0 && sc.Version != doc.hq.Version {
+			return port.ErrVersionMismatch
+		}
+		doc.hq.Version++
+		doc.hq.BankName = sc.BankName
+		doc.hq.Address = sc.Address
+		doc.hq.TownName = sc.TownName
+		doc.hq.CodeType = sc.CodeType
+		doc.hq.TimeZone = sc.TimeZone
+		doc.hq.CountryISO2 = sc.CountryISO2
+		doc.hq.CountryName = sc.CountryName
+		return nil
+	}
+
+	owner := r.owner(sc.SwiftCode)
+	if owner == nil {
+		return port.ErrNotFound
+	}
+	if sc.Version != 0 && sc.Version != owner.hq.Version {
+		return port.ErrVersionMismatch
+	}
+	for i := range owner.hq.Branches {
+		br := &owner.hq.Branches[i]
+		if br.SwiftCode == sc.SwiftCode {
+			br.BankName = sc.BankName
+			br.Address = sc.Address
+			br.TownName = sc.TownName
+			br.CodeType = sc.CodeType
+			br.TimeZone = sc.TimeZone
+			br.CountryISO2 = sc.CountryISO2
+			owner.hq.Version++
+			return nil
+		}
+	}
+	return port.ErrNotFound
+}
+
+// Delete marks entry by given SWIFT code as deleted
+func (r *MemoryRepository) Delete(ctx context.Context, code string, version int64, actor string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	if strings.HasSuffix(code, "XXX") {
+		// the whole HQ with its branches is hidden
+		doc := r.live(code)
+		if doc == nil {
+			return port.ErrNotFound
+		}
+		if version != 0 && version != doc.hq.Version {
+			return port.ErrVersionMismatch
+		}
+		doc.hq.DeletedAt = &now
+		doc.hq.DeletedBy = actor
+		doc.hq.Version++
+		return nil
+	}
+
+	// move branch only to the deleted ones, from the first HQ embedding it
+	owner := r.owner(code)
+	if owner == nil {
+		return port.ErrNotFound
+	}
+	if version != 0 && version != owner.hq.Version {
+		return port.ErrVersionMismatch
+	}
+	kept := owner.hq.Branches[:0]
+	for _, br := range owner.hq.Branches {
+		if br.SwiftCode != code {
+			kept = append(kept, br)
+			continue
+		}
+		br.DeletedAt = &now
+		br.DeletedBy = actor
+		owner.hq.DeletedBranches = append(owner.hq.DeletedBranches, br)
+	}
+	owner.hq.Branches = kept
+	owner.hq.Version++
+	r.unown(code, owner.hq.SwiftCode)
+	return nil
+}
+
+// Restore clears the deleted mark of an HQ or moves a deleted branch back to its HQ
+func (r *MemoryRepository) Restore(ctx context.Context, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if strings.HasSuffix(code, "XXX") {
+		doc, ok := r.docs[code]
+		if !ok || doc.hq.DeletedAt == nil {
+			return port.ErrNotFound
+		}
+		doc.hq.DeletedAt = nil
+		doc.hq.DeletedBy = ""
+		doc.hq.Version++
+		return nil
+	}
+
+	for _, doc := range r.docs {
+		if !hasBranch(doc.hq.DeletedBranches, code) {
+			continue
+		}
+		if doc.hq.DeletedAt != nil {
+			return port.ErrHQNotFound
+		}
+		kept := doc.hq.DeletedBranches[:0]
+		for _, br := range doc.hq.DeletedBranches {
+			if br.SwiftCode != code {
+				kept = append(kept, br)
+				continue
+			}
+			br.DeletedAt = nil
+			br.DeletedBy = ""
+			doc.hq.Branches = append(doc.hq.Branches, br)
+		}
+		doc.hq.DeletedBranches = kept
+		doc.hq.Version++
+		r.own(code, doc.hq.SwiftCode)
+		return nil
+	}
+	return port.ErrNotFound
+}
+
+// Purge removes HQs and branches deleted before the given time
+func (r *MemoryRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for code, doc := range r.docs {
+		if doc.hq.DeletedAt != nil && doc.hq.DeletedAt.Before(before) {
+			// its branches were deleted before it, so they go too
+			purged += 1 + len(doc.hq.DeletedBranches)
+			for _, br := range doc.hq.Branches {
+				r.unown(br.SwiftCode, code)
+			}
+			delete(r.docs, code)
+			continue
+		}
+		kept := doc.hq.DeletedBranches[:0]
+		for _, br := range doc.hq.DeletedBranches {
+			if br.DeletedAt.Before(before) {
+				purged++
+				continue
+			}
+			kept = append(kept, br)
+		}
+		doc.hq.DeletedBranches = kept
+	}
+	return purged, nil
+}
+
+// Ping always succeeds, there is no connection to check
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// snapshot returns copies of all HQ documents in insertion order
+func (r *MemoryRepository) snapshot() []models.SwiftCode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	docs := make([]*memoryDoc, 0, len(r.docs))
+	for _, doc := range r.docs {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].seq < docs[j].seq })
+
+	hqs := make([]models.SwiftCode, len(docs))
+	for i, doc := range docs {
+		hqs[i] = cloneSwiftCode(doc.hq)
+	}
+	return hqs
+}
+
+// families returns the HQ documents a change of the codes can touch: an HQ code itself,
+// for a branch the HQ of its prefix and every HQ embedding it
+func (r *MemoryRepository) families(codes ...string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool, len(codes))
+	var hqs []string
+	add := func(code string) {
+		if !seen[code] {
+			seen[code] = true
+			hqs = append(hqs, code)
+		}
+	}
+	for _, code := range codes {
+		if strings.HasSuffix(code, "XXX") || len(code) < 8 {
+			add(code)
+			continue
+		}
+		add(code[:8] + "XXX")
+		for hqCode := range r.owners[code] {
+			add(hqCode)
+		}
+	}
+	return hqs
+}
+
+// withDeleted returns the HQ documents holding the deleted branch code,
+// or with an empty code every document holding a deleted HQ or branch
+func (r *MemoryRepository) withDeleted(code string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hqs []string
+	for hqCode, doc := range r.docs {
+		if code == "" && (doc.hq.DeletedAt != nil || len(doc.hq.DeletedBranches) > 0) ||
+			code != "" && hasBranch(doc.hq.DeletedBranches, code) {
+			hqs = append(hqs, hqCode)
+		}
+	}
+	return hqs
+}
+
+// backup copies the documents of the HQ codes, nil for codes not stored, so undo can put them back
+func (r *MemoryRepository) backup(codes []string) map[string]*memoryDoc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	saved := make(map[string]*memoryDoc, len(codes))
+	for _, code := range codes {
+		saved[code] = nil
+		if doc, ok := r.docs[code]; ok {
+			saved[code] = &memoryDoc{seq: doc.seq, hq: cloneSwiftCode(doc.hq)}
+		}
+	}
+	return saved
+}
+
+// undo puts back the documents of a backup, removing the ones that weren't stored then
+func (r *MemoryRepository) undo(saved map[string]*memoryDoc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for code, old := range saved {
+		if doc, ok := r.docs[code]; ok {
+			for _, br := range doc.hq.Branches {
+				r.unown(br.SwiftCode, code)
+			}
+			delete(r.docs, code)
+		}
+		if old == nil {
+			continue
+		}
+		r.docs[code] = old
+		for _, br := range old.hq.Branches {
+			r.own(br.SwiftCode, code)
+		}
+	}
+}
+
+// insert stores a copy of the HQ document; caller holds the write lock
+func (r *MemoryRepository) insert(hq models.SwiftCode) {
+	r.seq++
+	doc := &memoryDoc{seq: r.seq, hq: cloneSwiftCode(hq)}
+	r.docs[hq.SwiftCode] = doc
+	for _, br := range doc.hq.Branches {
+		r.own(br.SwiftCode, hq.SwiftCode)
+	}
+}
+
+// replace swaps the HQ of a stored document, keeping its place in insertion order;
+// caller holds the write lock
+func (r *MemoryRepository) replace(doc *memoryDoc, hq models.SwiftCode) {
+	for _, br := range doc.hq.Branches {
+		r.unown(br.SwiftCode, doc.hq.SwiftCode)
+	}
+	doc.hq = cloneSwiftCode(hq)
+	for _, br := range doc.hq.Branches {
+		r.own(br.SwiftCode, hq.SwiftCode)
+	}
+}
+
+// appendBranch appends the branch unless one with its code is embedded already, whatever its details;
+// a deleted branch with the code is replaced. Caller holds the write lock.
+func (r *MemoryRepository) appendBranch(doc *memoryDoc, br models.SwiftBranch) bool {
+	for _, existing := range doc.hq.Branches {
+		if existing.SwiftCode == br.SwiftCode {
+			return false
+		}
+	}
+	kept := doc.hq.DeletedBranches[:0]
+	for _, deleted := range doc.hq.DeletedBranches {
+		if deleted.SwiftCode != br.SwiftCode {
+			kept = append(kept, deleted)
+		}
+	}
+	doc.hq.DeletedBranches = kept
+	doc.hq.Branches = append(doc.hq.Branches, br)
+	doc.hq.Version++
+	r.own(br.SwiftCode, doc.hq.SwiftCode)
+	return true
+}
+
+// live returns the HQ document of code unless it is missing or deleted. Caller holds a lock.
+func (r *MemoryRepository) live(code string) *memoryDoc {
+	doc, ok := r.docs[code]
+	if !ok || doc.hq.DeletedAt != nil {
+		return nil
+	}
+	return doc
+}
+
+// owner returns the live HQ document embedding the branch code, nil when there is none;
+// the first HQ (in insertion order) wins, as with FindOne. Caller holds a lock.
+func (r *MemoryRepository) owner(branchCode string) *memoryDoc {
+	var owner *memoryDoc
+	for hqCode := range r.owners[branchCode] {
+		doc := r.docs[hqCode]
+		if doc.hq.DeletedAt == nil && (owner == nil || doc.seq < owner.seq) {
+			owner = doc
+		}
+	}
+	return owner
+}
+
+func (r *MemoryRepository) own(branchCode, hqCode string) {
+	if r.owners[branchCode] == nil {
+		r.owners[branchCode] = make(map[string]struct{})
+	}
+	r.owners[branchCode][hqCode] = struct{}{}
+}
+
+func (r *MemoryRepository) unown(branchCode, hqCode string) {
+	delete(r.owners[branchCode], hqCode)
+	if len(r.owners[branchCode]) == 0 {
+		delete(r.owners, branchCode)
+	}
+}
+
+// hasBranch reports whether a branch in the list has the code
+func hasBranch(branches []models.SwiftBranch, code string) bool {
+	for _, br := range branches {
+		if br.SwiftCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten returns the HQ (without nested branches) followed by its branches,
+// branches take country name from their HQ like in List
+func flatten(hq models.SwiftCode) []models.SwiftCode {
+	entries := make([]models.SwiftCode, 0, len(hq.Branches)+1)
+	entries = append(entries, models.SwiftCode{
+		SwiftCode:     hq.SwiftCode,
+		BankName:      hq.BankName,
+		Address:       hq.Address,
+		TownName:      hq.TownName,
+		CodeType:      hq.CodeType,
+		TimeZone:      hq.TimeZone,
+		CountryISO2:   hq.CountryISO2,
+		CountryName:   hq.CountryName,
+		IsHeadquarter: hq.IsHeadquarter,
+	})
+	for _, br := range hq.Branches {
+		entries = append(entries, models.SwiftCode{
+			SwiftCode:     br.SwiftCode,
+			BankName:      br.BankName,
+			Address:       br.Address,
+			TownName:      br.TownName,
+			CodeType:      br.CodeType,
+			TimeZone:      br.TimeZone,
+			CountryISO2:   br.CountryISO2,
+			CountryName:   hq.CountryName,
+			IsHeadquarter: br.IsHeadquarter,
+		})
+	}
+	return entries
+}
+
+// matchesQuery applies SwiftCodeQuery filters (not paging) to a flattened entry
+func matchesQuery(sc models.SwiftCode, q models.SwiftCodeQuery) bool {
+	if q.CountryISO2 != "" && sc.CountryISO2 != q.CountryISO2 {
+		return false
+	}
+	if q.IsHeadquarter != nil && sc.IsHeadquarter != *q.IsHeadquarter {
+		return false
+	}
+	if q.BankNamePrefix != "" && !strings.HasPrefix(strings.ToUpper(sc.BankName), strings.ToUpper(q.BankNamePrefix)) {
+		return false
+	}
+	if q.Town != "" && !strings.EqualFold(sc.TownName, q.Town) {
+		return false
+	}
+	if q.TimeZone != "" && sc.TimeZone != q.TimeZone {
+		return false
+	}
+	return true
+}
+
+// cloneSwiftCode deep-copies the branches so callers can't mutate stored data;
+// an empty branch list is dropped like an omitted BSON field
+func cloneSwiftCode(sc models.SwiftCode) models.SwiftCode {
+	sc.Branches = cloneBranches(sc.Branches)
+	sc.DeletedBranches = cloneBranches(sc.DeletedBranches)
+	return sc
+}
+
+func cloneBranches(branches []models.SwiftBranch) []models.SwiftBranch {
+	if len(branches) == 0 {
+		return nil
+	}
+	return append([]models.SwiftBranch(nil), branches...)
+}
+
+// visible copies a live HQ document for readers, without its deleted branches
+func visible(hq models.SwiftCode) models.SwiftCode {
+	hq = cloneSwiftCode(hq)
+	hq.DeletedBranches = nil
+	return hq
+}
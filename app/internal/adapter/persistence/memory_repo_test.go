@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestMemoryRepository_Contract(t *testing.T) {
+	runRepositoryContract(t, func(t *testing.T) port.SwiftRepository {
+		return NewMemoryRepository()
+	})
+}
+
+func TestMemoryRepository_ConcurrentWrites(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	_, _ = repo.SaveHeadquarters(ctx, []models.SwiftCode{
+		{SwiftCode: "CCCCGB2LXXX", BankName: "Bank C", CountryISO2: "GB", CountryName: "UK", IsHeadquarter: true},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			code := fmt.Sprintf("CCCCGB2L%03d", i)
+			_ = repo.AddBranch(ctx, "CCCCGB2LXXX", models.SwiftBranch{SwiftCode: code, CountryISO2: "GB"})
+			_, _ = repo.GetByCode(ctx, code)
+		}(i)
+	}
+	wg.Wait()
+
+	hq, err := repo.GetByCode(ctx, "CCCCGB2LXXX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hq.Branches) != 50 {
+		t.Errorf("got %d branches; want 50", len(hq.Branches))
+	}
+}
@@ -0,0 +1,24 @@
+-- Headquarters (codes ending with XXX) and their branches
+CREATE TABLE headquarters (
+    id           BIGSERIAL PRIMARY KEY,
+    swift_code   VARCHAR(11) NOT NULL UNIQUE,
+    bank_name    TEXT        NOT NULL DEFAULT '',
+    address      TEXT        NOT NULL DEFAULT '',
+    country_iso2 CHAR(2)     NOT NULL,
+    country_name TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX headquarters_country_iso2_idx ON headquarters (country_iso2);
+
+CREATE TABLE branches (
+    id             BIGSERIAL PRIMARY KEY,
+    headquarter_id BIGINT      NOT NULL REFERENCES headquarters (id) ON DELETE CASCADE,
+    swift_code     VARCHAR(11) NOT NULL UNIQUE,
+    bank_name      TEXT        NOT NULL DEFAULT '',
+    address        TEXT        NOT NULL DEFAULT '',
+    country_iso2   CHAR(2)     NOT NULL,
+    country_name   TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX branches_headquarter_id_idx ON branches (headquarter_id);
+CREATE INDEX branches_country_iso2_idx ON branches (country_iso2);
@@ -0,0 +1,3 @@
+-- Case-insensitive bank name prefix lookups for the list endpoint
+CREATE INDEX headquarters_bank_name_prefix_idx ON headquarters (lower(bank_name) text_pattern_ops);
+CREATE INDEX branches_bank_name_prefix_idx ON branches (lower(bank_name) text_pattern_ops);
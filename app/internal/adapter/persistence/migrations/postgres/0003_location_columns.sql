@@ -0,0 +1,15 @@
+-- Town, code type and time zone from the source CSV, empty for rows imported before
+ALTER TABLE headquarters
+    ADD COLUMN town_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN code_type TEXT NOT NULL DEFAULT '',
+    ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+ALTER TABLE branches
+    ADD COLUMN town_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN code_type TEXT NOT NULL DEFAULT '',
+    ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+
+-- Town and time zone filters of the list endpoint
+CREATE INDEX headquarters_town_name_idx ON headquarters (lower(town_name));
+CREATE INDEX branches_town_name_idx ON branches (lower(town_name));
+CREATE INDEX headquarters_time_zone_idx ON headquarters (time_zone);
+CREATE INDEX branches_time_zone_idx ON branches (time_zone);
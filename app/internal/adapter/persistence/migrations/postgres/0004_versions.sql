@@ -0,0 +1,2 @@
+-- Version of each HQ with its branches, incremented by every change; existing rows start at 1
+ALTER TABLE headquarters ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,11 @@
+-- Deleted HQs and branches are kept with who deleted them and when, until purged
+ALTER TABLE headquarters
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by TEXT;
+ALTER TABLE branches
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by TEXT;
+
+-- Purge of deleted rows past the retention period
+CREATE INDEX headquarters_deleted_at_idx ON headquarters (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX branches_deleted_at_idx ON branches (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Trigram indexes for search: substring matches on bank name, address and town,
+-- and word_similarity to keep the best candidates first
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX headquarters_bank_name_trgm_idx ON headquarters USING gin (bank_name gin_trgm_ops);
+CREATE INDEX headquarters_address_trgm_idx ON headquarters USING gin (address gin_trgm_ops);
+CREATE INDEX headquarters_town_name_trgm_idx ON headquarters USING gin (town_name gin_trgm_ops);
+CREATE INDEX branches_bank_name_trgm_idx ON branches USING gin (bank_name gin_trgm_ops);
+CREATE INDEX branches_address_trgm_idx ON branches USING gin (address gin_trgm_ops);
+CREATE INDEX branches_town_name_trgm_idx ON branches USING gin (town_name gin_trgm_ops);
@@ -2,26 +2,55 @@ package persistence
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
 )
 
+// mongoSearchIndex is the name of the text index over bank names, addresses and towns.
+// A collection has at most one text index, so when its fields change it gets a new name
+// and the old one is dropped in NewMongoRepository.
+const mongoSearchIndex = "search_text_v2"
+
+// staleSearchIndexes are text indexes created by older versions
+var staleSearchIndexes = []string{"search_text"}
+
+// DefaultMongoBatchSize is how many writes SaveHeadquarters and SaveBranches send in one bulk write
+const DefaultMongoBatchSize = 1000
+
 // MongoRepository implements port.SwiftRepository for MongoDB
 type MongoRepository struct {
 	client     *mongo.Client
 	collection *mongo.Collection
+	batchSize  int
+}
+
+// MongoOption configures MongoRepository
+type MongoOption func(*MongoRepository)
+
+// WithBatchSize sets how many writes are sent in one bulk write, values below 1 keep the default
+func WithBatchSize(n int) MongoOption {
+	return func(r *MongoRepository) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
 }
 
 // NewMongoRepository creates connection with MongoDB and inits collection
-func NewMongoRepository(uri, dbName, collName string) (port.SwiftRepository, error) {
+func NewMongoRepository(uri, dbName, collName string, opts ...MongoOption) (port.SwiftRepository, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -32,6 +61,15 @@ func NewMongoRepository(uri, dbName, collName string) (port.SwiftRepository, err
 	}
 
 	coll := client.Database(dbName).Collection(collName)
+	// documents saved before versions were added start at version 1
+	if _, err := coll.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}}); err != nil {
+		return nil, err
+	}
+	for _, name := range staleSearchIndexes {
+		if err := dropIndexIfExists(ctx, coll, name); err != nil {
+			return nil, err
+		}
+	}
 	// Indexes
 	_, err = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
@@ -42,66 +80,216 @@ func NewMongoRepository(uri, dbName, collName string) (port.SwiftRepository, err
 			Keys:    bson.D{{Key: "countryISO2", Value: 1}},
 			Options: options.Index().SetBackground(true),
 		},
+		{
+			// used by Purge, only deleted HQs and HQs with deleted branches are indexed
+			Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"deletedAt": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "deletedBranches.deletedAt", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"deletedBranches": bson.M{"$exists": true}}),
+		},
+		{
+			// used by Search; "none" keeps words unstemmed, bank names aren't English prose
+			Keys: bson.D{
+				{Key: "bankName", Value: "text"},
+				{Key: "address", Value: "text"},
+				{Key: "townName", Value: "text"},
+				{Key: "branches.bankName", Value: "text"},
+				{Key: "branches.address", Value: "text"},
+				{Key: "branches.townName", Value: "text"},
+			},
+			Options: options.Index().
+				SetName(mongoSearchIndex).
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{
+					{Key: "bankName", Value: 2},
+					{Key: "branches.bankName", Value: 2},
+					{Key: "address", Value: 1},
+					{Key: "branches.address", Value: 1},
+					{Key: "townName", Value: 1},
+					{Key: "branches.townName", Value: 1},
+				}),
+		},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &MongoRepository{client: client, collection: coll}, nil
+	repo := &MongoRepository{client: client, collection: coll, batchSize: DefaultMongoBatchSize}
+	for _, opt := range opts {
+		opt(repo)
+	}
+	return repo, nil
 
 }
 
-// SaveHeadquarters
+// dropIndexIfExists drops the named index, a missing index or collection is not an error
+func dropIndexIfExists(ctx context.Context, coll *mongo.Collection, name string) error {
+	_, err := coll.Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+		return nil
+	}
+	return err
+}
+
+// SaveHeadquarters inserts HQs that don't exist yet and replaces deleted ones with unordered bulk upserts
+// (see saveHeadquarterUpdate), live ones are skipped
 func (r *MongoRepository) SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
 	var summary models.ImportSummary
-	for _, hq := range hqs {
-		filter := bson.M{"swiftCode": hq.SwiftCode}
-		update := bson.M{"$setOnInsert": hq}
-		opts := options.Update().SetUpsert(true)
-		res, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	for start := 0; start < len(hqs); start += r.batchSize {
+		batch := hqs[start:min(start+r.batchSize, len(hqs))]
+
+		// upserts of one code in the same unordered batch would race, send it once
+		seen := make(map[string]bool, len(batch))
+		writes := make([]mongo.WriteModel, 0, len(batch))
+		for _, hq := range batch {
+			if seen[hq.SwiftCode] {
+				summary.HQSkipped++
+				continue
+			}
+			seen[hq.SwiftCode] = true
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"swiftCode": hq.SwiftCode}).
+				SetUpdate(saveHeadquarterUpdate(hq)).
+				SetUpsert(true))
+		}
+
+		res, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		// a duplicate key error means another writer inserted the HQ first
+		dups, err := duplicateKeyWrites(err)
 		if err != nil {
 			return summary, err
 		}
-		if res.MatchedCount == 0 {
-			summary.HQAdded++
-		} else {
-			summary.HQSkipped++
-		}
+		// a replaced HQ is modified, a live one matched but left as it was
+		summary.HQAdded += int(res.UpsertedCount + res.ModifiedCount)
+		summary.HQSkipped += int(res.MatchedCount-res.ModifiedCount) + dups
 	}
 	return summary, nil
 }
 
-// SaveBranches add branches, checking if HQ exists
+// SaveBranches add branches with unordered bulk updates (see addBranchUpdate), checking if HQ exists
 func (r *MongoRepository) SaveBranches(ctx context.Context, branches []models.SwiftCode) (models.ImportSummary, error) {
 	var summary models.ImportSummary
-	for _, br := range branches {
-		// get HQ with prefix of 8 characters
-		hqCode := strings.ToUpper(br.SwiftCode[:8] + "XXX")
-		filter := bson.M{"swiftCode": hqCode}
-		// add uniqie
-		update := bson.M{"$addToSet": bson.M{"branches": models.SwiftBranch{
-			SwiftCode:     br.SwiftCode,
-			BankName:      br.BankName,
-			Address:       br.Address,
-			CountryISO2:   br.CountryISO2,
-			IsHeadquarter: false,
-		}}}
-		res, err := r.collection.UpdateOne(ctx, filter, update)
+	for start := 0; start < len(branches); start += r.batchSize {
+		batch := branches[start:min(start+r.batchSize, len(branches))]
+
+		writes := make([]mongo.WriteModel, 0, len(batch))
+		for _, br := range batch {
+			// get HQ with prefix of 8 characters
+			hqCode := strings.ToUpper(br.SwiftCode[:8] + "XXX")
+			// add uniqie
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"swiftCode": hqCode, "deletedAt": nil}).
+				SetUpdate(addBranchUpdate(models.SwiftBranch{
+					SwiftCode:     br.SwiftCode,
+					BankName:      br.BankName,
+					Address:       br.Address,
+					TownName:      br.TownName,
+					CodeType:      br.CodeType,
+					TimeZone:      br.TimeZone,
+					CountryISO2:   br.CountryISO2,
+					IsHeadquarter: false,
+				})))
+		}
+
+		res, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
 		if err != nil {
 			return summary, err
 		}
-		switch {
-		case res.MatchedCount == 0:
-			summary.BranchesMissingHQ++
-		case res.ModifiedCount == 0:
-			summary.BranchesDuplicate++
-		default:
-			summary.BranchesAdded++
-		}
+		// every update matches at most one HQ: unmatched ones have no HQ,
+		// matched but unmodified ones were already in the set
+		summary.BranchesAdded += int(res.ModifiedCount)
+		summary.BranchesDuplicate += int(res.MatchedCount - res.ModifiedCount)
+		summary.BranchesMissingHQ += len(batch) - int(res.MatchedCount)
 	}
 	return summary, nil
 }
 
+// saveHeadquarterUpdate is an upsert pipeline that stores hq in place of a missing or deleted HQ
+// (dropping the branches deleted together with it) and leaves a live one as it is, so it counts
+// as not modified; the version goes on from the deleted one so ETags from before the delete don't match
+func saveHeadquarterUpdate(hq models.SwiftCode) mongo.Pipeline {
+	// an upserted document only has the filter fields, no version
+	replace := bson.M{"$or": bson.A{
+		bson.M{"$eq": bson.A{bson.M{"$type": "$version"}, "missing"}},
+		bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$deletedAt", nil}}, nil}},
+	}}
+	hq.Version = 0
+	saved := bson.M{"$mergeObjects": bson.A{
+		bson.M{"$literal": hq},
+		bson.M{"_id": "$_id", "version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}}},
+	}}
+	return mongo.Pipeline{{{Key: "$replaceWith", Value: bson.M{"$cond": bson.A{replace, saved, "$$ROOT"}}}}}
+}
+
+// addBranchUpdate is an update pipeline that appends br unless a branch with its code is embedded
+// already, whatever its details, replacing a deleted branch with the code, and increments
+// the version only when br was added, so an unchanged document still counts as not modified
+func addBranchUpdate(br models.SwiftBranch) mongo.Pipeline {
+	branches := bson.M{"$ifNull": bson.A{"$branches", bson.A{}}}
+	exists := bson.M{"$in": bson.A{br.SwiftCode, bson.M{"$ifNull": bson.A{"$branches.swiftCode", bson.A{}}}}}
+	otherDeleted := bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$deletedBranches", bson.A{}}},
+		"as":    "b",
+		"cond":  bson.M{"$ne": bson.A{"$$b.swiftCode", br.SwiftCode}},
+	}}
+	return mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"branches": bson.M{"$cond": bson.A{exists, "$branches",
+			bson.M{"$concatArrays": bson.A{branches, bson.A{bson.M{"$literal": br}}}}}},
+		"deletedBranches": bson.M{"$cond": bson.A{exists, "$deletedBranches", otherDeleted}},
+		"version":         bson.M{"$cond": bson.A{exists, "$version", bson.M{"$add": bson.A{"$version", 1}}}},
+	}}}}
+}
+
+// hideDeletedBranches projects HQ documents for readers, deleted branches stay in the database
+var hideDeletedBranches = bson.M{"deletedBranches": 0}
+
+// withVersion adds the expected document version to filter, 0 matches any version
+func withVersion(filter bson.M, version int64) bson.M {
+	if version == 0 {
+		return filter
+	}
+	versioned := bson.M{"version": version}
+	for k, v := range filter {
+		versioned[k] = v
+	}
+	return versioned
+}
+
+// missingOrChanged tells why a write with withVersion(filter, version) matched nothing
+func (r *MongoRepository) missingOrChanged(ctx context.Context, filter bson.M, version int64) error {
+	if version == 0 {
+		return port.ErrNotFound
+	}
+	n, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return port.ErrNotFound
+	}
+	return port.ErrVersionMismatch
+}
+
+// duplicateKeyWrites counts duplicate key errors of a bulk write, any other error is returned
+func duplicateKeyWrites(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil {
+		return 0, err
+	}
+	for _, we := range bwe.WriteErrors {
+		if we.Code != 11000 {
+			return 0, err
+		}
+	}
+	return len(bwe.WriteErrors), nil
+}
+
 // GetByCode gets SwiftCode (HQ or branch) by code
 func (r *MongoRepository) GetByCode(ctx context.Context, code string) (models.SwiftCode, error) {
 	// Fetch the HQ document that either has swiftCode == code OR contains the branch
@@ -110,9 +298,10 @@ func (r *MongoRepository) GetByCode(ctx context.Context, code string) (models.Sw
 			{"swiftCode": code},
 			{"branches.swiftCode": code},
 		},
+		"deletedAt": nil,
 	}
 	var doc models.SwiftCode
-	err := r.collection.FindOne(ctx, filter).Decode(&doc)
+	err := r.collection.FindOne(ctx, filter, options.FindOne().SetProjection(hideDeletedBranches)).Decode(&doc)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return models.SwiftCode{}, port.ErrNotFound
@@ -132,9 +321,13 @@ func (r *MongoRepository) GetByCode(ctx context.Context, code string) (models.Sw
 				SwiftCode:     br.SwiftCode,
 				BankName:      br.BankName,
 				Address:       br.Address,
+				TownName:      br.TownName,
+				CodeType:      br.CodeType,
+				TimeZone:      br.TimeZone,
 				CountryISO2:   doc.CountryISO2,
 				CountryName:   doc.CountryName,
 				IsHeadquarter: false,
+				Version:       doc.Version,
 				// omit Branches slice entirely
 			}, nil
 		}
@@ -144,51 +337,277 @@ func (r *MongoRepository) GetByCode(ctx context.Context, code string) (models.Sw
 	return models.SwiftCode{}, port.ErrNotFound
 }
 
-// GetByCountry gets all code (HQ and branches) for a country
-func (r *MongoRepository) GetByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"countryISO2": iso2})
+// GetByCodes fetches the HQ documents of all codes with a single query
+func (r *MongoRepository) GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error) {
+	found := make(map[string]models.SwiftCode, len(codes))
+	if len(codes) == 0 {
+		return found, nil
+	}
+	filter := bson.M{
+		"$or": []bson.M{
+			{"swiftCode": bson.M{"$in": codes}},
+			{"branches.swiftCode": bson.M{"$in": codes}},
+		},
+		"deletedAt": nil,
+	}
+	// the first HQ by _id wins a branch code embedded twice, as with FindOne
+	opts := options.Find().SetProjection(hideDeletedBranches).SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var results []models.SwiftCode
+	wanted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		wanted[code] = true
+	}
 	for cursor.Next(ctx) {
-		var hq models.SwiftCode
-		if err := cursor.Decode(&hq); err != nil {
+		var doc models.SwiftCode
+		if err := cursor.Decode(&doc); err != nil {
 			return nil, err
 		}
-		// add HQ
-		results = append(results, hq)
-		// add branches
-		for _, br := range hq.Branches {
-			results = append(results, models.SwiftCode{
+		if wanted[doc.SwiftCode] {
+			found[doc.SwiftCode] = doc
+		}
+		for _, br := range doc.Branches {
+			if _, seen := found[br.SwiftCode]; !wanted[br.SwiftCode] || seen {
+				continue
+			}
+			found[br.SwiftCode] = models.SwiftCode{
 				SwiftCode:     br.SwiftCode,
 				BankName:      br.BankName,
 				Address:       br.Address,
-				CountryISO2:   br.CountryISO2,
-				CountryName:   hq.CountryName,
+				TownName:      br.TownName,
+				CodeType:      br.CodeType,
+				TimeZone:      br.TimeZone,
+				CountryISO2:   doc.CountryISO2,
+				CountryName:   doc.CountryName,
 				IsHeadquarter: false,
-			})
+				Version:       doc.Version,
+			}
 		}
 	}
-	if len(results) == 0 {
-		return nil, port.ErrNotFound
+	return found, cursor.Err()
+}
+
+// List returns HQs and branches matching the query, ordered by SWIFT code.
+// Filtering, sorting and the limit run in the aggregation pipeline, so only one page is loaded.
+func (r *MongoRepository) List(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	// document level prefilter, so HQs that can't contain a match are skipped before $unwind
+	docMatch := bson.A{bson.M{"deletedAt": nil}}
+	entryMatch := bson.M{}
+	if q.After != "" {
+		// all codes of a document start with the 8 characters of its HQ code, so a document
+		// with codes after q.After has an HQ code from its first 8 characters on (index range)
+		docMatch = append(docMatch, bson.M{"swiftCode": bson.M{"$gte": q.After[:min(len(q.After), 8)]}})
+		entryMatch["swiftCode"] = bson.M{"$gt": q.After}
+	}
+	if q.CountryISO2 != "" {
+		docMatch = append(docMatch, entryFieldMatch("countryISO2", q.CountryISO2, q.IsHeadquarter))
+		entryMatch["countryISO2"] = q.CountryISO2
+	}
+	if q.IsHeadquarter != nil {
+		if !*q.IsHeadquarter {
+			docMatch = append(docMatch, bson.M{"branches.0": bson.M{"$exists": true}})
+		}
+		entryMatch["isHeadquarter"] = *q.IsHeadquarter
+	}
+	if q.BankNamePrefix != "" {
+		re := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(q.BankNamePrefix), Options: "i"}
+		docMatch = append(docMatch, entryFieldMatch("bankName", re, q.IsHeadquarter))
+		entryMatch["bankName"] = re
+	}
+	if q.Town != "" {
+		re := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(q.Town) + "$", Options: "i"}
+		docMatch = append(docMatch, entryFieldMatch("townName", re, q.IsHeadquarter))
+		entryMatch["townName"] = re
+	}
+	if q.TimeZone != "" {
+		docMatch = append(docMatch, entryFieldMatch("timeZone", q.TimeZone, q.IsHeadquarter))
+		entryMatch["timeZone"] = q.TimeZone
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"$and": docMatch}}},
+	}
+	pipeline = append(pipeline,
+		// one entry per HQ and per embedded branch
+		bson.D{{Key: "$project", Value: bson.M{"entries": bson.M{"$concatArrays": bson.A{
+			bson.A{bson.M{
+				"swiftCode":     "$swiftCode",
+				"bankName":      "$bankName",
+				"address":       "$address",
+				"countryISO2":   "$countryISO2",
+				"countryName":   "$countryName",
+				"isHeadquarter": "$isHeadquarter",
+				"townName":      "$townName",
+				"codeType":      "$codeType",
+				"timeZone":      "$timeZone",
+			}},
+			bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$branches", bson.A{}}},
+				"as":    "b",
+				"in": bson.M{
+					"swiftCode":     "$$b.swiftCode",
+					"bankName":      "$$b.bankName",
+					"address":       "$$b.address",
+					"countryISO2":   "$$b.countryISO2",
+					"countryName":   "$countryName",
+					"isHeadquarter": "$$b.isHeadquarter",
+					"townName":      "$$b.townName",
+					"codeType":      "$$b.codeType",
+					"timeZone":      "$$b.timeZone",
+				},
+			}},
+		}}}}},
+		bson.D{{Key: "$unwind", Value: "$entries"}},
+		bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$entries"}}},
+		bson.D{{Key: "$match", Value: entryMatch}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "swiftCode", Value: 1}}}},
+	)
+	if q.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: q.Limit}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
 	}
-	return results, nil
+	defer cursor.Close(ctx)
 
+	results := []models.SwiftCode{}
+	for cursor.Next(ctx) {
+		var sc models.SwiftCode
+		if err := cursor.Decode(&sc); err != nil {
+			return nil, err
+		}
+		results = append(results, sc)
+	}
+	return results, cursor.Err()
+}
+
+// Walk reads live HQ documents in code order through the swiftCode index; all codes of a document
+// share the 8 character prefix of its HQ code, so its sorted entries keep the order across documents
+func (r *MongoRepository) Walk(ctx context.Context, fn func(models.SwiftCode) error) error {
+	opts := options.Find().SetSort(bson.M{"swiftCode": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"deletedAt": nil}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var hq models.SwiftCode
+		if err := cursor.Decode(&hq); err != nil {
+			return err
+		}
+		entries := flatten(hq)
+		sort.Slice(entries, func(i, j int) bool { return entries[i].SwiftCode < entries[j].SwiftCode })
+		for _, sc := range entries {
+			if err := fn(sc); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// entryFieldMatch matches documents whose HQ or one of its branches has field matching value,
+// only the HQ or only the branches when isHQ is set
+func entryFieldMatch(field string, value interface{}, isHQ *bool) bson.M {
+	switch {
+	case isHQ == nil:
+		return bson.M{"$or": bson.A{bson.M{field: value}, bson.M{"branches." + field: value}}}
+	case *isHQ:
+		return bson.M{field: value}
+	default:
+		return bson.M{"branches." + field: value}
+	}
+}
+
+// Search takes the best scored candidate HQ documents from the text index, which only matches whole words,
+// and when that finds too few, every document matching a regex on word prefixes so prefixes and typos
+// still match. HQ and branch entries of the candidates are scored in Go, keeping only the best ones.
+func (r *MongoRepository) Search(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error) {
+	terms := util.SearchTerms(query)
+	if len(terms) == 0 {
+		return []models.SwiftCodeSearchHit{}, nil
+	}
+
+	seen := make(map[string]bool)
+	var entries []models.SwiftCode
+	// prune drops entries outside the best limit matches, so a long scan stays small
+	prune := func() {
+		if limit <= 0 {
+			return
+		}
+		best := make(map[string]bool)
+		for _, h := range rankSearchHits(entries, terms, limit) {
+			best[h.SwiftCode] = true
+		}
+		kept := entries[:0]
+		for _, sc := range entries {
+			if best[sc.SwiftCode] {
+				kept = append(kept, sc)
+			}
+		}
+		entries = kept
+	}
+	load := func(filter bson.M, opts *options.FindOptions) error {
+		cursor, err := r.collection.Find(ctx, filter, opts)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+		for n := 1; cursor.Next(ctx); n++ {
+			var hq models.SwiftCode
+			if err := cursor.Decode(&hq); err != nil {
+				return err
+			}
+			if !seen[hq.SwiftCode] {
+				seen[hq.SwiftCode] = true
+				entries = append(entries, flatten(hq)...)
+			}
+			if n%searchCandidates == 0 {
+				prune()
+			}
+		}
+		return cursor.Err()
+	}
+
+	textOpts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetLimit(searchCandidates)
+	if err := load(bson.M{"$text": bson.M{"$search": strings.Join(terms, " ")}, "deletedAt": nil}, textOpts); err != nil {
+		return nil, err
+	}
+	if hits := rankSearchHits(entries, terms, limit); limit > 0 && len(hits) >= limit {
+		return hits, nil
+	}
+
+	or := bson.A{}
+	for _, prefix := range searchPrefixes(terms) {
+		re := primitive.Regex{Pattern: `\b` + regexp.QuoteMeta(prefix), Options: "i"}
+		for _, field := range []string{"bankName", "address", "townName", "branches.bankName", "branches.address", "branches.townName"} {
+			or = append(or, bson.M{field: re})
+		}
+	}
+	if err := load(bson.M{"$or": or, "deletedAt": nil}, options.Find().SetBatchSize(searchCandidates)); err != nil {
+		return nil, err
+	}
+	return rankSearchHits(entries, terms, limit), nil
 }
 
 // AddBranch add branch to exisitng HQ
 func (r *MongoRepository) AddBranch(ctx context.Context, hqCode string, br models.SwiftBranch) error {
-	filter := bson.M{"swiftCode": hqCode}
-	update := bson.M{"$addToSet": bson.M{"branches": br}}
-	res, err := r.collection.UpdateOne(ctx, filter, update)
+	filter := bson.M{"swiftCode": hqCode, "deletedAt": nil}
+	res, err := r.collection.UpdateOne(ctx, filter, addBranchUpdate(br))
 	if err != nil {
 		return err
 	}
 	if res.MatchedCount == 0 {
-		return port.ErrNotFound
+		return port.ErrHQNotFound
 	}
 	if res.ModifiedCount == 0 {
 		return port.ErrBranchDuplicate
@@ -196,32 +615,171 @@ func (r *MongoRepository) AddBranch(ctx context.Context, hqCode string, br model
 	return nil
 }
 
-// Delete deletes entry by given SWIFT code
-func (r *MongoRepository) Delete(ctx context.Context, code string) error {
+// Update replaces details of an HQ document or of the embedded branch with the code
+func (r *MongoRepository) Update(ctx context.Context, sc models.SwiftCode) error {
+	filter := bson.M{"swiftCode": sc.SwiftCode, "deletedAt": nil}
+	set := bson.M{
+		"bankName":    sc.BankName,
+		"address":     sc.Address,
+		"townName":    sc.TownName,
+		"codeType":    sc.CodeType,
+		"timeZone":    sc.TimeZone,
+		"countryISO2": sc.CountryISO2,
+		"countryName": sc.CountryName,
+	}
+	if !strings.HasSuffix(sc.SwiftCode, "XXX") {
+		// positional update of the matched branch, country name stays with the HQ
+		filter = bson.M{"branches.swiftCode": sc.SwiftCode, "deletedAt": nil}
+		set = bson.M{
+			"branches.$.bankName":    sc.BankName,
+			"branches.$.address":     sc.Address,
+			"branches.$.townName":    sc.TownName,
+			"branches.$.codeType":    sc.CodeType,
+			"branches.$.timeZone":    sc.TimeZone,
+			"branches.$.countryISO2": sc.CountryISO2,
+		}
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	res, err := r.collection.UpdateOne(ctx, withVersion(filter, sc.Version), update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return r.missingOrChanged(ctx, filter, sc.Version)
+	}
+	return nil
+}
+
+// Delete marks the HQ document as deleted, or moves the branch to the deleted branches of its HQ
+func (r *MongoRepository) Delete(ctx context.Context, code string, version int64, actor string) error {
+	now := time.Now().UTC()
+	if strings.HasSuffix(code, "XXX") {
+		// cały HQ i oddziały są ukryte
+		filter := bson.M{"swiftCode": code, "deletedAt": nil}
+		update := bson.M{"$set": bson.M{"deletedAt": now, "deletedBy": actor}, "$inc": bson.M{"version": 1}}
+		res, err := r.collection.UpdateOne(ctx, withVersion(filter, version), update)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return r.missingOrChanged(ctx, filter, version)
+		}
+		return nil
+	}
+	// branch only, one $set stage sees the branches before the move
+	filter := bson.M{"branches.swiftCode": code, "deletedAt": nil}
+	isCode := bson.M{"$eq": bson.A{"$$b.swiftCode", code}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"deletedBranches": bson.M{"$concatArrays": bson.A{
+			bson.M{"$ifNull": bson.A{"$deletedBranches", bson.A{}}},
+			bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{"input": "$branches", "as": "b", "cond": isCode}},
+				"as":    "b",
+				"in":    bson.M{"$mergeObjects": bson.A{"$$b", bson.M{"deletedAt": now, "deletedBy": actor}}},
+			}},
+		}},
+		"branches": bson.M{"$filter": bson.M{"input": "$branches", "as": "b", "cond": bson.M{"$not": bson.A{isCode}}}},
+		"version":  bson.M{"$add": bson.A{"$version", 1}},
+	}}}}
+	res, err := r.collection.UpdateOne(ctx, withVersion(filter, version), update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return r.missingOrChanged(ctx, filter, version)
+	}
+	return nil
+}
+
+// Restore clears the deleted mark of an HQ document or moves a deleted branch back to its branches
+func (r *MongoRepository) Restore(ctx context.Context, code string) error {
 	if strings.HasSuffix(code, "XXX") {
-		// usuń cały HQ i oddziały
-		res, err := r.collection.DeleteOne(ctx, bson.M{"swiftCode": code})
+		res, err := r.collection.UpdateOne(ctx,
+			bson.M{"swiftCode": code, "deletedAt": bson.M{"$ne": nil}},
+			bson.M{"$unset": bson.M{"deletedAt": "", "deletedBy": ""}, "$inc": bson.M{"version": 1}})
 		if err != nil {
 			return err
 		}
-		if res.DeletedCount == 0 {
+		if res.MatchedCount == 0 {
 			return port.ErrNotFound
 		}
 		return nil
 	}
-	// remove branch onlu
-	filter := bson.M{"branches.swiftCode": code}
-	update := bson.M{"$pull": bson.M{"branches": bson.M{"swiftCode": code}}}
-	res, err := r.collection.UpdateOne(ctx, filter, update)
+
+	var hq models.SwiftCode
+	err := r.collection.FindOne(ctx, bson.M{"deletedBranches.swiftCode": code},
+		options.FindOne().SetProjection(bson.M{"deletedAt": 1})).Decode(&hq)
+	if err == mongo.ErrNoDocuments {
+		return port.ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if res.ModifiedCount == 0 {
+	if hq.DeletedAt != nil {
+		return port.ErrHQNotFound
+	}
+
+	isCode := bson.M{"$eq": bson.A{"$$b.swiftCode", code}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"branches": bson.M{"$concatArrays": bson.A{
+			bson.M{"$ifNull": bson.A{"$branches", bson.A{}}},
+			bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{"input": "$deletedBranches", "as": "b", "cond": isCode}},
+				"as":    "b",
+				"in": bson.M{"$unsetField": bson.M{"field": "deletedAt",
+					"input": bson.M{"$unsetField": bson.M{"field": "deletedBy", "input": "$$b"}}}},
+			}},
+		}},
+		"deletedBranches": bson.M{"$filter": bson.M{"input": "$deletedBranches", "as": "b", "cond": bson.M{"$not": bson.A{isCode}}}},
+		"version":         bson.M{"$add": bson.A{"$version", 1}},
+	}}}}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"deletedBranches.swiftCode": code, "deletedAt": nil}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		// restored or its HQ deleted meanwhile
 		return port.ErrNotFound
 	}
 	return nil
 }
 
+// Purge deletes HQ documents deleted before the given time and pulls older deleted branches
+func (r *MongoRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	old := bson.M{"$lt": before}
+	// $pull reports documents, not branches, so they are counted first
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"deletedBranches.deletedAt": old}}},
+		{{Key: "$unwind", Value: "$deletedBranches"}},
+		{{Key: "$match", Value: bson.M{"deletedBranches.deletedAt": old}}},
+		{{Key: "$count", Value: "n"}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var counts []struct {
+		N int `bson:"n"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return 0, err
+	}
+	purged := 0
+	if len(counts) > 0 {
+		purged = counts[0].N
+	}
+
+	if _, err := r.collection.UpdateMany(ctx,
+		bson.M{"deletedBranches.deletedAt": old},
+		bson.M{"$pull": bson.M{"deletedBranches": bson.M{"deletedAt": old}}}); err != nil {
+		return purged, err
+	}
+	res, err := r.collection.DeleteMany(ctx, bson.M{"deletedAt": old})
+	if err != nil {
+		return purged, err
+	}
+	return purged + int(res.DeletedCount), nil
+}
+
 func (r *MongoRepository) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx, readpref.Primary())
 }
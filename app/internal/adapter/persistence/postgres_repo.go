@@ -0,0 +1,689 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// postgresMigrationLock is the advisory lock key guarding schema migrations between replicas
+const postgresMigrationLock = 9362
+
+// PostgresRepository implements port.SwiftRepository for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository connects to PostgreSQL and applies pending schema migrations
+func NewPostgresRepository(dsn string) (port.SwiftRepository, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migratePostgres(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate schema: %w", err)
+	}
+	return &PostgresRepository{db: db}, nil
+}
+
+// migratePostgres applies embedded migrations not yet recorded in schema_migrations, in file name order
+func migratePostgres(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresMigrationLock); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, postgresMigrationLock)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return err
+	}
+
+	files, err := fs.Glob(postgresMigrations, "migrations/postgres/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		version := strings.TrimSuffix(file[strings.LastIndex(file, "/")+1:], ".sql")
+
+		var applied bool
+		err := conn.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		stmt, err := postgresMigrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(stmt)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveHeadquarters inserts HQs that don't exist yet and replaces deleted ones, live ones are skipped
+func (r *PostgresRepository) SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
+	var summary models.ImportSummary
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, hq := range hqs {
+			// a deleted HQ goes with its branches, the version goes on so ETags from before the delete don't match
+			var version int64
+			err := tx.QueryRowContext(ctx, `
+				DELETE FROM headquarters WHERE swift_code = $1 AND deleted_at IS NOT NULL
+				RETURNING version`, hq.SwiftCode,
+			).Scan(&version)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+
+			var id int64
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO headquarters (swift_code, bank_name, address, town_name, code_type, time_zone, country_iso2, country_name, version)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (swift_code) DO NOTHING
+				RETURNING id`,
+				hq.SwiftCode, hq.BankName, hq.Address, hq.TownName, hq.CodeType, hq.TimeZone, hq.CountryISO2, hq.CountryName, version+1,
+			).Scan(&id)
+			if err == sql.ErrNoRows {
+				summary.HQSkipped++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			for _, br := range hq.Branches {
+				if _, err := insertBranch(ctx, tx, id, br); err != nil {
+					return err
+				}
+			}
+			summary.HQAdded++
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	return summary, nil
+}
+
+// SaveBranches adds branches in one transaction, checking if HQ exists
+func (r *PostgresRepository) SaveBranches(ctx context.Context, branches []models.SwiftCode) (models.ImportSummary, error) {
+	var summary models.ImportSummary
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, br := range branches {
+			// get HQ with prefix of 8 characters
+			hqCode := strings.ToUpper(br.SwiftCode[:8] + "XXX")
+			id, err := headquarterID(ctx, tx, hqCode)
+			if err == port.ErrNotFound {
+				summary.BranchesMissingHQ++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			added, err := insertBranch(ctx, tx, id, models.SwiftBranch{
+				SwiftCode:   br.SwiftCode,
+				BankName:    br.BankName,
+				Address:     br.Address,
+				TownName:    br.TownName,
+				CodeType:    br.CodeType,
+				TimeZone:    br.TimeZone,
+				CountryISO2: br.CountryISO2,
+			})
+			if err != nil {
+				return err
+			}
+			if !added {
+				summary.BranchesDuplicate++
+				continue
+			}
+			if err := bumpVersion(ctx, tx, id); err != nil {
+				return err
+			}
+			summary.BranchesAdded++
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	return summary, nil
+}
+
+// GetByCode gets SwiftCode (HQ or branch) by code
+func (r *PostgresRepository) GetByCode(ctx context.Context, code string) (models.SwiftCode, error) {
+	var id int64
+	hq := models.SwiftCode{IsHeadquarter: true}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, swift_code, bank_name, address, town_name, code_type, time_zone, country_iso2, country_name, version
+		FROM headquarters WHERE swift_code = $1 AND deleted_at IS NULL`, code,
+	).Scan(&id, &hq.SwiftCode, &hq.BankName, &hq.Address, &hq.TownName, &hq.CodeType, &hq.TimeZone, &hq.CountryISO2, &hq.CountryName, &hq.Version)
+	if err == nil {
+		branches, err := r.branchesOf(ctx, `b.headquarter_id = $1`, id)
+		if err != nil {
+			return models.SwiftCode{}, err
+		}
+		hq.Branches = branches[id]
+		return hq, nil
+	}
+	if err != sql.ErrNoRows {
+		return models.SwiftCode{}, err
+	}
+
+	// not an HQ, look for a branch and take country from its HQ
+	var br models.SwiftCode
+	err = r.db.QueryRowContext(ctx, `
+		SELECT b.swift_code, b.bank_name, b.address, b.town_name, b.code_type, b.time_zone, h.country_iso2, h.country_name, h.version
+		FROM branches b JOIN headquarters h ON h.id = b.headquarter_id
+		WHERE b.swift_code = $1 AND b.deleted_at IS NULL AND h.deleted_at IS NULL`, code,
+	).Scan(&br.SwiftCode, &br.BankName, &br.Address, &br.TownName, &br.CodeType, &br.TimeZone, &br.CountryISO2, &br.CountryName, &br.Version)
+	if err == sql.ErrNoRows {
+		return models.SwiftCode{}, port.ErrNotFound
+	}
+	if err != nil {
+		return models.SwiftCode{}, err
+	}
+	return br, nil
+}
+
+// GetByCodes looks the HQs and the branches up with a query each, and the branches of found HQs with one more
+func (r *PostgresRepository) GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error) {
+	found := make(map[string]models.SwiftCode, len(codes))
+	if len(codes) == 0 {
+		return found, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, swift_code, bank_name, address, town_name, code_type, time_zone, country_iso2, country_name, version
+		FROM headquarters WHERE swift_code = ANY($1) AND deleted_at IS NULL`, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	hqs := make(map[int64]models.SwiftCode)
+	for rows.Next() {
+		var id int64
+		hq := models.SwiftCode{IsHeadquarter: true}
+		if err := rows.Scan(&id, &hq.SwiftCode, &hq.BankName, &hq.Address, &hq.TownName, &hq.CodeType, &hq.TimeZone, &hq.CountryISO2, &hq.CountryName, &hq.Version); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		hqs[id] = hq
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		branches, err := r.branchesOf(ctx, `b.headquarter_id = ANY($1)`, pq.Array(ids))
+		if err != nil {
+			return nil, err
+		}
+		for id, hq := range hqs {
+			hq.Branches = branches[id]
+			found[hq.SwiftCode] = hq
+		}
+	}
+
+	// the codes that aren't HQs may be branches, they take country from their HQ
+	brRows, err := r.db.QueryContext(ctx, `
+		SELECT b.swift_code, b.bank_name, b.address, b.town_name, b.code_type, b.time_zone, h.country_iso2, h.country_name, h.version
+		FROM branches b JOIN headquarters h ON h.id = b.headquarter_id
+		WHERE b.swift_code = ANY($1) AND b.deleted_at IS NULL AND h.deleted_at IS NULL`, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer brRows.Close()
+	for brRows.Next() {
+		var br models.SwiftCode
+		if err := brRows.Scan(&br.SwiftCode, &br.BankName, &br.Address, &br.TownName, &br.CodeType, &br.TimeZone, &br.CountryISO2, &br.CountryName, &br.Version); err != nil {
+			return nil, err
+		}
+		found[br.SwiftCode] = br
+	}
+	return found, brRows.Err()
+}
+
+// listEntries flattens live HQs and branches into one relation, branches take country name from their HQ
+const listEntries = `
+	SELECT h.swift_code, h.bank_name, h.address, h.town_name, h.code_type, h.time_zone, h.country_iso2, h.country_name, TRUE AS is_headquarter
+	FROM headquarters h
+	WHERE h.deleted_at IS NULL
+	UNION ALL
+	SELECT b.swift_code, b.bank_name, b.address, b.town_name, b.code_type, b.time_zone, b.country_iso2, h.country_name, FALSE AS is_headquarter
+	FROM branches b JOIN headquarters h ON h.id = b.headquarter_id
+	WHERE b.deleted_at IS NULL AND h.deleted_at IS NULL`
+
+// entryColumns are the listEntries columns read by scanEntries
+const entryColumns = `swift_code, bank_name, address, town_name, code_type, time_zone, country_iso2, country_name, is_headquarter`
+
+// List returns HQs and branches matching the query, ordered by SWIFT code
+func (r *PostgresRepository) List(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if q.After != "" {
+		where = append(where, "swift_code > "+arg(q.After))
+	}
+	if q.CountryISO2 != "" {
+		where = append(where, "country_iso2 = "+arg(q.CountryISO2))
+	}
+	if q.IsHeadquarter != nil {
+		where = append(where, "is_headquarter = "+arg(*q.IsHeadquarter))
+	}
+	if q.BankNamePrefix != "" {
+		where = append(where, "lower(bank_name) LIKE "+arg(strings.ToLower(escapeLike(q.BankNamePrefix))+"%"))
+	}
+	if q.Town != "" {
+		where = append(where, "lower(town_name) = "+arg(strings.ToLower(q.Town)))
+	}
+	if q.TimeZone != "" {
+		where = append(where, "time_zone = "+arg(q.TimeZone))
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM (` + listEntries + `) e`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY swift_code`
+	if q.Limit > 0 {
+		query += ` LIMIT ` + arg(q.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanEntries(rows)
+}
+
+// Walk streams the rows of one ordered query, fn runs while the query holds its connection
+func (r *PostgresRepository) Walk(ctx context.Context, fn func(models.SwiftCode) error) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+entryColumns+` FROM (`+listEntries+`) e ORDER BY swift_code`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		sc, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(sc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Search loads entries containing one of the query term prefixes, the most similar first, and scores them in Go
+func (r *PostgresRepository) Search(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error) {
+	terms := util.SearchTerms(query)
+	if len(terms) == 0 {
+		return []models.SwiftCodeSearchHit{}, nil
+	}
+	var where []string
+	var args []interface{}
+	for _, prefix := range searchPrefixes(terms) {
+		args = append(args, "%"+escapeLike(prefix)+"%")
+		where = append(where, fmt.Sprintf("bank_name ILIKE $%[1]d OR address ILIKE $%[1]d OR town_name ILIKE $%[1]d", len(args)))
+	}
+	// the candidates most similar to the query come first, weighted like rankSearchHits,
+	// so the cap drops the weakest ones
+	args = append(args, strings.Join(terms, " "))
+	similarity := fmt.Sprintf(`greatest(word_similarity($%[1]d, bank_name), 0.6 * word_similarity($%[1]d, address), 0.6 * word_similarity($%[1]d, town_name))`, len(args))
+	args = append(args, searchCandidates)
+	sqlQuery := `SELECT ` + entryColumns + ` FROM (` + listEntries + `) e
+		WHERE ` + strings.Join(where, " OR ") + `
+		ORDER BY ` + similarity + fmt.Sprintf(` DESC, swift_code LIMIT $%d`, len(args))
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries, err := scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	return rankSearchHits(entries, terms, limit), nil
+}
+
+// AddBranch add branch to existing HQ
+func (r *PostgresRepository) AddBranch(ctx context.Context, hqCode string, br models.SwiftBranch) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := headquarterID(ctx, tx, hqCode)
+		if err == port.ErrNotFound {
+			return port.ErrHQNotFound
+		}
+		if err != nil {
+			return err
+		}
+		added, err := insertBranch(ctx, tx, id, br)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return port.ErrBranchDuplicate
+		}
+		return bumpVersion(ctx, tx, id)
+	})
+}
+
+// Update replaces details of an HQ or branch row and increments the HQ version
+func (r *PostgresRepository) Update(ctx context.Context, sc models.SwiftCode) error {
+	if strings.HasSuffix(sc.SwiftCode, "XXX") {
+		res, err := r.db.ExecContext(ctx, `
+			UPDATE headquarters SET bank_name = $2, address = $3, town_name = $4, code_type = $5, time_zone = $6,
+				country_iso2 = $7, country_name = $8, version = version + 1
+			WHERE swift_code = $1 AND deleted_at IS NULL AND ($9::bigint = 0 OR version = $9)`,
+			sc.SwiftCode, sc.BankName, sc.Address, sc.TownName, sc.CodeType, sc.TimeZone, sc.CountryISO2, sc.CountryName, sc.Version)
+		if err != nil {
+			return err
+		}
+		return r.checkHQWrite(ctx, res, sc.SwiftCode, sc.Version)
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := branchOwner(ctx, tx, sc.SwiftCode, sc.Version)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE branches SET bank_name = $2, address = $3, town_name = $4, code_type = $5, time_zone = $6, country_iso2 = $7
+			WHERE swift_code = $1`,
+			sc.SwiftCode, sc.BankName, sc.Address, sc.TownName, sc.CodeType, sc.TimeZone, sc.CountryISO2); err != nil {
+			return err
+		}
+		return bumpVersion(ctx, tx, id)
+	})
+}
+
+// Delete marks an HQ row (hiding its branches) or a branch row as deleted and increments the HQ version
+func (r *PostgresRepository) Delete(ctx context.Context, code string, version int64, actor string) error {
+	if strings.HasSuffix(code, "XXX") {
+		res, err := r.db.ExecContext(ctx, `
+			UPDATE headquarters SET deleted_at = now(), deleted_by = $3, version = version + 1
+			WHERE swift_code = $1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)`, code, version, actor)
+		if err != nil {
+			return err
+		}
+		return r.checkHQWrite(ctx, res, code, version)
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		id, err := branchOwner(ctx, tx, code, version)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE branches SET deleted_at = now(), deleted_by = $2 WHERE swift_code = $1`, code, actor); err != nil {
+			return err
+		}
+		return bumpVersion(ctx, tx, id)
+	})
+}
+
+// Restore clears the deleted mark of an HQ or branch row and increments the HQ version
+func (r *PostgresRepository) Restore(ctx context.Context, code string) error {
+	if strings.HasSuffix(code, "XXX") {
+		res, err := r.db.ExecContext(ctx, `
+			UPDATE headquarters SET deleted_at = NULL, deleted_by = NULL, version = version + 1
+			WHERE swift_code = $1 AND deleted_at IS NOT NULL`, code)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return port.ErrNotFound
+		}
+		return nil
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var id int64
+		var hqDeleted bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT h.id, h.deleted_at IS NOT NULL FROM branches b JOIN headquarters h ON h.id = b.headquarter_id
+			WHERE b.swift_code = $1 AND b.deleted_at IS NOT NULL FOR UPDATE OF h`, code).Scan(&id, &hqDeleted)
+		if err == sql.ErrNoRows {
+			return port.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if hqDeleted {
+			return port.ErrHQNotFound
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE branches SET deleted_at = NULL, deleted_by = NULL WHERE swift_code = $1`, code); err != nil {
+			return err
+		}
+		return bumpVersion(ctx, tx, id)
+	})
+}
+
+// Purge deletes rows deleted before the given time, branches of a purged HQ cascade
+func (r *PostgresRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			`DELETE FROM branches WHERE deleted_at < $1`,
+			`DELETE FROM headquarters WHERE deleted_at < $1`,
+		} {
+			res, err := tx.ExecContext(ctx, stmt, before)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			purged += int(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// checkHQWrite turns an HQ write that changed no row into ErrNotFound or ErrVersionMismatch
+func (r *PostgresRepository) checkHQWrite(ctx context.Context, res sql.Result, code string, version int64) error {
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return err
+	}
+	if version == 0 {
+		return port.ErrNotFound
+	}
+	var exists bool
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM headquarters WHERE swift_code = $1 AND deleted_at IS NULL)`, code).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return port.ErrNotFound
+	}
+	return port.ErrVersionMismatch
+}
+
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Close closes the connection pool
+func (r *PostgresRepository) Close(ctx context.Context) error {
+	return r.db.Close()
+}
+
+// inTx runs fn in a transaction, rolling back when it fails
+func (r *PostgresRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// branchesOf loads live branches matching where (over aliases b and h) grouped by HQ id
+func (r *PostgresRepository) branchesOf(ctx context.Context, where string, args ...interface{}) (map[int64][]models.SwiftBranch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.headquarter_id, b.swift_code, b.bank_name, b.address, b.town_name, b.code_type, b.time_zone, b.country_iso2, b.country_name
+		FROM branches b JOIN headquarters h ON h.id = b.headquarter_id
+		WHERE b.deleted_at IS NULL AND `+where+` ORDER BY b.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := make(map[int64][]models.SwiftBranch)
+	for rows.Next() {
+		var id int64
+		var br models.SwiftBranch
+		if err := rows.Scan(&id, &br.SwiftCode, &br.BankName, &br.Address, &br.TownName, &br.CodeType, &br.TimeZone, &br.CountryISO2, &br.CountryName); err != nil {
+			return nil, err
+		}
+		branches[id] = append(branches[id], br)
+	}
+	return branches, rows.Err()
+}
+
+// scanEntries reads rows selected with entryColumns
+func scanEntries(rows *sql.Rows) ([]models.SwiftCode, error) {
+	results := []models.SwiftCode{}
+	for rows.Next() {
+		sc, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, sc)
+	}
+	return results, rows.Err()
+}
+
+// scanEntry reads the current entryColumns row
+func scanEntry(rows *sql.Rows) (models.SwiftCode, error) {
+	var sc models.SwiftCode
+	err := rows.Scan(&sc.SwiftCode, &sc.BankName, &sc.Address, &sc.TownName, &sc.CodeType, &sc.TimeZone,
+		&sc.CountryISO2, &sc.CountryName, &sc.IsHeadquarter)
+	return sc, err
+}
+
+// headquarterID returns the row id of live HQ code, locking it until the transaction ends
+func headquarterID(ctx context.Context, tx *sql.Tx, hqCode string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx,
+		`SELECT id FROM headquarters WHERE swift_code = $1 AND deleted_at IS NULL FOR UPDATE`, hqCode).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, port.ErrNotFound
+	}
+	return id, err
+}
+
+// branchOwner locks the live HQ of a live branch until the transaction ends and returns its row id,
+// checking the HQ version unless version is 0
+func branchOwner(ctx context.Context, tx *sql.Tx, branchCode string, version int64) (int64, error) {
+	var id, current int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT h.id, h.version FROM branches b JOIN headquarters h ON h.id = b.headquarter_id
+		WHERE b.swift_code = $1 AND b.deleted_at IS NULL AND h.deleted_at IS NULL
+		FOR UPDATE OF h`, branchCode).Scan(&id, &current)
+	if err == sql.ErrNoRows {
+		return 0, port.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if version != 0 && version != current {
+		return 0, port.ErrVersionMismatch
+	}
+	return id, nil
+}
+
+// bumpVersion increments the version of HQ id after a change to it or its branches
+func bumpVersion(ctx context.Context, tx *sql.Tx, hqID int64) error {
+	_, err := tx.ExecContext(ctx, `UPDATE headquarters SET version = version + 1 WHERE id = $1`, hqID)
+	return err
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// insertBranch inserts branch under HQ id in place of a deleted one, reporting false when the code already exists
+func insertBranch(ctx context.Context, tx *sql.Tx, hqID int64, br models.SwiftBranch) (bool, error) {
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM branches WHERE swift_code = $1 AND deleted_at IS NOT NULL`, br.SwiftCode); err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO branches (headquarter_id, swift_code, bank_name, address, town_name, code_type, time_zone, country_iso2, country_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (swift_code) DO NOTHING`,
+		hqID, br.SwiftCode, br.BankName, br.Address, br.TownName, br.CodeType, br.TimeZone, br.CountryISO2, br.CountryName)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
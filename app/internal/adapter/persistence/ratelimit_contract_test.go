@@ -0,0 +1,55 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+func TestRateLimitStore_Contract(t *testing.T) {
+	runAdapters(t, map[string]func(t *testing.T) port.RateLimitStore{
+		"memory": func(*testing.T) port.RateLimitStore { return NewMemoryRateLimitStore() },
+		"mongo":  openMongoRateLimitStore,
+	}, func(t *testing.T, store port.RateLimitStore) {
+		ctx := context.Background()
+		// long enough that no token comes back while the test runs
+		limit := models.RateLimit{Limit: 3, Period: time.Hour}
+
+		for want := 2; want >= 0; want-- {
+			res, err := store.Take(ctx, "read:ip:10.0.0.1", limit)
+			if err != nil {
+				t.Fatalf("Take failed: %v", err)
+			}
+			if !res.Allowed || res.Remaining != want || res.Limit != 3 {
+				t.Errorf("Take = %+v; want allowed with %d remaining", res, want)
+			}
+		}
+
+		res, err := store.Take(ctx, "read:ip:10.0.0.1", limit)
+		if err != nil {
+			t.Fatalf("Take failed: %v", err)
+		}
+		if res.Allowed || res.Remaining != 0 {
+			t.Errorf("Take on empty bucket = %+v; want refused", res)
+		}
+		// one token comes back every 20 minutes
+		if res.RetryAfter < 19*time.Minute || res.RetryAfter > 20*time.Minute {
+			t.Errorf("RetryAfter = %v; want about 20m", res.RetryAfter)
+		}
+		if res.Reset < 59*time.Minute || res.Reset > time.Hour {
+			t.Errorf("Reset = %v; want about 1h", res.Reset)
+		}
+
+		// buckets are per key
+		res, err = store.Take(ctx, "write:ip:10.0.0.1", limit)
+		if err != nil {
+			t.Fatalf("Take failed: %v", err)
+		}
+		if !res.Allowed || res.Remaining != 2 {
+			t.Errorf("Take on other key = %+v; want a full bucket", res)
+		}
+	})
+}
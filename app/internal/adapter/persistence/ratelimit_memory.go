@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// rateLimitSweepInterval is how often buckets that have filled up again are dropped
+const rateLimitSweepInterval = time.Minute
+
+// MemoryRateLimitStore implements port.RateLimitStore in process memory, every replica counts on its own
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// memoryBucket is the tokens a bucket held at a time, full is when it holds its limit again
+type memoryBucket struct {
+	tokens float64
+	at     time.Time
+	full   time.Time
+}
+
+// NewMemoryRateLimitStore creates empty in-memory buckets
+func NewMemoryRateLimitStore() port.RateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*memoryBucket{}, now: time.Now}
+}
+
+// Take refills the bucket for the time since the last request and removes a token if there is one
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Limit), at: now}
+		s.buckets[key] = b
+	}
+	b.tokens = limit.Refill(b.tokens, now.Sub(b.at))
+	b.at = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	res := limit.Result(allowed, b.tokens)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// sweep drops full buckets, a new bucket starts full anyway
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+func TestMemoryRateLimitStore_Refill(t *testing.T) {
+	store := NewMemoryRateLimitStore().(*MemoryRateLimitStore)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	limit := models.RateLimit{Limit: 2, Period: time.Minute}
+
+	store.Take(ctx, "k", limit)
+	store.Take(ctx, "k", limit)
+	if res, _ := store.Take(ctx, "k", limit); res.Allowed {
+		t.Fatalf("Take on empty bucket = %+v; want refused", res)
+	}
+
+	now = now.Add(30 * time.Second)
+	if res, _ := store.Take(ctx, "k", limit); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("Take after half the period = %+v; want one token back and taken", res)
+	}
+
+	// idle buckets are full again and get dropped
+	now = now.Add(time.Hour)
+	store.Take(ctx, "other", limit)
+	if _, ok := store.buckets["k"]; ok {
+		t.Error("full bucket k kept; want it swept")
+	}
+}
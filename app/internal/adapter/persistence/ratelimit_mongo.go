@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// MongoRateLimitStore implements port.RateLimitStore for MongoDB, so all replicas share the buckets.
+// Each bucket is one document updated atomically, a TTL index removes it once it would be full again.
+type MongoRateLimitStore struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// mongoBucket is the stored state of a bucket
+type mongoBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+// NewMongoRateLimitStore creates connection with MongoDB and the TTL index of the buckets
+func NewMongoRateLimitStore(uri, dbName, collName string) (port.RateLimitStore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	coll := client.Database(dbName).Collection(collName)
+	_, err = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expireAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoRateLimitStore{client: client, collection: coll}, nil
+}
+
+// Take refills and takes from the bucket in a single pipeline update, the same steps as models.RateLimit.Refill.
+// Two replicas creating the same bucket at once race on the upsert, the loser retries.
+func (s *MongoRateLimitStore) Take(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitResult, error) {
+	now := time.Now().UTC()
+	perMilli := float64(limit.Limit) / float64(limit.Period.Milliseconds())
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$min", Value: bson.A{
+				float64(limit.Limit),
+				bson.D{{Key: "$add", Value: bson.A{
+					bson.D{{Key: "$ifNull", Value: bson.A{"$tokens", float64(limit.Limit)}}},
+					bson.D{{Key: "$multiply", Value: bson.A{perMilli, bson.D{{Key: "$max", Value: bson.A{0,
+						bson.D{{Key: "$subtract", Value: bson.A{now, bson.D{{Key: "$ifNull", Value: bson.A{"$at", now}}}}}},
+					}}}}}},
+				}}},
+			}}}},
+			{Key: "at", Value: now},
+		}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "allowed", Value: bson.D{{Key: "$gte", Value: bson.A{"$tokens", 1}}}},
+		}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$cond", Value: bson.A{
+				"$allowed", bson.D{{Key: "$subtract", Value: bson.A{"$tokens", 1}}}, "$tokens",
+			}}}},
+			{Key: "expireAt", Value: now.Add(limit.Period)},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var b mongoBucket
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&b)
+	if mongo.IsDuplicateKeyError(err) {
+		err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&b)
+	}
+	if err != nil {
+		return models.RateLimitResult{}, err
+	}
+	return limit.Result(b.Allowed, b.Tokens), nil
+}
+
+// Close closes MongoDB connection
+func (s *MongoRateLimitStore) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
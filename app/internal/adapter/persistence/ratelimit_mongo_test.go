@@ -0,0 +1,25 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+const testRateLimitCollection = "test_rate_limits"
+
+// openMongoRateLimitStore connects to an empty bucket collection: skips the test if Mongo isn't running
+func openMongoRateLimitStore(t *testing.T) port.RateLimitStore {
+	store, err := NewMongoRateLimitStore(testURI, testDB, testRateLimitCollection)
+	if err != nil {
+		t.Skipf("skipping Mongo tests; cannot connect: %v", err)
+	}
+	s := store.(*MongoRateLimitStore)
+	t.Cleanup(func() { s.Close(context.Background()) })
+	// clean slate, keeping the TTL index
+	s.collection.DeleteMany(context.Background(), bson.M{})
+	return s
+}
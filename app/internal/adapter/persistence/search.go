@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"sort"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// searchCandidates caps how many HQ documents (or rows) an adapter takes, best first, for one search
+// or scores at once
+const searchCandidates = 1000
+
+// rankSearchHits scores flattened entries against the query terms and returns
+// the best limit matches, highest score first (ties by SWIFT code)
+func rankSearchHits(entries []models.SwiftCode, terms []string, limit int) []models.SwiftCodeSearchHit {
+	hits := []models.SwiftCodeSearchHit{}
+	seen := make(map[string]bool)
+	for _, sc := range entries {
+		if seen[sc.SwiftCode] {
+			continue
+		}
+		score := util.SearchScore(terms,
+			util.SearchField{Text: sc.BankName, Weight: 1},
+			util.SearchField{Text: sc.Address, Weight: 0.6},
+			util.SearchField{Text: sc.TownName, Weight: 0.6},
+		)
+		if score == 0 {
+			continue
+		}
+		seen[sc.SwiftCode] = true
+		hits = append(hits, models.SwiftCodeSearchHit{
+			SwiftBranch: models.SwiftBranch{
+				Address:       sc.Address,
+				TownName:      sc.TownName,
+				CodeType:      sc.CodeType,
+				TimeZone:      sc.TimeZone,
+				BankName:      sc.BankName,
+				CountryISO2:   sc.CountryISO2,
+				CountryName:   sc.CountryName,
+				IsHeadquarter: sc.IsHeadquarter,
+				SwiftCode:     sc.SwiftCode,
+			},
+			Score: score,
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].SwiftCode < hits[j].SwiftCode
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// searchPrefixes returns the leading characters of each term that a candidate must contain;
+// typos are tolerated after them, so adapters can pre-filter without a full scan of scores
+func searchPrefixes(terms []string) []string {
+	prefixes := make([]string, 0, len(terms))
+	for _, term := range terms {
+		r := []rune(term)
+		if len(r) > 3 {
+			r = r[:3]
+		}
+		prefixes = append(prefixes, string(r))
+	}
+	return prefixes
+}
@@ -0,0 +1,25 @@
+package models
+
+// Roles granted to API clients, any role also allows reading and admin allows everything
+const (
+	RoleReader   = "reader"
+	RoleEditor   = "editor"
+	RoleImporter = "importer"
+	RoleAdmin    = "admin"
+)
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	switch role {
+	case RoleReader, RoleEditor, RoleImporter, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// APIKey is a client credential, only the hex SHA-256 hash of the key is stored
+type APIKey struct {
+	Name  string   `bson:"name" json:"name"`
+	Hash  string   `bson:"hash" json:"hash"`
+	Roles []string `bson:"roles" json:"roles"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Audited actions
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditImport  = "import"
+	AuditPurge   = "purge"
+)
+
+// AuditEntry records one change: who made it, when, in which request, and the code before and after it.
+// Imports and purges aren't tied to one code, they carry a summary and details instead.
+type AuditEntry struct {
+	Timestamp time.Time      `bson:"timestamp" json:"timestamp"`
+	Action    string         `bson:"action" json:"action"`
+	SwiftCode string         `bson:"swiftCode,omitempty" json:"swiftCode,omitempty"`
+	Actor     string         `bson:"actor" json:"actor"`
+	RequestID string         `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	Before    *SwiftCode     `bson:"before,omitempty" json:"before,omitempty"`
+	After     *SwiftCode     `bson:"after,omitempty" json:"after,omitempty"`
+	Import    *ImportSummary `bson:"import,omitempty" json:"import,omitempty"`
+	Details   string         `bson:"details,omitempty" json:"details,omitempty"`
+}
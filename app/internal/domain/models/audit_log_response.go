@@ -0,0 +1,6 @@
+package models
+
+// AuditLogResponse response structure for GET /v1/audit
+type AuditLogResponse struct {
+	Entries []AuditEntry `json:"entries"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// AuditQuery filters the audit log, entries are returned oldest first
+type AuditQuery struct {
+	SwiftCode string    // exact SWIFT code
+	From      time.Time // entries at or after, zero means no lower bound
+	To        time.Time // entries at or before, zero means no upper bound
+	Limit     int       // maximum number of entries returned
+}
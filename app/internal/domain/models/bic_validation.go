@@ -0,0 +1,43 @@
+package models
+
+// Rules of ISO 9362 a SWIFT code (BIC) can break
+const (
+	BICRuleLength         = "length"
+	BICRuleInstitution    = "institutionCode"
+	BICRuleCountry        = "countryCode"
+	BICRuleUnknownCountry = "unknownCountry"
+	BICRuleLocation       = "locationCode"
+	BICRuleBranch         = "branchCode"
+)
+
+// BICPart is one segment of a SWIFT code and whether it is well formed
+type BICPart struct {
+	Value string `json:"value"`
+	Valid bool   `json:"valid"`
+}
+
+// BICViolation is a rule a SWIFT code breaks
+type BICViolation struct {
+	Rule    string `json:"rule" enums:"length,institutionCode,countryCode,unknownCountry,locationCode,branchCode"`
+	Message string `json:"message"`
+}
+
+// BICValidation response structure for GET /v1/swift-codes/{code}/validate,
+// the ISO 9362 parts of a SWIFT code and every rule it breaks
+type BICValidation struct {
+	Code  string `json:"code"`
+	Valid bool   `json:"valid"`
+	// Institution is the 4 letter bank code, Country the ISO2 country, Location 2 letters or digits
+	Institution BICPart `json:"institution"`
+	Country     BICPart `json:"country"`
+	CountryName string  `json:"countryName,omitempty"`
+	Location    BICPart `json:"location"`
+	// Branch is the 3 character branch code of an 11 character code, XXX for the head office
+	Branch        *BICPart `json:"branch,omitempty"`
+	IsHeadquarter bool     `json:"isHeadquarter"`
+	// Test is set for test and training codes (location ending with 0),
+	// PassiveParticipant for codes not connected to the SWIFT network (location ending with 1)
+	Test               bool           `json:"test"`
+	PassiveParticipant bool           `json:"passiveParticipant"`
+	Violations         []BICViolation `json:"violations"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// CachedLookup is the result of looking up a SWIFT code, a code that wasn't found is cached too
+type CachedLookup struct {
+	Found bool      `json:"found"`
+	Code  SwiftCode `json:"code"`
+}
+
+// CacheStats counts how the lookup cache is doing since start
+type CacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	// Errors are failed cache reads and writes, the repository answered instead
+	Errors uint64
+	// Entries and Evictions are known for the in-process cache only
+	Entries   int
+	Evictions uint64
+}
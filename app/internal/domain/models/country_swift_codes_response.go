@@ -5,4 +5,5 @@ type CountrySwiftCodesResponse struct {
 	CountryISO2 string        `json:"countryISO2"`
 	CountryName string        `json:"countryName"`
 	SwiftCodes  []SwiftBranch `json:"swiftCodes"`
+	NextCursor  string        `json:"nextCursor,omitempty"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// HistoryQuery selects codes as they were at AsOf, ordered by SWIFT code
+type HistoryQuery struct {
+	AsOf        time.Time // point in time
+	SwiftCode   string    // exact SWIFT code
+	BankPrefix  string    // first 8 characters of the SWIFT code, an HQ and its branches
+	CountryISO2 string    // exact country ISO2 at AsOf
+	After       string    // return codes sorted after this one
+	Limit       int       // maximum number of codes returned, 0 for all
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Import job statuses
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportSucceeded = "succeeded"
+	ImportFailed    = "failed"
+)
+
+// ImportJob is a CSV import started through the API, polled until it finishes
+type ImportJob struct {
+	ID         string         `json:"id"`
+	Status     string         `json:"status"`
+	FileName   string         `json:"fileName"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	StartedAt  *time.Time     `json:"startedAt,omitempty"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
+	Summary    *ImportSummary `json:"summary,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
@@ -1,11 +1,72 @@
 package models
 
-// ImportSummary summaries CSV import
+// ImportSummary summaries CSV import.
+// Updated and removed counters are only set by a sync import, DryRun marks a sync that changed nothing.
 type ImportSummary struct {
-	HQAdded           int `json:"hqAdded"`
-	HQSkipped         int `json:"hqSkipped"`
-	BranchesAdded     int `json:"branchesAdded"`
-	BranchesDuplicate int `json:"branchesDuplicate"`
-	BranchesMissingHQ int `json:"branchesMissingHQ"`
-	BranchesSkipped   int `json:"branchesSkipped"`
+	HQAdded           int  `json:"hqAdded"`
+	HQSkipped         int  `json:"hqSkipped"`
+	HQUpdated         int  `json:"hqUpdated"`
+	HQRemoved         int  `json:"hqRemoved"`
+	BranchesAdded     int  `json:"branchesAdded"`
+	BranchesDuplicate int  `json:"branchesDuplicate"`
+	BranchesMissingHQ int  `json:"branchesMissingHQ"`
+	BranchesSkipped   int  `json:"branchesSkipped"`
+	BranchesUpdated   int  `json:"branchesUpdated"`
+	BranchesRemoved   int  `json:"branchesRemoved"`
+	Rejected          int  `json:"rejected"`
+	DryRun            bool `json:"dryRun,omitempty"`
+
+	RejectedByReason RejectionCounts `json:"rejectedByReason"`
+}
+
+// RejectionCounts counts rejected CSV rows per reason
+type RejectionCounts struct {
+	MalformedRow        int `json:"malformedRow"`
+	InvalidSwiftCode    int `json:"invalidSwiftCode"`
+	InvalidCountryISO2  int `json:"invalidCountryISO2"`
+	UnknownCountry      int `json:"unknownCountry"`
+	CountryNameMismatch int `json:"countryNameMismatch"`
+	CountryCodeMismatch int `json:"countryCodeMismatch"`
+}
+
+// Add adds all counters of other to s, DryRun is kept
+func (s *ImportSummary) Add(other ImportSummary) {
+	s.HQAdded += other.HQAdded
+	s.HQSkipped += other.HQSkipped
+	s.HQUpdated += other.HQUpdated
+	s.HQRemoved += other.HQRemoved
+	s.BranchesAdded += other.BranchesAdded
+	s.BranchesDuplicate += other.BranchesDuplicate
+	s.BranchesMissingHQ += other.BranchesMissingHQ
+	s.BranchesSkipped += other.BranchesSkipped
+	s.BranchesUpdated += other.BranchesUpdated
+	s.BranchesRemoved += other.BranchesRemoved
+	s.Rejected += other.Rejected
+	s.RejectedByReason.MalformedRow += other.RejectedByReason.MalformedRow
+	s.RejectedByReason.InvalidSwiftCode += other.RejectedByReason.InvalidSwiftCode
+	s.RejectedByReason.InvalidCountryISO2 += other.RejectedByReason.InvalidCountryISO2
+	s.RejectedByReason.UnknownCountry += other.RejectedByReason.UnknownCountry
+	s.RejectedByReason.CountryNameMismatch += other.RejectedByReason.CountryNameMismatch
+	s.RejectedByReason.CountryCodeMismatch += other.RejectedByReason.CountryCodeMismatch
+}
+
+// CountRejected adds rejected rows to Rejected and RejectedByReason
+func (s *ImportSummary) CountRejected(rows ...RejectedRow) {
+	for _, row := range rows {
+		s.Rejected++
+		switch row.Reason {
+		case RejectMalformedRow:
+			s.RejectedByReason.MalformedRow++
+		case RejectInvalidSwiftCode:
+			s.RejectedByReason.InvalidSwiftCode++
+		case RejectInvalidCountryISO2:
+			s.RejectedByReason.InvalidCountryISO2++
+		case RejectUnknownCountry:
+			s.RejectedByReason.UnknownCountry++
+		case RejectCountryNameMismatch:
+			s.RejectedByReason.CountryNameMismatch++
+		case RejectCountryCodeMismatch:
+			s.RejectedByReason.CountryCodeMismatch++
+		}
+	}
 }
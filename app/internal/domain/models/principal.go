@@ -0,0 +1,17 @@
+package models
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Roles   []string
+}
+
+// HasRole reports whether the caller may act in role, every role includes reading and admin includes all
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role || r == RoleAdmin || role == RoleReader && ValidRole(r) {
+			return true
+		}
+	}
+	return false
+}
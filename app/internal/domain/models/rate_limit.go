@@ -0,0 +1,51 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket holding Limit requests that refills completely over Period
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Enabled reports whether the limit restricts anything
+func (l RateLimit) Enabled() bool {
+	return l.Limit > 0 && l.Period > 0
+}
+
+// Refill returns the tokens in a bucket that held tokens elapsed ago, capped at Limit
+func (l RateLimit) Refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += float64(elapsed) / float64(l.Period) * float64(l.Limit)
+	}
+	return math.Min(tokens, float64(l.Limit))
+}
+
+// Result describes a bucket left with tokens after a request was allowed or not
+func (l RateLimit) Result(allowed bool, tokens float64) RateLimitResult {
+	perToken := float64(l.Period) / float64(l.Limit)
+	res := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     l.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(l.Limit) - tokens) * perToken),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return res
+}
+
+// RateLimitResult is the state of a bucket after taking a token for a request
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token when the request wasn't allowed
+	RetryAfter time.Duration
+}
@@ -0,0 +1,20 @@
+package models
+
+// Reasons a CSV row is rejected during import
+const (
+	RejectMalformedRow        = "malformed_row"
+	RejectInvalidSwiftCode    = "invalid_swift_code"
+	RejectInvalidCountryISO2  = "invalid_country_iso2"
+	RejectUnknownCountry      = "unknown_country"
+	RejectCountryNameMismatch = "country_name_mismatch"
+	// the country of the SWIFT code (characters 5-6) isn't the country ISO2 of the row
+	RejectCountryCodeMismatch = "country_code_mismatch"
+)
+
+// RejectedRow is a CSV row skipped by the import, with the reason it failed validation
+type RejectedRow struct {
+	Line    int      `json:"line"`
+	Record  []string `json:"record"`
+	Reason  string   `json:"reason"`
+	Message string   `json:"message"`
+}
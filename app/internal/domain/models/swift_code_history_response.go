@@ -0,0 +1,7 @@
+package models
+
+// SwiftCodeHistoryResponse response structure for GET /v1/swift-codes/{swift-code}/history
+type SwiftCodeHistoryResponse struct {
+	SwiftCode string             `json:"swiftCode"`
+	Versions  []SwiftCodeVersion `json:"versions"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// SwiftCodeListResponse response structure for GET /v1/swift-codes
+type SwiftCodeListResponse struct {
+	SwiftCodes []SwiftBranch `json:"swiftCodes"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
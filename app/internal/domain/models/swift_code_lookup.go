@@ -0,0 +1,28 @@
+package models
+
+// Statuses of a code in a batch lookup
+const (
+	LookupHeadquarter = "hq"
+	LookupBranch      = "branch"
+	LookupNotFound    = "notFound"
+	LookupInvalid     = "invalid"
+)
+
+// SwiftCodeLookupRequest request structure for POST /v1/swift-codes/lookup
+type SwiftCodeLookupRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// SwiftCodeLookupResult is the outcome for one requested code, SwiftCode is set for found codes
+// and Message explains an invalid one
+type SwiftCodeLookupResult struct {
+	Code      string     `json:"code"`
+	Status    string     `json:"status" enums:"hq,branch,notFound,invalid"`
+	SwiftCode *SwiftCode `json:"swiftCode,omitempty"`
+	Message   string     `json:"message,omitempty"`
+}
+
+// SwiftCodeLookupResponse response structure for POST /v1/swift-codes/lookup, results are in the order of the codes
+type SwiftCodeLookupResponse struct {
+	Results []SwiftCodeLookupResult `json:"results"`
+}
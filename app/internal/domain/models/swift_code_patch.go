@@ -0,0 +1,30 @@
+package models
+
+// SwiftCodePatch holds the fields changed by a partial update, nil fields are kept.
+// SWIFT code and headquarter flag can't be changed.
+type SwiftCodePatch struct {
+	Address     *string `json:"address,omitempty"`
+	BankName    *string `json:"bankName,omitempty"`
+	CountryISO2 *string `json:"countryISO2,omitempty"`
+	CountryName *string `json:"countryName,omitempty"`
+	TownName    *string `json:"townName,omitempty"`
+	CodeType    *string `json:"codeType,omitempty"`
+	TimeZone    *string `json:"timeZone,omitempty"`
+}
+
+// Apply returns sc with the set fields of p
+func (p SwiftCodePatch) Apply(sc SwiftCode) SwiftCode {
+	set := func(dst *string, v *string) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	set(&sc.Address, p.Address)
+	set(&sc.BankName, p.BankName)
+	set(&sc.CountryISO2, p.CountryISO2)
+	set(&sc.CountryName, p.CountryName)
+	set(&sc.TownName, p.TownName)
+	set(&sc.CodeType, p.CodeType)
+	set(&sc.TimeZone, p.TimeZone)
+	return sc
+}
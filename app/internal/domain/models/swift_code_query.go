@@ -0,0 +1,12 @@
+package models
+
+// SwiftCodeQuery filters and pages the flat list of HQ and branch codes, ordered by SWIFT code
+type SwiftCodeQuery struct {
+	CountryISO2    string // exact country ISO2
+	IsHeadquarter  *bool  // only HQs (true) or only branches (false)
+	BankNamePrefix string // case-insensitive bank name prefix
+	Town           string // case-insensitive town name
+	TimeZone       string // exact IANA time zone, e.g. Europe/Warsaw
+	After          string // return codes sorted after this one
+	Limit          int    // maximum number of codes returned
+}
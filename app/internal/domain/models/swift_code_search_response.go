@@ -0,0 +1,13 @@
+package models
+
+// SwiftCodeSearchHit is a single HQ or branch found by search, with its relevance score
+type SwiftCodeSearchHit struct {
+	SwiftBranch
+	Score float64 `json:"score"`
+}
+
+// SwiftCodeSearchResponse response structure for GET /v1/swift-codes/search
+type SwiftCodeSearchResponse struct {
+	Query   string               `json:"query"`
+	Results []SwiftCodeSearchHit `json:"results"`
+}
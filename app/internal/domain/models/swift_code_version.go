@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SwiftCodeVersion is the state of one HQ or branch (without nested branches) from ValidFrom
+// until the next version of the code; a deleted version keeps the state the code had when deleted
+type SwiftCodeVersion struct {
+	ValidFrom time.Time  `bson:"validFrom" json:"validFrom"`
+	ValidTo   *time.Time `bson:"-" json:"validTo,omitempty"` // start of the next version, set in history responses
+	Deleted   bool       `bson:"deleted,omitempty" json:"deleted,omitempty"`
+	Code      SwiftCode  `bson:"code" json:"code"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // SwiftBranch structure of SWIFT branch
 type SwiftBranch struct {
 	Address       string `bson:"address"       json:"address"`
@@ -8,4 +10,10 @@ type SwiftBranch struct {
 	CountryName   string `bson:"countryName,omitempty" json:"countryName,omitempty"`
 	IsHeadquarter bool   `bson:"isHeadquarter" json:"isHeadquarter"`
 	SwiftCode     string `bson:"swiftCode"     json:"swiftCode"`
+	TownName      string `bson:"townName,omitempty" json:"townName,omitempty"`
+	CodeType      string `bson:"codeType,omitempty" json:"codeType,omitempty"`
+	TimeZone      string `bson:"timeZone,omitempty" json:"timeZone,omitempty"`
+	// set on branches in SwiftCode.DeletedBranches only
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"deletedAt,omitempty" swaggerignore:"true"`
+	DeletedBy string     `bson:"deletedBy,omitempty" json:"deletedBy,omitempty" swaggerignore:"true"`
 }
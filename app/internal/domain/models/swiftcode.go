@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // SwiftCode structure of SWIFT code
 type SwiftCode struct {
 	Address       string        `bson:"address"      json:"address"`
@@ -8,5 +10,15 @@ type SwiftCode struct {
 	CountryName   string        `bson:"countryName"  json:"countryName"`
 	IsHeadquarter bool          `bson:"isHeadquarter" json:"isHeadquarter"`
 	SwiftCode     string        `bson:"swiftCode"    json:"swiftCode"`
+	TownName      string        `bson:"townName,omitempty" json:"townName,omitempty"`
+	CodeType      string        `bson:"codeType,omitempty" json:"codeType,omitempty"`
+	TimeZone      string        `bson:"timeZone,omitempty" json:"timeZone,omitempty"`
 	Branches      []SwiftBranch `bson:"branches,omitempty" json:"branches,omitempty"`
+	// Version of the stored HQ document, a branch has the version of its HQ; sent as ETag too
+	Version int64 `bson:"version" json:"version,omitempty"`
+	// DeletedAt and DeletedBy mark a soft deleted HQ, it is hidden until restored or purged
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"deletedAt,omitempty" swaggerignore:"true"`
+	DeletedBy string     `bson:"deletedBy,omitempty" json:"deletedBy,omitempty" swaggerignore:"true"`
+	// DeletedBranches are soft deleted branches of the HQ, kept by the storage only
+	DeletedBranches []SwiftBranch `bson:"deletedBranches,omitempty" json:"deletedBranches,omitempty" swaggerignore:"true"`
 }
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// auditor appends entries to the audit log, it does nothing without one
+type auditor struct {
+	log port.AuditRepository
+}
+
+// record appends entry with the time, actor and request ID taken from ctx.
+// The change is already saved when it fails, so the caller reports a 500.
+func (a auditor) record(ctx context.Context, entry models.AuditEntry) error {
+	if a.log == nil {
+		return nil
+	}
+	entry.Timestamp = time.Now().UTC()
+	entry.Actor = util.Actor(ctx)
+	entry.RequestID = util.RequestID(ctx)
+	if err := a.log.Append(ctx, entry); err != nil {
+		return util.Internal("error recording %s in audit log: %v", entry.Action, err)
+	}
+	return nil
+}
+
+// change records a change of one code
+func (a auditor) change(ctx context.Context, action, code string, before, after *models.SwiftCode) error {
+	return a.record(ctx, models.AuditEntry{Action: action, SwiftCode: code, Before: before, After: after})
+}
+
+// AuditService reads the audit log
+type AuditService struct {
+	log port.AuditRepository
+}
+
+// NewAuditService creates service reading log
+func NewAuditService(log port.AuditRepository) *AuditService {
+	return &AuditService{log: log}
+}
+
+// ListAuditEntries returns entries matching the query, oldest first
+func (s *AuditService) ListAuditEntries(ctx context.Context, q models.AuditQuery) (models.AuditLogResponse, error) {
+	if q.SwiftCode != "" {
+		if err := util.ValidateSwiftCode(q.SwiftCode); err != nil {
+			return models.AuditLogResponse{}, util.BadRequest("invalid SWIFT code: %v", err)
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return models.AuditLogResponse{}, util.BadRequest("to must not be before from")
+	}
+	limit, err := pageSize(q.Limit)
+	if err != nil {
+		return models.AuditLogResponse{}, err
+	}
+	q.Limit = limit
+
+	entries, err := s.log.Find(ctx, q)
+	if err != nil {
+		return models.AuditLogResponse{}, util.Internal("error reading audit log: %v", err)
+	}
+	// no entries is still a list in JSON, not null
+	return models.AuditLogResponse{Entries: append([]models.AuditEntry{}, entries...)}, nil
+}
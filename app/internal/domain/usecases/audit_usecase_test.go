@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/initializer"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+type stubAudit struct {
+	entries   []models.AuditEntry
+	appendErr error
+	lastQuery models.AuditQuery
+}
+
+func (s *stubAudit) Append(ctx context.Context, entry models.AuditEntry) error {
+	if s.appendErr != nil {
+		return s.appendErr
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+func (s *stubAudit) Find(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error) {
+	s.lastQuery = q
+	return nil, nil
+}
+
+func TestSwiftService_AuditLog(t *testing.T) {
+	hq := models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "Bank", CountryISO2: "PL", IsHeadquarter: true, Version: 1}
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{}}
+	audit := &stubAudit{}
+	svc := NewSwiftService(repo, WithAuditLog(audit))
+	ctx := util.WithRequestID(util.WithActor(context.Background(), "alice"), "req-1")
+
+	if err := svc.AddSwiftCode(ctx, hq); err != nil {
+		t.Fatal(err)
+	}
+	repo.byCode[hq.SwiftCode] = hq
+	renamed := hq
+	renamed.BankName = "Renamed"
+	if _, err := svc.UpdateSwiftCode(ctx, hq.SwiftCode, 0, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteSwiftCode(ctx, hq.SwiftCode, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RestoreSwiftCode(ctx, hq.SwiftCode); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.PurgeDeleted(ctx, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		action        string
+		before, after bool
+	}{
+		{models.AuditCreate, false, true},
+		{models.AuditUpdate, true, true},
+		{models.AuditDelete, true, false},
+		{models.AuditRestore, false, true},
+		{models.AuditPurge, false, false},
+	}
+	if len(audit.entries) != len(want) {
+		t.Fatalf("recorded %d entries; want %d", len(audit.entries), len(want))
+	}
+	for i, w := range want {
+		e := audit.entries[i]
+		if e.Action != w.action || (e.Before != nil) != w.before || (e.After != nil) != w.after {
+			t.Errorf("entry %d = %+v; want %s with before %v, after %v", i, e, w.action, w.before, w.after)
+		}
+		if e.Actor != "alice" || e.RequestID != "req-1" || e.Timestamp.IsZero() {
+			t.Errorf("entry %d made by %q in %q at %v; want alice, req-1 and a time", i, e.Actor, e.RequestID, e.Timestamp)
+		}
+	}
+	if e := audit.entries[0]; e.SwiftCode != hq.SwiftCode || e.After.BankName != "Bank" {
+		t.Errorf("create entry = %+v", e)
+	}
+	if e := audit.entries[4]; e.SwiftCode != "" || !strings.Contains(e.Details, "3 codes") {
+		t.Errorf("purge entry = %+v; want the purged count", e)
+	}
+
+	// the change is saved but can't be audited
+	audit.appendErr = errors.New("boom")
+	if err := svc.DeleteSwiftCode(ctx, hq.SwiftCode, 0); !isStatus(err, http.StatusInternalServerError) {
+		t.Errorf("audit failure: expected 500, got %v", err)
+	}
+}
+
+func TestImportService_ImportFileAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codes.csv")
+	csv := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AAAAPLPWXXX,Bank A,Addr A,POLAND
+`
+	if err := os.WriteFile(path, []byte(csv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	audit := &stubAudit{}
+	svc := NewImportService(&stubRepo{}, map[string]string{"PL": "POLAND"}, WithAuditLog(audit))
+	ctx := util.WithActor(context.Background(), "startup import")
+
+	if _, err := svc.ImportFile(ctx, path, initializer.WithDryRun()); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.entries) != 0 {
+		t.Errorf("dry run recorded %+v; want nothing", audit.entries)
+	}
+
+	if _, err := svc.ImportFile(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.entries) != 1 {
+		t.Fatalf("recorded %+v; want one import entry", audit.entries)
+	}
+	e := audit.entries[0]
+	if e.Action != models.AuditImport || e.Actor != "startup import" || e.Details != "codes.csv" ||
+		e.Import == nil || e.Import.HQAdded != 1 {
+		t.Errorf("import entry = %+v", e)
+	}
+}
+
+func TestAuditService_ListAuditEntries(t *testing.T) {
+	audit := &stubAudit{}
+	svc := NewAuditService(audit)
+	ctx := context.Background()
+
+	resp, err := svc.ListAuditEntries(ctx, models.AuditQuery{SwiftCode: "ABCDPLPWXXX"})
+	if err != nil || resp.Entries == nil {
+		t.Fatalf("ListAuditEntries = %+v, %v; want empty list", resp, err)
+	}
+	if audit.lastQuery.Limit != util.DefaultPageSize {
+		t.Errorf("limit = %d; want default page size", audit.lastQuery.Limit)
+	}
+
+	now := time.Now()
+	bad := []models.AuditQuery{
+		{SwiftCode: "BAD"},
+		{From: now, To: now.Add(-time.Hour)},
+		{Limit: util.MaxPageSize + 1},
+	}
+	for _, q := range bad {
+		if _, err := svc.ListAuditEntries(ctx, q); !isStatus(err, http.StatusBadRequest) {
+			t.Errorf("ListAuditEntries(%+v) = %v; want 400", q, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// historian records versions of codes, it does nothing without a history
+type historian struct {
+	versions port.HistoryRepository
+}
+
+// record appends the current state of codes, or the state they were deleted in, valid from now
+func (h historian) record(ctx context.Context, deleted bool, codes ...models.SwiftCode) error {
+	if h.versions == nil || len(codes) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	versions := make([]models.SwiftCodeVersion, len(codes))
+	for i, sc := range codes {
+		versions[i] = models.SwiftCodeVersion{ValidFrom: now, Deleted: deleted, Code: snapshot(sc)}
+	}
+	if err := h.versions.Record(ctx, versions); err != nil {
+		return util.Internal("error recording history: %v", err)
+	}
+	return nil
+}
+
+// recordWithBranches records an HQ together with its branches, or a single branch
+func (h historian) recordWithBranches(ctx context.Context, deleted bool, sc models.SwiftCode) error {
+	codes := []models.SwiftCode{sc}
+	for _, br := range sc.Branches {
+		codes = append(codes, models.SwiftCode{
+			SwiftCode:   br.SwiftCode,
+			BankName:    br.BankName,
+			Address:     br.Address,
+			TownName:    br.TownName,
+			CodeType:    br.CodeType,
+			TimeZone:    br.TimeZone,
+			CountryISO2: br.CountryISO2,
+			CountryName: sc.CountryName,
+		})
+	}
+	return h.record(ctx, deleted, codes...)
+}
+
+// snapshot is sc as kept in the history: details only, no branches, version or deletion marks
+func snapshot(sc models.SwiftCode) models.SwiftCode {
+	return models.SwiftCode{
+		SwiftCode:     sc.SwiftCode,
+		BankName:      sc.BankName,
+		Address:       sc.Address,
+		TownName:      sc.TownName,
+		CodeType:      sc.CodeType,
+		TimeZone:      sc.TimeZone,
+		CountryISO2:   sc.CountryISO2,
+		CountryName:   sc.CountryName,
+		IsHeadquarter: sc.IsHeadquarter,
+	}
+}
+
+// GetSwiftCodeHistory returns every version of an HQ or branch, oldest first
+func (s *SwiftService) GetSwiftCodeHistory(ctx context.Context, code string) (models.SwiftCodeHistoryResponse, error) {
+	if err := s.historyEnabled(code); err != nil {
+		return models.SwiftCodeHistoryResponse{}, err
+	}
+	versions, err := s.history.versions.History(ctx, code)
+	if err != nil {
+		return models.SwiftCodeHistoryResponse{}, util.Internal("error fetching history: %v", err)
+	}
+	if len(versions) == 0 {
+		return models.SwiftCodeHistoryResponse{}, util.NotFound("no history of SWIFT code %s", code)
+	}
+	for i := 0; i < len(versions)-1; i++ {
+		validTo := versions[i+1].ValidFrom
+		versions[i].ValidTo = &validTo
+	}
+	return models.SwiftCodeHistoryResponse{SwiftCode: code, Versions: versions}, nil
+}
+
+// GetSwiftCodeAsOf returns an HQ with its branches, or a branch, as it was at asOf
+func (s *SwiftService) GetSwiftCodeAsOf(ctx context.Context, code string, asOf time.Time) (models.SwiftCode, error) {
+	if err := s.historyEnabled(code); err != nil {
+		return models.SwiftCode{}, err
+	}
+	q := models.HistoryQuery{AsOf: asOf, SwiftCode: code}
+	if len(code) == 11 && strings.HasSuffix(code, "XXX") {
+		q = models.HistoryQuery{AsOf: asOf, BankPrefix: code[:8]}
+	}
+	versions, err := s.history.versions.AsOf(ctx, q)
+	if err != nil {
+		return models.SwiftCode{}, util.Internal("error fetching SWIFT code history: %v", err)
+	}
+
+	var found *models.SwiftCode
+	var branches []models.SwiftCode
+	for i, v := range versions {
+		switch {
+		case v.Code.SwiftCode == code:
+			found = &versions[i].Code
+		case !v.Code.IsHeadquarter:
+			branches = append(branches, v.Code)
+		}
+	}
+	if found == nil {
+		return models.SwiftCode{}, util.NotFound("SWIFT code %s not found at %s", code, asOf.UTC().Format(time.RFC3339))
+	}
+	sc := *found
+	if sc.IsHeadquarter {
+		sc.Branches = toBranches(branches)
+	}
+	return sc, nil
+}
+
+// GetSwiftCodesByCountryAsOf returns one page of HQs and branches of a country as they were at asOf
+func (s *SwiftService) GetSwiftCodesByCountryAsOf(ctx context.Context, iso2, cursor string, limit int, asOf time.Time) (models.CountrySwiftCodesResponse, error) {
+	if s.history.versions == nil {
+		return models.CountrySwiftCodesResponse{}, historyDisabled()
+	}
+	return s.countryPage(iso2, cursor, limit, func(q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+		versions, err := s.history.versions.AsOf(ctx, models.HistoryQuery{
+			AsOf: asOf, CountryISO2: q.CountryISO2, After: q.After, Limit: q.Limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		list := make([]models.SwiftCode, len(versions))
+		for i, v := range versions {
+			list[i] = v.Code
+		}
+		return list, nil
+	})
+}
+
+// historyEnabled checks the code of a history lookup and that versions are recorded
+func (s *SwiftService) historyEnabled(code string) error {
+	if err := util.ValidateSwiftCode(code); err != nil {
+		return util.BadRequest("invalid SWIFT code: %v", err)
+	}
+	if s.history.versions == nil {
+		return historyDisabled()
+	}
+	return nil
+}
+
+// historyDisabled is the answer to history lookups when no history is recorded
+func historyDisabled() error {
+	return util.BadRequest("version history isn't recorded by this server")
+}
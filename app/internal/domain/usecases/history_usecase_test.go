@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+type stubHistory struct {
+	recorded  []models.SwiftCodeVersion
+	versions  []models.SwiftCodeVersion // returned by History and AsOf
+	lastQuery models.HistoryQuery
+	// latestCalls counts Latest lookups
+	latestCalls int
+}
+
+func (s *stubHistory) Record(ctx context.Context, versions []models.SwiftCodeVersion) error {
+	s.recorded = append(s.recorded, versions...)
+	return nil
+}
+func (s *stubHistory) History(ctx context.Context, code string) ([]models.SwiftCodeVersion, error) {
+	var versions []models.SwiftCodeVersion
+	for _, v := range s.versions {
+		if v.Code.SwiftCode == code {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+func (s *stubHistory) Latest(ctx context.Context, codes []string) (map[string]models.SwiftCodeVersion, error) {
+	s.latestCalls++
+	latest := make(map[string]models.SwiftCodeVersion)
+	for _, code := range codes {
+		for _, v := range s.versions {
+			if v.Code.SwiftCode == code {
+				latest[code] = v
+			}
+		}
+	}
+	return latest, nil
+}
+func (s *stubHistory) AsOf(ctx context.Context, q models.HistoryQuery) ([]models.SwiftCodeVersion, error) {
+	s.lastQuery = q
+	return s.versions, nil
+}
+
+func TestSwiftService_RecordsHistory(t *testing.T) {
+	hq := models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "Bank", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	history := &stubHistory{}
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{}}
+	svc := NewSwiftService(repo, WithHistory(history))
+	ctx := context.Background()
+
+	if err := svc.AddSwiftCode(ctx, hq); err != nil {
+		t.Fatal(err)
+	}
+	stored := hq
+	stored.Version = 2
+	stored.Branches = []models.SwiftBranch{{SwiftCode: "ABCDPLPW001", BankName: "Branch", CountryISO2: "PL"}}
+	repo.byCode[hq.SwiftCode] = stored
+	if err := svc.DeleteSwiftCode(ctx, hq.SwiftCode, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		code    string
+		deleted bool
+	}{
+		{"ABCDPLPWXXX", false},
+		{"ABCDPLPWXXX", true},
+		{"ABCDPLPW001", true}, // deleted with its HQ
+	}
+	if len(history.recorded) != len(want) {
+		t.Fatalf("recorded %+v; want %d versions", history.recorded, len(want))
+	}
+	for i, w := range want {
+		v := history.recorded[i]
+		if v.Code.SwiftCode != w.code || v.Deleted != w.deleted || v.ValidFrom.IsZero() {
+			t.Errorf("version %d = %+v; want %s deleted=%v", i, v, w.code, w.deleted)
+		}
+		if v.Code.Branches != nil || v.Code.Version != 0 {
+			t.Errorf("version %d keeps branches or version: %+v", i, v.Code)
+		}
+	}
+	if branch := history.recorded[2].Code; branch.CountryName != "POLAND" || branch.IsHeadquarter {
+		t.Errorf("branch version = %+v; want the HQ country name", branch)
+	}
+}
+
+func TestGetSwiftCodeHistory(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := &stubHistory{versions: []models.SwiftCodeVersion{
+		{ValidFrom: t0, Code: models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "Old"}},
+		{ValidFrom: t0.Add(time.Hour), Code: models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "New"}},
+	}}
+	svc := NewSwiftService(&stubRepo{}, WithHistory(history))
+	ctx := context.Background()
+
+	resp, err := svc.GetSwiftCodeHistory(ctx, "ABCDPLPWXXX")
+	if err != nil || len(resp.Versions) != 2 {
+		t.Fatalf("GetSwiftCodeHistory = %+v, %v", resp, err)
+	}
+	if to := resp.Versions[0].ValidTo; to == nil || !to.Equal(t0.Add(time.Hour)) || resp.Versions[1].ValidTo != nil {
+		t.Errorf("validTo = %v, %v; want the start of the next version, none for the current one", to, resp.Versions[1].ValidTo)
+	}
+	if _, err := svc.GetSwiftCodeHistory(ctx, "ZZZZPLPWXXX"); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("no history: expected 404, got %v", err)
+	}
+	if _, err := NewSwiftService(&stubRepo{}).GetSwiftCodeHistory(ctx, "ABCDPLPWXXX"); !isStatus(err, http.StatusBadRequest) {
+		t.Errorf("history not recorded: expected 400, got %v", err)
+	}
+}
+
+func TestGetSwiftCodeAsOf(t *testing.T) {
+	asOf := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := &stubHistory{versions: []models.SwiftCodeVersion{
+		{Code: models.SwiftCode{SwiftCode: "ABCDPLPW001", BankName: "Branch", CountryISO2: "PL"}},
+		{Code: models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "Bank", CountryISO2: "PL", IsHeadquarter: true}},
+	}}
+	svc := NewSwiftService(&stubRepo{}, WithHistory(history))
+	ctx := context.Background()
+
+	hq, err := svc.GetSwiftCodeAsOf(ctx, "ABCDPLPWXXX", asOf)
+	if err != nil || hq.BankName != "Bank" || len(hq.Branches) != 1 || hq.Branches[0].SwiftCode != "ABCDPLPW001" {
+		t.Fatalf("GetSwiftCodeAsOf = %+v, %v; want HQ with its branch", hq, err)
+	}
+	if q := history.lastQuery; q.BankPrefix != "ABCDPLPW" || !q.AsOf.Equal(asOf) {
+		t.Errorf("HQ query = %+v; want the bank prefix at asOf", q)
+	}
+
+	branch, err := svc.GetSwiftCodeAsOf(ctx, "ABCDPLPW001", asOf)
+	if err != nil || branch.SwiftCode != "ABCDPLPW001" || history.lastQuery.SwiftCode != "ABCDPLPW001" {
+		t.Errorf("GetSwiftCodeAsOf(branch) = %+v, %v with query %+v", branch, err, history.lastQuery)
+	}
+
+	history.versions = nil
+	if _, err := svc.GetSwiftCodeAsOf(ctx, "ABCDPLPWXXX", asOf); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("not there at asOf: expected 404, got %v", err)
+	}
+}
+
+func TestImportService_RecordsAddedHistory(t *testing.T) {
+	stored := models.SwiftCode{SwiftCode: "AAAAPLPWXXX", BankName: "Stored", CountryISO2: "PL", IsHeadquarter: true}
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{stored.SwiftCode: stored}}
+	history := &stubHistory{}
+	svc := NewImportService(repo, nil, WithHistory(history))
+	ctx := context.Background()
+	batch := []models.SwiftCode{
+		{SwiftCode: "AAAAPLPWXXX", BankName: "From file", IsHeadquarter: true},
+		{SwiftCode: "BBBBPLPWXXX", BankName: "Skipped", IsHeadquarter: true},
+	}
+
+	// every code added: the rows are the stored state
+	if err := svc.recordAdded(ctx, batch, 2); err != nil || len(history.recorded) != 2 {
+		t.Fatalf("recordAdded = %v, recorded %+v", err, history.recorded)
+	}
+
+	// some skipped: codes without history are recorded as stored, missing ones not at all
+	history.recorded = nil
+	if err := svc.recordAdded(ctx, batch, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.recorded) != 1 || history.recorded[0].Code.BankName != "Stored" {
+		t.Errorf("recorded %+v; want only the stored AAAAPLPWXXX", history.recorded)
+	}
+
+	// codes with history are left alone, a whole batch takes one lookup of each
+	history.recorded, history.latestCalls, repo.batches = nil, 0, 0
+	history.versions = []models.SwiftCodeVersion{{Code: stored}}
+	if err := svc.recordAdded(ctx, batch, 1); err != nil || len(history.recorded) != 0 {
+		t.Errorf("recordAdded = %v, recorded %+v; want nothing", err, history.recorded)
+	}
+	if history.latestCalls != 1 || repo.batches != 1 {
+		t.Errorf("history lookups = %d, code lookups = %d; want one each", history.latestCalls, repo.batches)
+	}
+
+	// a deleted code added again gets a new version
+	history.recorded, history.latestCalls, repo.batches = nil, 0, 0
+	history.versions = []models.SwiftCodeVersion{{Code: stored, Deleted: true}}
+	if err := svc.recordAdded(ctx, batch, 1); err != nil || len(history.recorded) != 1 || history.recorded[0].Deleted {
+		t.Errorf("recordAdded = %v, recorded %+v; want the stored AAAAPLPWXXX", err, history.recorded)
+	}
+	if history.latestCalls != 1 || repo.batches != 1 {
+		t.Errorf("history lookups = %d, code lookups = %d; want one each", history.latestCalls, repo.batches)
+	}
+}
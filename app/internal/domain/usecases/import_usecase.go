@@ -0,0 +1,265 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/initializer"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// maxImportJobs is how many jobs are remembered, the oldest finished ones are forgotten first
+const maxImportJobs = 1000
+
+// ImportService runs CSV imports uploaded through the API in the background.
+// Jobs run one at a time in the order they were started and are kept in memory only.
+type ImportService struct {
+	repo      port.SwiftRepository
+	countries map[string]string
+	audit     auditor
+	history   historian
+
+	mu   sync.Mutex
+	jobs map[string]*models.ImportJob
+
+	run sync.Mutex     // serializes imports
+	wg  sync.WaitGroup // running jobs, waited for in tests
+}
+
+// NewImportService creates service importing into repo, validating country names against countries
+func NewImportService(r port.SwiftRepository, countries map[string]string, opts ...Option) *ImportService {
+	o := applyOptions(opts)
+	return &ImportService{
+		repo:      r,
+		countries: countries,
+		audit:     auditor{log: o.audit},
+		history:   historian{versions: o.history},
+		jobs:      make(map[string]*models.ImportJob),
+	}
+}
+
+// ImportFile imports the CSV at path right away, like at startup.
+// The import is recorded in the audit log with its summary, and so is every code a sync updates or removes;
+// the history gets a version of every code added, updated or removed. A dry run changes nothing and isn't recorded.
+func (s *ImportService) ImportFile(ctx context.Context, path string, opts ...initializer.Option) (*models.ImportSummary, error) {
+	return s.importFile(ctx, filepath.Base(path), path, opts...)
+}
+
+// importFile imports the CSV at path, name describes it in the audit log
+func (s *ImportService) importFile(ctx context.Context, name, path string, opts ...initializer.Option) (*models.ImportSummary, error) {
+	if s.audit.log != nil || s.history.versions != nil {
+		opts = append(opts, initializer.WithChangeHook(func(before, after *models.SwiftCode) error {
+			if after == nil {
+				if err := s.history.record(ctx, true, *before); err != nil {
+					return err
+				}
+				return s.audit.change(ctx, models.AuditDelete, before.SwiftCode, before, nil)
+			}
+			if err := s.history.record(ctx, false, *after); err != nil {
+				return err
+			}
+			return s.audit.change(ctx, models.AuditUpdate, before.SwiftCode, before, after)
+		}))
+	}
+	if s.history.versions != nil {
+		opts = append(opts, initializer.WithSaveHook(func(batch []models.SwiftCode, added int) error {
+			return s.recordAdded(ctx, batch, added)
+		}))
+	}
+	summary, err := initializer.ImportCSV(s.repo, path, s.countries, opts...)
+	if summary != nil && summary.DryRun {
+		return summary, err
+	}
+
+	entry := models.AuditEntry{Action: models.AuditImport, Import: summary, Details: name}
+	if err != nil {
+		entry.Details += ": " + err.Error()
+	}
+	if aerr := s.audit.record(ctx, entry); err == nil {
+		err = aerr
+	}
+	return summary, err
+}
+
+// StartImport stores the uploaded CSV in a temporary file and imports it in the background with opts
+func (s *ImportService) StartImport(ctx context.Context, fileName string, csv io.Reader, opts ...initializer.Option) (models.ImportJob, error) {
+	tmp, err := os.CreateTemp("", "swift-import-*.csv")
+	if err != nil {
+		return models.ImportJob{}, util.Internal("error storing upload: %v", err)
+	}
+	n, err := io.Copy(tmp, csv)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return models.ImportJob{}, util.Internal("error storing upload: %v", err)
+	}
+	if n == 0 {
+		os.Remove(tmp.Name())
+		return models.ImportJob{}, util.BadRequest("uploaded file is empty")
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return models.ImportJob{}, util.Internal("error creating import job: %v", err)
+	}
+	job := &models.ImportJob{
+		ID:        id,
+		Status:    models.ImportPending,
+		FileName:  fileName,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	s.jobs[id] = job
+	s.evict()
+	snapshot := *job
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	// the import outlives the request, but is recorded with its actor and request ID
+	go s.runImport(context.WithoutCancel(ctx), job, tmp.Name(), opts...)
+	return snapshot, nil
+}
+
+// GetImport returns the current state of an import job
+func (s *ImportService) GetImport(ctx context.Context, id string) (models.ImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return models.ImportJob{}, util.NotFound("import %s not found", id)
+	}
+	return *job, nil
+}
+
+// runImport imports the file and records the outcome on the job
+func (s *ImportService) runImport(ctx context.Context, job *models.ImportJob, path string, opts ...initializer.Option) {
+	defer s.wg.Done()
+	defer os.Remove(path)
+	// a bug in the import must fail the job, not take the server down with it
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("import %s panicked: %v", job.ID, p)
+			s.update(job, func(j *models.ImportJob) {
+				now := time.Now().UTC()
+				j.FinishedAt = &now
+				j.Status = models.ImportFailed
+				j.Error = fmt.Sprintf("import panicked: %v", p)
+			})
+		}
+	}()
+
+	s.run.Lock()
+	defer s.run.Unlock()
+
+	s.update(job, func(j *models.ImportJob) {
+		now := time.Now().UTC()
+		j.Status = models.ImportRunning
+		j.StartedAt = &now
+	})
+
+	summary, err := s.importFile(ctx, fmt.Sprintf("%s (import %s)", job.FileName, job.ID), path, opts...)
+
+	s.update(job, func(j *models.ImportJob) {
+		now := time.Now().UTC()
+		j.FinishedAt = &now
+		j.Summary = summary
+		if err != nil {
+			j.Status = models.ImportFailed
+			j.Error = err.Error()
+			return
+		}
+		j.Status = models.ImportSucceeded
+	})
+}
+
+// recordAdded records versions of the codes of a saved batch. When some of them were skipped,
+// only codes without a live latest version are recorded, as they are stored: the added ones,
+// deleted codes added again and codes stored before the history was kept.
+// History and codes are looked up once for the whole batch.
+func (s *ImportService) recordAdded(ctx context.Context, batch []models.SwiftCode, added int) error {
+	if added == len(batch) {
+		return s.history.record(ctx, false, batch...)
+	}
+	codes := make([]string, len(batch))
+	for i, sc := range batch {
+		codes[i] = sc.SwiftCode
+	}
+	latest, err := s.history.versions.Latest(ctx, codes)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if v, ok := latest[code]; (ok && !v.Deleted) || seen[code] {
+			continue
+		}
+		seen[code] = true
+		missing = append(missing, code)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	found, err := s.repo.GetByCodes(ctx, missing)
+	if err != nil {
+		return err
+	}
+	var stored []models.SwiftCode
+	for _, code := range missing {
+		if sc, ok := found[code]; ok {
+			stored = append(stored, sc)
+		}
+	}
+	return s.history.record(ctx, false, stored...)
+}
+
+func (s *ImportService) update(job *models.ImportJob, fn func(*models.ImportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(job)
+}
+
+// evict forgets the oldest finished jobs above maxImportJobs; caller holds s.mu
+func (s *ImportService) evict() {
+	if len(s.jobs) <= maxImportJobs {
+		return
+	}
+	var finished []*models.ImportJob
+	for _, job := range s.jobs {
+		if job.FinishedAt != nil {
+			finished = append(finished, job)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CreatedAt.Before(finished[j].CreatedAt) })
+	for _, job := range finished {
+		if len(s.jobs) <= maxImportJobs {
+			return
+		}
+		delete(s.jobs, job.ID)
+	}
+}
+
+// newJobID returns a random 128-bit hex ID
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
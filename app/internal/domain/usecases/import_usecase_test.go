@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/initializer"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+func TestImportService_RunsJob(t *testing.T) {
+	repo := &stubRepo{}
+	svc := NewImportService(repo, map[string]string{"PL": "POLAND"})
+
+	csv := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AAAAPLPWXXX,Bank A,Addr A,POLAND
+PL,BAD,Bank B,Addr B,POLAND
+`
+	job, err := svc.StartImport(context.Background(), "codes.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID == "" || job.Status != models.ImportPending || job.FileName != "codes.csv" {
+		t.Errorf("unexpected new job %+v", job)
+	}
+	svc.wg.Wait()
+
+	done, err := svc.GetImport(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.Status != models.ImportSucceeded || done.FinishedAt == nil || done.Summary == nil {
+		t.Fatalf("unexpected finished job %+v", done)
+	}
+	if done.Summary.HQAdded != 1 || done.Summary.Rejected != 1 {
+		t.Errorf("unexpected summary %+v", *done.Summary)
+	}
+}
+
+func TestImportService_FailedJob(t *testing.T) {
+	svc := NewImportService(&stubRepo{}, nil)
+	job, err := svc.StartImport(context.Background(), "bad.csv", strings.NewReader("NO,USABLE,HEADER\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.wg.Wait()
+
+	done, _ := svc.GetImport(context.Background(), job.ID)
+	if done.Status != models.ImportFailed || done.Error == "" {
+		t.Errorf("expected failed job, got %+v", done)
+	}
+}
+
+// panickingRepo panics when saving, like a bug in an adapter would
+type panickingRepo struct{ stubRepo }
+
+func (*panickingRepo) SaveHeadquarters(context.Context, []models.SwiftCode) (models.ImportSummary, error) {
+	panic("boom")
+}
+
+func TestImportService_PanicFailsJob(t *testing.T) {
+	svc := NewImportService(&panickingRepo{}, map[string]string{"PL": "POLAND"})
+	csv := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AAAAPLPWXXX,Bank A,Addr A,POLAND
+`
+	job, err := svc.StartImport(context.Background(), "codes.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.wg.Wait()
+
+	done, _ := svc.GetImport(context.Background(), job.ID)
+	if done.Status != models.ImportFailed || !strings.Contains(done.Error, "boom") || done.FinishedAt == nil {
+		t.Errorf("expected failed job with the panic, got %+v", done)
+	}
+
+	// the next import still runs
+	job, _ = svc.StartImport(context.Background(), "bad.csv", strings.NewReader("NO,USABLE,HEADER\n"))
+	svc.wg.Wait()
+	if done, _ := svc.GetImport(context.Background(), job.ID); done.Status != models.ImportFailed || strings.Contains(done.Error, "boom") {
+		t.Errorf("unexpected second job %+v", done)
+	}
+}
+
+func TestImportService_StagePanicFailsJob(t *testing.T) {
+	svc := NewImportService(&stubRepo{}, map[string]string{"PL": "POLAND"})
+	csv := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AAAAPLPWXXX,Bank A,Addr A,POLAND
+PL,BAD,Bad row,Addr,POLAND
+`
+	// the hook runs in the validating goroutine, outside the job's own recover
+	onReject := initializer.WithRejectHook(func(models.RejectedRow) error { panic("boom") })
+	job, err := svc.StartImport(context.Background(), "codes.csv", strings.NewReader(csv), onReject)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.wg.Wait()
+
+	done, _ := svc.GetImport(context.Background(), job.ID)
+	if done.Status != models.ImportFailed || !strings.Contains(done.Error, "boom") || done.FinishedAt == nil {
+		t.Errorf("expected failed job with the panic, got %+v", done)
+	}
+}
+
+func TestImportService_Errors(t *testing.T) {
+	svc := NewImportService(&stubRepo{}, nil)
+	_, err := svc.StartImport(context.Background(), "empty.csv", strings.NewReader(""))
+	if e, ok := err.(*util.AppError); !ok || e.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty upload, got %v", err)
+	}
+	_, err = svc.GetImport(context.Background(), "missing")
+	if e, ok := err.(*util.AppError); !ok || e.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown job, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package usecases
+
+import "github.com/przemekk6973/swift-code-app/app/internal/port"
+
+// Option configures SwiftService and ImportService
+type Option func(*serviceOptions)
+
+type serviceOptions struct {
+	audit     port.AuditRepository
+	history   port.HistoryRepository
+	countries map[string]string
+}
+
+// WithAuditLog records every change made through the service in log
+func WithAuditLog(log port.AuditRepository) Option {
+	return func(o *serviceOptions) { o.audit = log }
+}
+
+// WithHistory records every version of the codes changed through the service in history
+func WithHistory(history port.HistoryRepository) Option {
+	return func(o *serviceOptions) { o.history = history }
+}
+
+// WithCountries looks the country codes up in countries (ISO2 to name): the country segment of a SWIFT code,
+// and the country of added codes, which must be known and match their country name
+func WithCountries(countries map[string]string) Option {
+	return func(o *serviceOptions) { o.countries = countries }
+}
+
+func applyOptions(opts []Option) serviceOptions {
+	var o serviceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -2,6 +2,9 @@ package usecases
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/port"
@@ -10,12 +13,16 @@ import (
 
 // SwiftService does operations on SWIFT coes
 type SwiftService struct {
-	repo port.SwiftRepository
+	repo      port.SwiftRepository
+	audit     auditor
+	history   historian
+	countries map[string]string
 }
 
 // NewSwiftService creates new insance of service
-func NewSwiftService(r port.SwiftRepository) *SwiftService {
-	return &SwiftService{repo: r}
+func NewSwiftService(r port.SwiftRepository, opts ...Option) *SwiftService {
+	o := applyOptions(opts)
+	return &SwiftService{repo: r, audit: auditor{log: o.audit}, history: historian{versions: o.history}, countries: o.countries}
 }
 
 // GetSwiftCodeDetails returns data of HQ or branch by code
@@ -35,60 +42,188 @@ func (s *SwiftService) GetSwiftCodeDetails(ctx context.Context, code string) (mo
 	return swift, nil
 }
 
-// GetSwiftCodesByCountry returns every HQ and branch for every ISO2
-func (s *SwiftService) GetSwiftCodesByCountry(ctx context.Context, iso2 string) (models.CountrySwiftCodesResponse, error) {
+// ValidateBIC breaks code down into its ISO 9362 parts and reports every rule it breaks,
+// the country segment is checked against the countries of the service when it has any
+func (s *SwiftService) ValidateBIC(code string) models.BICValidation {
+	return util.ValidateBIC(code, s.countries)
+}
+
+// LookupSwiftCodes looks many codes up with one repository call, every code gets a result in request order;
+// codes of a wrong format are reported as invalid instead of failing the whole batch
+func (s *SwiftService) LookupSwiftCodes(ctx context.Context, codes []string) (models.SwiftCodeLookupResponse, error) {
+	if len(codes) == 0 {
+		return models.SwiftCodeLookupResponse{}, util.BadRequest("codes must not be empty")
+	}
+	if len(codes) > util.MaxLookupCodes {
+		return models.SwiftCodeLookupResponse{}, util.BadRequest("at most %d codes can be looked up at once", util.MaxLookupCodes)
+	}
+
+	results := make([]models.SwiftCodeLookupResult, len(codes))
+	var valid []string
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		results[i].Code = code
+		if err := util.ValidateSwiftCode(code); err != nil {
+			results[i].Status = models.LookupInvalid
+			results[i].Message = err.Error()
+			continue
+		}
+		if !seen[code] {
+			seen[code] = true
+			valid = append(valid, code)
+		}
+	}
+
+	var found map[string]models.SwiftCode
+	if len(valid) > 0 {
+		var err error
+		if found, err = s.repo.GetByCodes(ctx, valid); err != nil {
+			return models.SwiftCodeLookupResponse{}, util.Internal("error fetching SWIFT codes: %v", err)
+		}
+	}
+	for i := range results {
+		if results[i].Status == models.LookupInvalid {
+			continue
+		}
+		sc, ok := found[results[i].Code]
+		switch {
+		case !ok:
+			results[i].Status = models.LookupNotFound
+		case sc.IsHeadquarter:
+			results[i].Status = models.LookupHeadquarter
+		default:
+			results[i].Status = models.LookupBranch
+		}
+		if ok {
+			results[i].SwiftCode = &sc
+		}
+	}
+	return models.SwiftCodeLookupResponse{Results: results}, nil
+}
+
+// GetSwiftCodesByCountry returns one page of HQs and branches for a country ISO2, ordered by SWIFT code.
+// Only the requested page is read from the repository, whatever the size of the country.
+func (s *SwiftService) GetSwiftCodesByCountry(ctx context.Context, iso2, cursor string, limit int) (models.CountrySwiftCodesResponse, error) {
+	return s.countryPage(iso2, cursor, limit, func(q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+		return s.repo.List(ctx, q)
+	})
+}
+
+// countryPage reads one page of a country through list, asking for one code more to know if there is a next page
+func (s *SwiftService) countryPage(iso2, cursor string, limit int,
+	list func(models.SwiftCodeQuery) ([]models.SwiftCode, error)) (models.CountrySwiftCodesResponse, error) {
 	// walidacja ISO2
 	if err := util.ValidateCountryISO2(iso2); err != nil {
 		return models.CountrySwiftCodesResponse{}, util.BadRequest("invalid country ISO2: %v", err)
 	}
-	list, err := s.repo.GetByCountry(ctx, iso2)
+	limit, err := pageSize(limit)
 	if err != nil {
-		if err == port.ErrNotFound {
-			// no data for this country: 404
-			return models.CountrySwiftCodesResponse{}, util.NotFound("no SWIFT codes for country %s", iso2)
+		return models.CountrySwiftCodesResponse{}, err
+	}
+	q := models.SwiftCodeQuery{CountryISO2: iso2, Limit: limit + 1}
+	if cursor != "" {
+		if q.After, err = util.DecodeCursor(cursor); err != nil {
+			return models.CountrySwiftCodesResponse{}, err
 		}
-		// any other repo error: 500
+	}
+
+	page, err := list(q)
+	if err != nil {
+		// any repo error: 500
 		return models.CountrySwiftCodesResponse{}, util.Internal("error fetching by country: %v", err)
 	}
-	// if repo might return an empty slice without error:
-	if len(list) == 0 {
+	// no data for this country: 404; past the last page is just empty
+	if len(page) == 0 && cursor == "" {
 		return models.CountrySwiftCodesResponse{}, util.NotFound("no SWIFT codes for country %s", iso2)
 	}
 
+	var next string
+	if len(page) > limit {
+		page = page[:limit]
+		next = util.EncodeCursor(page[limit-1].SwiftCode)
+	}
 	// country name from the first element
-	countryName := list[0].CountryName
-
-	// consolidate HQ and branches into one list SwiftBranch
-	var branches []models.SwiftBranch
-	for _, sc := range list {
-		branches = append(branches, models.SwiftBranch{
-			Address:       sc.Address,
-			BankName:      sc.BankName,
-			CountryISO2:   sc.CountryISO2,
-			CountryName:   sc.CountryName,
-			IsHeadquarter: sc.IsHeadquarter,
-			SwiftCode:     sc.SwiftCode,
-		})
+	var countryName string
+	if len(page) > 0 {
+		countryName = page[0].CountryName
 	}
 	return models.CountrySwiftCodesResponse{
 		CountryISO2: iso2,
 		CountryName: countryName,
-		SwiftCodes:  branches,
+		SwiftCodes:  append([]models.SwiftBranch{}, toBranches(page)...),
+		NextCursor:  next,
 	}, nil
 }
 
-// AddSwiftCode adds single HQ or branch
-func (s *SwiftService) AddSwiftCode(ctx context.Context, sc models.SwiftCode) error {
-	// validate
-	if err := util.ValidateSwiftCode(sc.SwiftCode); err != nil {
-		return util.BadRequest("invalid SWIFT code: %v", err)
+// ListSwiftCodes returns one page of HQs and branches matching the filter, ordered by SWIFT code
+func (s *SwiftService) ListSwiftCodes(ctx context.Context, filter models.SwiftCodeQuery, cursor string) (models.SwiftCodeListResponse, error) {
+	if filter.CountryISO2 != "" {
+		if err := util.ValidateCountryISO2(filter.CountryISO2); err != nil {
+			return models.SwiftCodeListResponse{}, util.BadRequest("invalid country ISO2: %v", err)
+		}
 	}
-	if err := util.ValidateSwiftSuffix(sc.SwiftCode, sc.IsHeadquarter); err != nil {
+	limit, err := pageSize(filter.Limit)
+	if err != nil {
+		return models.SwiftCodeListResponse{}, err
+	}
+	if cursor != "" {
+		if filter.After, err = util.DecodeCursor(cursor); err != nil {
+			return models.SwiftCodeListResponse{}, err
+		}
+	}
+
+	// ask for one extra code to know if there is a next page
+	filter.Limit = limit + 1
+	list, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return models.SwiftCodeListResponse{}, util.Internal("error listing SWIFT codes: %v", err)
+	}
+
+	var next string
+	if len(list) > limit {
+		list = list[:limit]
+		next = util.EncodeCursor(list[limit-1].SwiftCode)
+	}
+	// empty page is still a list in JSON, not null
+	page := append([]models.SwiftBranch{}, toBranches(list)...)
+	return models.SwiftCodeListResponse{SwiftCodes: page, NextCursor: next}, nil
+}
+
+// SearchSwiftCodes finds HQs and branches by bank name and address, best matches first
+func (s *SwiftService) SearchSwiftCodes(ctx context.Context, query string, limit int) (models.SwiftCodeSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if len(util.SearchTerms(query)) == 0 {
+		return models.SwiftCodeSearchResponse{}, util.BadRequest("search query must contain letters or digits")
+	}
+	if len(query) > util.MaxSearchQueryLength {
+		return models.SwiftCodeSearchResponse{}, util.BadRequest("search query must be at most %d characters", util.MaxSearchQueryLength)
+	}
+	limit, err := pageSize(limit)
+	if err != nil {
+		return models.SwiftCodeSearchResponse{}, err
+	}
+
+	hits, err := s.repo.Search(ctx, query, limit)
+	if err != nil {
+		return models.SwiftCodeSearchResponse{}, util.Internal("error searching SWIFT codes: %v", err)
+	}
+	// no results is still a list in JSON, not null
+	results := append([]models.SwiftCodeSearchHit{}, hits...)
+	return models.SwiftCodeSearchResponse{Query: query, Results: results}, nil
+}
+
+// AddSwiftCode adds single HQ or branch; with countries the country name must be the one of the ISO2,
+// and is filled in when missing
+func (s *SwiftService) AddSwiftCode(ctx context.Context, sc models.SwiftCode) error {
+	if err := validateSwiftCode(sc); err != nil {
 		return err
 	}
-	if err := util.ValidateCountryISO2(sc.CountryISO2); err != nil {
-		return util.BadRequest("invalid country ISO2: %v", err)
+	name, err := s.countryName(sc)
+	if err != nil {
+		return err
 	}
+	sc.CountryName = name
 
 	if sc.IsHeadquarter {
 		summary, err := s.repo.SaveHeadquarters(ctx, []models.SwiftCode{sc})
@@ -96,15 +231,21 @@ func (s *SwiftService) AddSwiftCode(ctx context.Context, sc models.SwiftCode) er
 			return util.Internal("error saving HQ: %v", err)
 		}
 		if summary.HQSkipped > 0 {
-			return util.Conflict("headquarter %s already exists", sc.SwiftCode)
+			return taken("headquarter", sc.SwiftCode)
 		}
-		return nil
+		if err := s.history.record(ctx, false, sc); err != nil {
+			return err
+		}
+		return s.audit.change(ctx, models.AuditCreate, sc.SwiftCode, nil, &sc)
 	}
 
 	// add branch – search HQ with first 8 characters + "XXX"
 	hqCode := sc.SwiftCode[:8] + "XXX"
 	branch := models.SwiftBranch{
 		Address:       sc.Address,
+		TownName:      sc.TownName,
+		CodeType:      sc.CodeType,
+		TimeZone:      sc.TimeZone,
 		BankName:      sc.BankName,
 		CountryISO2:   sc.CountryISO2,
 		CountryName:   sc.CountryName,
@@ -116,29 +257,212 @@ func (s *SwiftService) AddSwiftCode(ctx context.Context, sc models.SwiftCode) er
 		case port.ErrHQNotFound:
 			return util.BadRequest("headquarter %s not found for branch %s", hqCode, sc.SwiftCode)
 		case port.ErrBranchDuplicate:
-			return util.Conflict("branch %s already exists", sc.SwiftCode)
+			return taken("branch", sc.SwiftCode)
 		default:
 			return util.Internal("error adding branch: %v", err)
 		}
 	}
-	return nil
+	if err := s.history.record(ctx, false, sc); err != nil {
+		return err
+	}
+	return s.audit.change(ctx, models.AuditCreate, sc.SwiftCode, nil, &sc)
 }
 
-// DeleteSwiftCode removes HQ (and its branches) or single branch
-func (s *SwiftService) DeleteSwiftCode(ctx context.Context, code string) error {
-	if err := util.ValidateSwiftCode(code); err != nil {
-		return util.BadRequest("invalid SWIFT code: %v", err)
+// UpdateSwiftCode replaces details of an existing HQ (keeping its branches) or branch.
+// The code in the payload, when given, must be the updated one.
+// A non-zero version must be the current one (see GetSwiftCodeDetails).
+func (s *SwiftService) UpdateSwiftCode(ctx context.Context, code string, version int64, sc models.SwiftCode) (models.SwiftCode, error) {
+	if sc.SwiftCode != "" && sc.SwiftCode != code {
+		return models.SwiftCode{}, util.BadRequest("SWIFT code %s in payload doesn't match %s", sc.SwiftCode, code)
 	}
-	if err := s.repo.Delete(ctx, code); err != nil {
-		if err == port.ErrNotFound {
+	sc.SwiftCode = code
+	sc.Version = version
+	return s.update(ctx, sc)
+}
+
+// PatchSwiftCode changes the given fields of an existing HQ or branch.
+// A non-zero version must be the current one; the patch is saved only if nothing changed since it was read.
+func (s *SwiftService) PatchSwiftCode(ctx context.Context, code string, version int64, patch models.SwiftCodePatch) (models.SwiftCode, error) {
+	current, err := s.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		return models.SwiftCode{}, err
+	}
+	if version != 0 && version != current.Version {
+		return models.SwiftCode{}, versionChanged(code)
+	}
+	return s.update(ctx, patch.Apply(current))
+}
+
+// update validates sc like AddSwiftCode, saves it and returns the stored code
+func (s *SwiftService) update(ctx context.Context, sc models.SwiftCode) (models.SwiftCode, error) {
+	if err := validateSwiftCode(sc); err != nil {
+		return models.SwiftCode{}, err
+	}
+	name, err := s.countryName(sc)
+	if err != nil {
+		return models.SwiftCode{}, err
+	}
+	sc.CountryName = name
+
+	before, err := s.GetSwiftCodeDetails(ctx, sc.SwiftCode)
+	if err != nil {
+		return models.SwiftCode{}, err
+	}
+	if err := s.repo.Update(ctx, sc); err != nil {
+		switch err {
+		case port.ErrNotFound:
+			return models.SwiftCode{}, util.NotFound("SWIFT code %s not found", sc.SwiftCode)
+		case port.ErrVersionMismatch:
+			return models.SwiftCode{}, versionChanged(sc.SwiftCode)
+		default:
+			return models.SwiftCode{}, util.Internal("error updating SWIFT code: %v", err)
+		}
+	}
+	after, err := s.GetSwiftCodeDetails(ctx, sc.SwiftCode)
+	if err != nil {
+		return models.SwiftCode{}, err
+	}
+	// branches of an updated HQ don't change
+	if err := s.history.record(ctx, false, after); err != nil {
+		return models.SwiftCode{}, err
+	}
+	return after, s.audit.change(ctx, models.AuditUpdate, sc.SwiftCode, &before, &after)
+}
+
+// DeleteSwiftCode marks HQ (and its branches) or single branch as deleted by the actor of ctx,
+// a non-zero version must be the current one
+func (s *SwiftService) DeleteSwiftCode(ctx context.Context, code string, version int64) error {
+	before, err := s.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, code, version, util.Actor(ctx)); err != nil {
+		switch err {
+		case port.ErrNotFound:
 			return util.NotFound("SWIFT code %s not found", code)
+		case port.ErrVersionMismatch:
+			return versionChanged(code)
+		default:
+			return util.Internal("error deleting SWIFT code: %v", err)
 		}
-		return util.Internal("error deleting SWIFT code: %v", err)
 	}
-	return nil
+	if err := s.history.recordWithBranches(ctx, true, before); err != nil {
+		return err
+	}
+	return s.audit.change(ctx, models.AuditDelete, code, &before, nil)
+}
+
+// RestoreSwiftCode brings back a deleted HQ (with the branches deleted together with it) or branch
+func (s *SwiftService) RestoreSwiftCode(ctx context.Context, code string) (models.SwiftCode, error) {
+	if err := util.ValidateSwiftCode(code); err != nil {
+		return models.SwiftCode{}, util.BadRequest("invalid SWIFT code: %v", err)
+	}
+	if err := s.repo.Restore(ctx, code); err != nil {
+		switch err {
+		case port.ErrNotFound:
+			return models.SwiftCode{}, util.NotFound("no deleted SWIFT code %s", code)
+		case port.ErrHQNotFound:
+			return models.SwiftCode{}, util.Conflict("headquarter %s is deleted, restore it first", code[:8]+"XXX")
+		default:
+			return models.SwiftCode{}, util.Internal("error restoring SWIFT code: %v", err)
+		}
+	}
+	after, err := s.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		return models.SwiftCode{}, err
+	}
+	// the branches of a restored HQ are the ones deleted with it
+	if err := s.history.recordWithBranches(ctx, false, after); err != nil {
+		return models.SwiftCode{}, err
+	}
+	return after, s.audit.change(ctx, models.AuditRestore, code, nil, &after)
+}
+
+// PurgeDeleted permanently removes codes deleted more than retention ago and returns how many
+func (s *SwiftService) PurgeDeleted(ctx context.Context, retention time.Duration) (int, error) {
+	before := time.Now().Add(-retention)
+	n, err := s.repo.Purge(ctx, before)
+	if err != nil || n == 0 {
+		return n, err
+	}
+	return n, s.audit.record(ctx, models.AuditEntry{
+		Action:  models.AuditPurge,
+		Details: fmt.Sprintf("%d codes deleted before %s", n, before.UTC().Format(time.RFC3339)),
+	})
+}
+
+// taken is the 409 answer to adding an existing code
+func taken(kind, code string) error {
+	return util.Conflict("%s %s already exists", kind, code)
+}
+
+// versionChanged is the 412 answer to a write with an outdated version
+func versionChanged(code string) error {
+	return util.PreconditionFailed("SWIFT code %s was changed, fetch it again for the current ETag", code)
 }
 
 // HealthCheck pings the database to check if it is available
 func (s *SwiftService) HealthCheck(ctx context.Context) error {
 	return s.repo.Ping(ctx)
 }
+
+// validateSwiftCode checks code format, HQ suffix and country of a created or updated code
+func validateSwiftCode(sc models.SwiftCode) error {
+	if err := util.ValidateSwiftCode(sc.SwiftCode); err != nil {
+		return util.BadRequest("invalid SWIFT code: %v", err)
+	}
+	if err := util.ValidateSwiftSuffix(sc.SwiftCode, sc.IsHeadquarter); err != nil {
+		return err
+	}
+	if err := util.ValidateCountryISO2(sc.CountryISO2); err != nil {
+		return util.BadRequest("invalid country ISO2: %v", err)
+	}
+	return util.ValidateBICCountry(sc.SwiftCode, sc.CountryISO2)
+}
+
+// countryName checks the country ISO2 and name of sc against the countries of the service
+// and returns the name to store, the known one when sc has none. Without countries any name is taken.
+func (s *SwiftService) countryName(sc models.SwiftCode) (string, error) {
+	if len(s.countries) == 0 {
+		return sc.CountryName, nil
+	}
+	if strings.TrimSpace(sc.CountryName) == "" {
+		if name, ok := s.countries[sc.CountryISO2]; ok {
+			return name, nil
+		}
+	}
+	if err := util.ValidateCountryNameMatch(sc.CountryISO2, sc.CountryName, s.countries); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sc.CountryName), nil
+}
+
+// pageSize applies the default page size and rejects limits out of range
+func pageSize(limit int) (int, error) {
+	if limit == 0 {
+		return util.DefaultPageSize, nil
+	}
+	if limit < 0 || limit > util.MaxPageSize {
+		return 0, util.BadRequest("limit must be between 1 and %d", util.MaxPageSize)
+	}
+	return limit, nil
+}
+
+// toBranches consolidates HQ and branches into one list of SwiftBranch
+func toBranches(list []models.SwiftCode) []models.SwiftBranch {
+	var branches []models.SwiftBranch
+	for _, sc := range list {
+		branches = append(branches, models.SwiftBranch{
+			Address:       sc.Address,
+			TownName:      sc.TownName,
+			CodeType:      sc.CodeType,
+			TimeZone:      sc.TimeZone,
+			BankName:      sc.BankName,
+			CountryISO2:   sc.CountryISO2,
+			CountryName:   sc.CountryName,
+			IsHeadquarter: sc.IsHeadquarter,
+			SwiftCode:     sc.SwiftCode,
+		})
+	}
+	return branches
+}
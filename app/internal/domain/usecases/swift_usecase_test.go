@@ -3,7 +3,9 @@ package usecases
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/port"
@@ -13,9 +15,19 @@ import (
 type stubRepo struct {
 	existing     map[string]bool
 	byCode       map[string]models.SwiftCode
-	byCountry    map[string][]models.SwiftCode
+	listed       []models.SwiftCode
+	lastQuery    models.SwiftCodeQuery
+	hits         []models.SwiftCodeSearchHit
+	lastLimit    int
 	addBranchErr error
+	updateErr    error
 	deleteErr    error
+	deletedBy    string
+	restoreErr   error
+	purgedBefore time.Time
+	batches      int
+	saved        []models.SwiftCode
+	updated      []models.SwiftCode
 }
 
 func (s *stubRepo) Ping(ctx context.Context) error {
@@ -23,6 +35,7 @@ func (s *stubRepo) Ping(ctx context.Context) error {
 }
 
 func (s *stubRepo) SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
+	s.saved = append(s.saved, hqs...)
 	if s.existing == nil {
 		s.existing = make(map[string]bool)
 	}
@@ -46,18 +59,56 @@ func (s *stubRepo) GetByCode(ctx context.Context, code string) (models.SwiftCode
 	}
 	return models.SwiftCode{}, port.ErrNotFound
 }
-func (s *stubRepo) GetByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, error) {
-	if v, ok := s.byCountry[iso2]; ok {
-		return v, nil
+func (s *stubRepo) GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error) {
+	s.batches++
+	found := make(map[string]models.SwiftCode)
+	for _, code := range codes {
+		if v, ok := s.byCode[code]; ok {
+			found[code] = v
+		}
+	}
+	return found, nil
+}
+func (s *stubRepo) List(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	s.lastQuery = q
+	var page []models.SwiftCode
+	for _, sc := range s.listed {
+		if sc.SwiftCode > q.After && len(page) < q.Limit {
+			page = append(page, sc)
+		}
 	}
-	return nil, port.ErrNotFound
+	return page, nil
+}
+func (s *stubRepo) Walk(ctx context.Context, fn func(models.SwiftCode) error) error {
+	for _, sc := range s.listed {
+		if err := fn(sc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (s *stubRepo) Search(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error) {
+	s.lastLimit = limit
+	return s.hits, nil
 }
 func (s *stubRepo) AddBranch(ctx context.Context, hqCode string, branch models.SwiftBranch) error {
 	return s.addBranchErr
 }
-func (s *stubRepo) Delete(ctx context.Context, code string) error {
+func (s *stubRepo) Update(ctx context.Context, sc models.SwiftCode) error {
+	s.updated = append(s.updated, sc)
+	return s.updateErr
+}
+func (s *stubRepo) Delete(ctx context.Context, code string, version int64, actor string) error {
+	s.deletedBy = actor
 	return s.deleteErr
 }
+func (s *stubRepo) Restore(ctx context.Context, code string) error {
+	return s.restoreErr
+}
+func (s *stubRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+	s.purgedBefore = before
+	return 3, nil
+}
 
 func TestGetSwiftCodeDetails_NotFound(t *testing.T) {
 	svc := NewSwiftService(&stubRepo{})
@@ -70,7 +121,7 @@ func TestGetSwiftCodeDetails_NotFound(t *testing.T) {
 func TestAddSwiftCode_BranchWithoutHQ(t *testing.T) {
 	svc := NewSwiftService(&stubRepo{addBranchErr: port.ErrHQNotFound})
 	err := svc.AddSwiftCode(context.Background(), models.SwiftCode{
-		SwiftCode:     "ABCDEFGHBR1",
+		SwiftCode:     "ABCDPLPWBR1",
 		BankName:      "B",
 		Address:       "A",
 		CountryISO2:   "PL",
@@ -84,7 +135,7 @@ func TestAddSwiftCode_BranchWithoutHQ(t *testing.T) {
 
 func TestDeleteSwiftCode_NotFound(t *testing.T) {
 	svc := NewSwiftService(&stubRepo{deleteErr: port.ErrNotFound})
-	err := svc.DeleteSwiftCode(context.Background(), "ABCDEFGHXXX")
+	err := svc.DeleteSwiftCode(context.Background(), "ABCDEFGHXXX", 0)
 	if e, ok := err.(*util.AppError); !ok || e.StatusCode != 404 {
 		t.Errorf("expected 404 NotFound, got %v", err)
 	}
@@ -117,7 +168,7 @@ func TestAddSwiftCode_HQ_Duplicate(t *testing.T) {
 func TestAddSwiftCode_InvalidSuffix(t *testing.T) {
 	svc := NewSwiftService(&stubRepo{})
 	code := models.SwiftCode{
-		SwiftCode:     "INVALID1ABC", // not ending with XXX
+		SwiftCode:     "INVAPLPWABC", // not ending with XXX
 		BankName:      "Bank X",
 		Address:       "HQ Street",
 		CountryISO2:   "PL",
@@ -129,3 +180,355 @@ func TestAddSwiftCode_InvalidSuffix(t *testing.T) {
 		t.Errorf("expected 400 BadRequest for invalid HQ suffix, got %v", err)
 	}
 }
+
+func TestAddSwiftCode_CountryCodeMismatch(t *testing.T) {
+	svc := NewSwiftService(&stubRepo{existing: map[string]bool{}})
+	code := models.SwiftCode{SwiftCode: "ABCDDEFFXXX", BankName: "Bank", Address: "Addr", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	err := svc.AddSwiftCode(context.Background(), code)
+	if e, ok := err.(*util.AppError); !ok || e.StatusCode != http.StatusBadRequest || !strings.Contains(e.Message, "of country DE, not PL") {
+		t.Errorf("expected 400 for a DE code in PL, got %v", err)
+	}
+
+	// a Jersey bank with a GB code
+	code = models.SwiftCode{SwiftCode: "ABCDGB2LXXX", BankName: "Bank", Address: "Addr", CountryISO2: "JE", CountryName: "JERSEY", IsHeadquarter: true}
+	if err := svc.AddSwiftCode(context.Background(), code); err != nil {
+		t.Errorf("unexpected error for a shared country code: %v", err)
+	}
+}
+
+func TestAddSwiftCode_Countries(t *testing.T) {
+	repo := &stubRepo{}
+	svc := NewSwiftService(repo, WithCountries(map[string]string{"PL": "POLAND", "DE": "GERMANY"}))
+	hq := func(code, iso2, name string) models.SwiftCode {
+		return models.SwiftCode{SwiftCode: code, BankName: "Bank", Address: "Addr", CountryISO2: iso2, CountryName: name, IsHeadquarter: true}
+	}
+
+	// the name is filled in when missing, any case of the known one is accepted
+	if err := svc.AddSwiftCode(context.Background(), hq("ABCDPLPWXXX", "PL", "")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.AddSwiftCode(context.Background(), hq("ABCDDEFFXXX", "DE", " Germany ")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.saved) != 2 || repo.saved[0].CountryName != "POLAND" || repo.saved[1].CountryName != "Germany" {
+		t.Errorf("saved %+v; want POLAND filled in and Germany trimmed", repo.saved)
+	}
+
+	tests := map[string]struct {
+		sc   models.SwiftCode
+		want string
+	}{
+		"name of another country": {hq("EFGHPLPWXXX", "PL", "GERMANY"), `country name "GERMANY" does not match ISO2 PL`},
+		"unknown country":         {hq("EFGHZZPWXXX", "ZZ", "ZEDLAND"), "unknown country ISO2: ZZ"},
+		"unknown without a name":  {hq("EFGHZZPWXXX", "ZZ", ""), "unknown country ISO2: ZZ"},
+	}
+	for name, tc := range tests {
+		err := svc.AddSwiftCode(context.Background(), tc.sc)
+		if e, ok := err.(*util.AppError); !ok || e.StatusCode != http.StatusBadRequest || !strings.Contains(e.Message, tc.want) {
+			t.Errorf("%s: got %v; want 400 with %q", name, err, tc.want)
+		}
+	}
+	if len(repo.saved) != 2 {
+		t.Errorf("rejected codes were saved: %+v", repo.saved[2:])
+	}
+}
+
+func TestUpdateSwiftCode_Countries(t *testing.T) {
+	// Jersey banks use the country code of Great Britain
+	hq := models.SwiftCode{SwiftCode: "ABCDGB2LXXX", BankName: "Bank", Address: "Addr", CountryISO2: "GB", CountryName: "UNITED KINGDOM", IsHeadquarter: true}
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{hq.SwiftCode: hq}}
+	svc := NewSwiftService(repo, WithCountries(map[string]string{"GB": "UNITED KINGDOM", "JE": "JERSEY"}))
+	ctx := context.Background()
+
+	// PUT fills in a missing name like POST
+	put := hq
+	put.CountryName = ""
+	if _, err := svc.UpdateSwiftCode(ctx, hq.SwiftCode, 0, put); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.updated) != 1 || repo.updated[0].CountryName != "UNITED KINGDOM" {
+		t.Errorf("updated %+v; want UNITED KINGDOM filled in", repo.updated)
+	}
+	put.CountryName = "JERSEY"
+	if _, err := svc.UpdateSwiftCode(ctx, hq.SwiftCode, 0, put); !isStatus(err, http.StatusBadRequest) || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("PUT with the name of another country: expected 400, got %v", err)
+	}
+
+	// PATCH keeps the stored name, which must still match a changed ISO2
+	iso2 := "JE"
+	if _, err := svc.PatchSwiftCode(ctx, hq.SwiftCode, 0, models.SwiftCodePatch{CountryISO2: &iso2}); !isStatus(err, http.StatusBadRequest) || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("PATCH of ISO2 only: expected 400, got %v", err)
+	}
+	unknown, name := "IM", "Isle of Man"
+	if _, err := svc.PatchSwiftCode(ctx, hq.SwiftCode, 0, models.SwiftCodePatch{CountryISO2: &unknown, CountryName: &name}); !isStatus(err, http.StatusBadRequest) || !strings.Contains(err.Error(), "unknown country") {
+		t.Errorf("PATCH to unknown country: expected 400, got %v", err)
+	}
+	name = "Jersey"
+	if _, err := svc.PatchSwiftCode(ctx, hq.SwiftCode, 0, models.SwiftCodePatch{CountryISO2: &iso2, CountryName: &name}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.updated) != 2 || repo.updated[1].CountryName != "Jersey" {
+		t.Errorf("updated %+v; want the patch to Jersey saved, rejected ones not", repo.updated)
+	}
+}
+
+func TestUpdateSwiftCode(t *testing.T) {
+	hq := models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "Bank", Address: "Addr", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+
+	svc := NewSwiftService(&stubRepo{updateErr: port.ErrNotFound})
+	if _, err := svc.UpdateSwiftCode(context.Background(), hq.SwiftCode, 0, hq); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("missing code: expected 404, got %v", err)
+	}
+
+	svc = NewSwiftService(&stubRepo{byCode: map[string]models.SwiftCode{hq.SwiftCode: hq}})
+	other := hq
+	other.SwiftCode = "ZZZZPLPWXXX"
+	if _, err := svc.UpdateSwiftCode(context.Background(), hq.SwiftCode, 0, other); !isStatus(err, http.StatusBadRequest) {
+		t.Errorf("other code in payload: expected 400, got %v", err)
+	}
+	branch := hq
+	branch.IsHeadquarter = false
+	if _, err := svc.UpdateSwiftCode(context.Background(), hq.SwiftCode, 0, branch); !isStatus(err, http.StatusBadRequest) {
+		t.Errorf("branch flag on HQ code: expected 400, got %v", err)
+	}
+	hq.SwiftCode = ""
+	if _, err := svc.UpdateSwiftCode(context.Background(), "ABCDPLPWXXX", 0, hq); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPatchSwiftCode_NotFound(t *testing.T) {
+	svc := NewSwiftService(&stubRepo{})
+	addr := "New Addr"
+	_, err := svc.PatchSwiftCode(context.Background(), "ABCDPLPW001", 0, models.SwiftCodePatch{Address: &addr})
+	if !isStatus(err, http.StatusNotFound) {
+		t.Errorf("expected 404, got %v", err)
+	}
+}
+
+func TestVersionMismatch(t *testing.T) {
+	hq := models.SwiftCode{SwiftCode: "ABCDPLPWXXX", BankName: "Bank", CountryISO2: "PL", IsHeadquarter: true, Version: 3}
+	svc := NewSwiftService(&stubRepo{
+		byCode:    map[string]models.SwiftCode{hq.SwiftCode: hq},
+		updateErr: port.ErrVersionMismatch,
+		deleteErr: port.ErrVersionMismatch,
+	})
+	ctx := context.Background()
+
+	if _, err := svc.UpdateSwiftCode(ctx, hq.SwiftCode, 2, hq); !isStatus(err, http.StatusPreconditionFailed) {
+		t.Errorf("update: expected 412, got %v", err)
+	}
+	if err := svc.DeleteSwiftCode(ctx, hq.SwiftCode, 2); !isStatus(err, http.StatusPreconditionFailed) {
+		t.Errorf("delete: expected 412, got %v", err)
+	}
+	// an outdated version is rejected before the patch is applied
+	name := "New"
+	if _, err := svc.PatchSwiftCode(ctx, hq.SwiftCode, 2, models.SwiftCodePatch{BankName: &name}); !isStatus(err, http.StatusPreconditionFailed) {
+		t.Errorf("patch: expected 412, got %v", err)
+	}
+}
+
+// isStatus reports whether err is an AppError with the status code
+func isStatus(err error, status int) bool {
+	e, ok := err.(*util.AppError)
+	return ok && e.StatusCode == status
+}
+
+func TestListSwiftCodes_Pages(t *testing.T) {
+	repo := &stubRepo{listed: []models.SwiftCode{
+		{SwiftCode: "AAAAPLPW001"},
+		{SwiftCode: "AAAAPLPWXXX", IsHeadquarter: true},
+		{SwiftCode: "BBBBPLPWXXX", IsHeadquarter: true},
+	}}
+	svc := NewSwiftService(repo)
+
+	first, err := svc.ListSwiftCodes(context.Background(), models.SwiftCodeQuery{Limit: 2}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.SwiftCodes) != 2 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v; want 2 codes and a cursor", first)
+	}
+	if repo.lastQuery.Limit != 3 {
+		t.Errorf("repository limit = %d; want page size + 1", repo.lastQuery.Limit)
+	}
+
+	second, err := svc.ListSwiftCodes(context.Background(), models.SwiftCodeQuery{Limit: 2}, first.NextCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.SwiftCodes) != 1 || second.SwiftCodes[0].SwiftCode != "BBBBPLPWXXX" || second.NextCursor != "" {
+		t.Errorf("second page = %+v; want only BBBBPLPWXXX and no cursor", second)
+	}
+}
+
+func TestListSwiftCodes_InvalidInput(t *testing.T) {
+	svc := NewSwiftService(&stubRepo{})
+	bad := []struct {
+		filter models.SwiftCodeQuery
+		cursor string
+	}{
+		{models.SwiftCodeQuery{Limit: util.MaxPageSize + 1}, ""},
+		{models.SwiftCodeQuery{Limit: -1}, ""},
+		{models.SwiftCodeQuery{CountryISO2: "POL"}, ""},
+		{models.SwiftCodeQuery{}, "%%%"},
+	}
+	for _, tc := range bad {
+		_, err := svc.ListSwiftCodes(context.Background(), tc.filter, tc.cursor)
+		if e, ok := err.(*util.AppError); !ok || e.StatusCode != 400 {
+			t.Errorf("ListSwiftCodes(%+v, %q) = %v; want 400", tc.filter, tc.cursor, err)
+		}
+	}
+}
+
+func TestGetSwiftCodesByCountry_Pages(t *testing.T) {
+	repo := &stubRepo{listed: []models.SwiftCode{
+		{SwiftCode: "AAAAPLPW001", CountryISO2: "PL", CountryName: "POLAND"},
+		{SwiftCode: "AAAAPLPWXXX", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+	}}
+	svc := NewSwiftService(repo)
+
+	first, err := svc.GetSwiftCodesByCountry(context.Background(), "PL", "", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastQuery.CountryISO2 != "PL" || repo.lastQuery.Limit != 2 {
+		t.Errorf("repository query = %+v; want country PL, limit 2", repo.lastQuery)
+	}
+	if first.CountryName != "POLAND" || len(first.SwiftCodes) != 1 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v; want 1 code and a cursor", first)
+	}
+
+	last, err := svc.GetSwiftCodesByCountry(context.Background(), "PL", first.NextCursor, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(last.SwiftCodes) != 1 || last.SwiftCodes[0].SwiftCode != "AAAAPLPWXXX" || last.NextCursor != "" {
+		t.Errorf("last page = %+v; want only AAAAPLPWXXX and no cursor", last)
+	}
+
+	// past the last page is empty, not 404
+	if _, err := svc.GetSwiftCodesByCountry(context.Background(), "PL", util.EncodeCursor("AAAAPLPWXXX"), 1); err != nil {
+		t.Errorf("empty page error = %v; want nil", err)
+	}
+}
+
+func TestGetSwiftCodesByCountry_NotFound(t *testing.T) {
+	svc := NewSwiftService(&stubRepo{})
+	_, err := svc.GetSwiftCodesByCountry(context.Background(), "PL", "", 0)
+	if e, ok := err.(*util.AppError); !ok || e.StatusCode != 404 {
+		t.Errorf("expected 404 NotFound, got %v", err)
+	}
+}
+
+func TestSearchSwiftCodes(t *testing.T) {
+	repo := &stubRepo{}
+	svc := NewSwiftService(repo)
+
+	resp, err := svc.SearchSwiftCodes(context.Background(), "  berlin ", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Query != "berlin" || resp.Results == nil || repo.lastLimit != util.DefaultPageSize {
+		t.Errorf("unexpected response %+v or limit %d", resp, repo.lastLimit)
+	}
+
+	for _, q := range []string{"", " - ", strings.Repeat("a", util.MaxSearchQueryLength+1)} {
+		_, err := svc.SearchSwiftCodes(context.Background(), q, 0)
+		if e, ok := err.(*util.AppError); !ok || e.StatusCode != http.StatusBadRequest {
+			t.Errorf("SearchSwiftCodes(%q) = %v; want 400", q, err)
+		}
+	}
+}
+
+func TestLookupSwiftCodes(t *testing.T) {
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{
+		"AAAAPLPWXXX": {SwiftCode: "AAAAPLPWXXX", IsHeadquarter: true},
+		"AAAAPLPW001": {SwiftCode: "AAAAPLPW001"},
+	}}
+	svc := NewSwiftService(repo)
+
+	resp, err := svc.LookupSwiftCodes(context.Background(), []string{"aaaaplpwxxx", "AAAAPLPW001", "ZZZZPLPWXXX", "BAD", "AAAAPLPWXXX"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{models.LookupHeadquarter, models.LookupBranch, models.LookupNotFound, models.LookupInvalid, models.LookupHeadquarter}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("got %d results; want %d", len(resp.Results), len(want))
+	}
+	for i, status := range want {
+		if resp.Results[i].Status != status {
+			t.Errorf("result %d = %+v; want status %s", i, resp.Results[i], status)
+		}
+	}
+	if resp.Results[0].Code != "AAAAPLPWXXX" || resp.Results[0].SwiftCode == nil || resp.Results[2].SwiftCode != nil {
+		t.Errorf("unexpected results %+v", resp.Results)
+	}
+	if resp.Results[3].Message == "" {
+		t.Errorf("invalid code has no message")
+	}
+	if repo.batches != 1 {
+		t.Errorf("repository called %d times; want a single batch", repo.batches)
+	}
+
+	for _, codes := range [][]string{nil, make([]string, util.MaxLookupCodes+1)} {
+		_, err := svc.LookupSwiftCodes(context.Background(), codes)
+		if e, ok := err.(*util.AppError); !ok || e.StatusCode != http.StatusBadRequest {
+			t.Errorf("LookupSwiftCodes(%d codes) = %v; want 400", len(codes), err)
+		}
+	}
+}
+
+func TestValidateBIC_Countries(t *testing.T) {
+	svc := NewSwiftService(&stubRepo{}, WithCountries(map[string]string{"PL": "POLAND"}))
+	if v := svc.ValidateBIC("BREXPLPWXXX"); !v.Valid || v.CountryName != "POLAND" {
+		t.Errorf("BREXPLPWXXX = %+v; want valid in POLAND", v)
+	}
+	if v := svc.ValidateBIC("BREXZZPWXXX"); v.Valid || v.Violations[0].Rule != models.BICRuleUnknownCountry {
+		t.Errorf("BREXZZPWXXX = %+v; want unknown country", v)
+	}
+}
+
+func TestRestoreSwiftCode(t *testing.T) {
+	branch := models.SwiftCode{SwiftCode: "ABCDPLPW001", BankName: "Branch", CountryISO2: "PL", Version: 4}
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{branch.SwiftCode: branch}}
+	svc := NewSwiftService(repo)
+	ctx := context.Background()
+
+	got, err := svc.RestoreSwiftCode(ctx, branch.SwiftCode)
+	if err != nil || got.SwiftCode != branch.SwiftCode || got.Version != 4 {
+		t.Errorf("RestoreSwiftCode = %+v, %v; want restored branch", got, err)
+	}
+
+	repo.restoreErr = port.ErrHQNotFound
+	_, err = svc.RestoreSwiftCode(ctx, branch.SwiftCode)
+	if !isStatus(err, http.StatusConflict) || !strings.Contains(err.Error(), "ABCDPLPWXXX") {
+		t.Errorf("branch of deleted HQ: expected 409 naming the HQ, got %v", err)
+	}
+	repo.restoreErr = port.ErrNotFound
+	if _, err := svc.RestoreSwiftCode(ctx, branch.SwiftCode); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("not deleted: expected 404, got %v", err)
+	}
+	if _, err := svc.RestoreSwiftCode(ctx, "bad"); !isStatus(err, http.StatusBadRequest) {
+		t.Errorf("invalid code: expected 400, got %v", err)
+	}
+}
+
+func TestDeleteAndPurge(t *testing.T) {
+	hq := models.SwiftCode{SwiftCode: "ABCDPLPWXXX", CountryISO2: "PL", IsHeadquarter: true}
+	repo := &stubRepo{byCode: map[string]models.SwiftCode{hq.SwiftCode: hq}}
+	svc := NewSwiftService(repo)
+	ctx := util.WithActor(context.Background(), "alice")
+
+	if err := svc.DeleteSwiftCode(ctx, "ABCDPLPWXXX", 0); err != nil || repo.deletedBy != "alice" {
+		t.Errorf("DeleteSwiftCode = %v, actor %q; want deleted by alice", err, repo.deletedBy)
+	}
+
+	n, err := svc.PurgeDeleted(ctx, 24*time.Hour)
+	if err != nil || n != 3 {
+		t.Fatalf("PurgeDeleted = %d, %v", n, err)
+	}
+	if age := time.Since(repo.purgedBefore); age < 24*time.Hour || age > 25*time.Hour {
+		t.Errorf("purged codes deleted before %v; want a day ago", repo.purgedBefore)
+	}
+}
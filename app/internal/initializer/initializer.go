@@ -8,34 +8,154 @@ import (
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 	"github.com/przemekk6973/swift-code-app/app/internal/port"
-	"github.com/przemekk6973/swift-code-app/app/internal/util"
 )
 
-// ImportCSV parses CSV with csvPath and saves the code through the repository
-func ImportCSV(repo port.SwiftRepository, csvPath string, countries map[string]string) (*models.ImportSummary, error) {
+// DefaultBatchSize is how many codes ImportCSV saves through the repository at once
+const DefaultBatchSize = 1000
+
+// Option configures ImportCSV
+type Option func(*options)
+
+type options struct {
+	reportPath string
+	batchSize  int
+	sync       bool
+	dryRun     bool
+	onChange   func(before, after *models.SwiftCode) error
+	onSave     func(batch []models.SwiftCode, added int) error
+	onReject   func(row models.RejectedRow) error
+}
+
+// WithRejectReport writes rejected rows to path while importing,
+// as JSON when path ends with .json and as CSV otherwise
+func WithRejectReport(path string) Option {
+	return func(o *options) { o.reportPath = path }
+}
+
+// WithBatchSize sets how many codes are saved at once, values below 1 keep the default
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithSync makes the stored codes match the file: changed codes are updated and codes
+// missing from the file are removed, instead of only inserting new ones.
+// Codes of rejected rows are never removed.
+func WithSync() Option {
+	return func(o *options) { o.sync = true }
+}
+
+// WithDryRun syncs without writing anything, the summary counts what a sync would change
+func WithDryRun() Option {
+	return func(o *options) {
+		o.sync = true
+		o.dryRun = true
+	}
+}
+
+// WithChangeHook calls onChange for every code a sync updates (before and after the change)
+// or removes (after is nil), once the change is saved; an error stops the import.
+// Before is the code as stored, an HQ without its branches. Added codes are only counted in the summary.
+func WithChangeHook(onChange func(before, after *models.SwiftCode) error) Option {
+	return func(o *options) { o.onChange = onChange }
+}
+
+// WithSaveHook calls onSave after every batch of new codes is saved, with how many codes of the batch
+// were added; the others were skipped (existing codes, branches without HQ) and which ones isn't known.
+// The batch is reused afterwards, onSave must not keep it. An error stops the import.
+func WithSaveHook(onSave func(batch []models.SwiftCode, added int) error) Option {
+	return func(o *options) { o.onSave = onSave }
+}
+
+// WithRejectHook calls onReject for every rejected row, once per row even when the file is read twice;
+// it runs in the validating stage, alongside the saving. An error stops the import.
+func WithRejectHook(onReject func(row models.RejectedRow) error) Option {
+	return func(o *options) { o.onReject = onReject }
+}
+
+// ImportCSV streams CSV with csvPath and saves the codes through the repository in batches.
+// The file is read twice, HQs first and then branches, so a branch is saved after its HQ
+// wherever the HQ is in the file, without holding the file in memory.
+// With WithSync the file is read once and compared with the stored codes instead.
+func ImportCSV(repo port.SwiftRepository, csvPath string, countries map[string]string, opts ...Option) (*models.ImportSummary, error) {
+	o := options{batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	start := time.Now()
+	ctx := context.Background()
 
-	hqList, branchList, err := util.LoadSwiftCodes(csvPath, countries)
-	if err != nil {
-		return nil, fmt.Errorf("csv parse error: %w", err)
+	var report rejectReport
+	if o.reportPath != "" {
+		var err error
+		if report, err = createReport(o.reportPath); err != nil {
+			return nil, fmt.Errorf("reject report error: %w", err)
+		}
+		defer func() {
+			if report != nil {
+				report.Close()
+			}
+		}()
 	}
 
-	hqSum, err := repo.SaveHeadquarters(context.Background(), hqList)
-	if err != nil {
-		return nil, fmt.Errorf("save HQ error: %w", err)
+	// rejections are counted in the first pass only, the second one sees the same rows
+	var rejected models.ImportSummary
+	onReject := func(row models.RejectedRow) error {
+		rejected.CountRejected(row)
+		if report != nil {
+			if err := report.Write(row); err != nil {
+				return err
+			}
+		}
+		if o.onReject != nil {
+			return o.onReject(row)
+		}
+		return nil
+	}
+
+	summary := &models.ImportSummary{}
+	run := func(ctx context.Context) error {
+		if o.sync {
+			syncSum, err := syncCSV(ctx, repo, csvPath, countries, o.batchSize, o.dryRun, onReject, o.onChange, o.onSave)
+			if err != nil {
+				return fmt.Errorf("sync error: %w", err)
+			}
+			*summary = syncSum
+			return nil
+		}
+		hqSum, err := importPass(ctx, repo, csvPath, countries, true, o.batchSize, onReject, o.onSave)
+		if err != nil {
+			return fmt.Errorf("import HQ error: %w", err)
+		}
+		brSum, err := importPass(ctx, repo, csvPath, countries, false, o.batchSize, nil, o.onSave)
+		if err != nil {
+			return fmt.Errorf("import branches error: %w", err)
+		}
+		summary.Add(hqSum)
+		summary.Add(brSum)
+		return nil
+	}
+	// a repository that persists batches more cheaply writes the whole import at once
+	var err error
+	if b, ok := repo.(port.Batcher); ok {
+		err = b.Batch(ctx, run)
+	} else {
+		err = run(ctx)
 	}
-	brSum, err := repo.SaveBranches(context.Background(), branchList)
 	if err != nil {
-		return nil, fmt.Errorf("save branches error: %w", err)
+		return nil, err
 	}
+	summary.Add(rejected)
 
-	summary := &models.ImportSummary{
-		HQAdded:           hqSum.HQAdded,
-		HQSkipped:         hqSum.HQSkipped,
-		BranchesAdded:     brSum.BranchesAdded,
-		BranchesDuplicate: brSum.BranchesDuplicate,
-		BranchesMissingHQ: brSum.BranchesMissingHQ,
-		BranchesSkipped:   brSum.BranchesSkipped,
+	if report != nil {
+		err := report.Close()
+		report = nil
+		if err != nil {
+			return summary, fmt.Errorf("reject report error: %w", err)
+		}
 	}
 
 	elapsed := time.Since(start)
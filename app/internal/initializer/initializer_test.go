@@ -3,9 +3,13 @@ package initializer
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 )
@@ -33,13 +37,27 @@ func (r *minimalRepo) Ping(_ context.Context) error { return nil }
 func (r *minimalRepo) GetByCode(_ context.Context, _ string) (models.SwiftCode, error) {
 	panic("unused")
 }
-func (r *minimalRepo) GetByCountry(_ context.Context, _ string) ([]models.SwiftCode, error) {
+func (r *minimalRepo) GetByCodes(_ context.Context, _ []string) (map[string]models.SwiftCode, error) {
+	panic("unused")
+}
+func (r *minimalRepo) List(_ context.Context, _ models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	panic("unused")
+}
+func (r *minimalRepo) Walk(_ context.Context, _ func(models.SwiftCode) error) error {
+	panic("unused")
+}
+func (r *minimalRepo) Search(_ context.Context, _ string, _ int) ([]models.SwiftCodeSearchHit, error) {
 	panic("unused")
 }
 func (r *minimalRepo) AddBranch(_ context.Context, _ string, _ models.SwiftBranch) error {
 	panic("unused")
 }
-func (r *minimalRepo) Delete(_ context.Context, _ string) error { panic("unused") }
+func (r *minimalRepo) Update(_ context.Context, _ models.SwiftCode) error {
+	panic("unused")
+}
+func (r *minimalRepo) Delete(_ context.Context, _ string, _ int64, _ string) error { panic("unused") }
+func (r *minimalRepo) Restore(_ context.Context, _ string) error                   { panic("unused") }
+func (r *minimalRepo) Purge(_ context.Context, _ time.Time) (int, error)           { panic("unused") }
 
 func TestImportCSV_Success(t *testing.T) {
 	// valid 11‑char codes: two HQ and one branch
@@ -79,3 +97,54 @@ func TestImportCSV_FileNotFound(t *testing.T) {
 		t.Fatal("expected error when CSV is missing, got nil")
 	}
 }
+
+func TestImportCSV_RejectReport(t *testing.T) {
+	csv := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AAAAPL1AXXX,HeadQ1,Addr1,POLAND
+PL,AAAAPL1A001,Branch1,Addr2,POLAND
+DE,BBBBDE2AXXX,HeadQ2,Addr3,GERMANY
+PL,BAD,Bad,Addr4,POLAND
+DE,CCCCDE2AXXX,HeadQ3,Addr5,POLAND
+`
+	tmp, err := ioutil.TempFile("", "test_*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	tmp.WriteString(csv)
+	tmp.Close()
+	countries := map[string]string{"PL": "POLAND", "DE": "GERMANY"}
+
+	for _, ext := range []string{".csv", ".json"} {
+		report := filepath.Join(t.TempDir(), "rejected"+ext)
+		repo := &minimalRepo{hqSum: models.ImportSummary{HQAdded: 2}}
+		sum, err := ImportCSV(repo, tmp.Name(), countries, WithRejectReport(report))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", ext, err)
+		}
+		want := models.RejectionCounts{InvalidSwiftCode: 1, CountryNameMismatch: 1}
+		if sum.Rejected != 2 || sum.RejectedByReason != want {
+			t.Errorf("%s: got summary %+v; want 2 rejected %+v", ext, sum, want)
+		}
+
+		data, err := os.ReadFile(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch ext {
+		case ".json":
+			var rows []models.RejectedRow
+			if err := json.Unmarshal(data, &rows); err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 2 || rows[0].Line != 5 || rows[0].Reason != models.RejectInvalidSwiftCode {
+				t.Errorf("unexpected JSON report: %+v", rows)
+			}
+		default:
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != 3 || !strings.HasPrefix(lines[2], "6,country_name_mismatch,") {
+				t.Errorf("unexpected CSV report:\n%s", data)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package initializer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// importPass streams the CSV at path once through reader → validator → batched writer stages
+// and saves only HQs (hq=true) or only branches. Channels between the stages hold at most one
+// batch, so a slow repository holds back reading and memory doesn't grow with the file size.
+// onReject is called by the validator for every rejected row, nil ignores them;
+// onSave, when set, after every saved batch.
+func importPass(ctx context.Context, repo port.SwiftRepository, path string, countries map[string]string,
+	hq bool, batchSize int, onReject func(models.RejectedRow) error,
+	onSave func([]models.SwiftCode, int) error) (models.ImportSummary, error) {
+	var summary models.ImportSummary
+
+	file, err := os.Open(path)
+	if err != nil {
+		return summary, fmt.Errorf("cannot open CSV file: %v", err)
+	}
+	defer file.Close()
+	reader, err := util.NewSwiftCSVReader(file)
+	if err != nil {
+		return summary, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	// stop the stages before the file is closed
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	errc := make(chan error, 2) // one error per stage at most
+
+	// reader: raw rows in file order
+	rows := make(chan util.CSVRow, batchSize)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(rows)
+		defer recoverStage("reader", errc)
+		for {
+			row, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case rows <- row:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// validator: valid codes of the wanted kind, rejections reported
+	valid := make(chan models.SwiftCode, batchSize)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(valid)
+		defer recoverStage("validator", errc)
+		for row := range rows {
+			sc, rej := reader.Parse(row, countries)
+			if rej != nil {
+				if onReject != nil {
+					if err := onReject(*rej); err != nil {
+						errc <- err
+						return
+					}
+				}
+				continue
+			}
+			if sc.IsHeadquarter != hq {
+				continue
+			}
+			select {
+			case valid <- sc:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// writer: batches saved through the repository
+	save := repo.SaveBranches
+	if hq {
+		save = repo.SaveHeadquarters
+	}
+	batch := make([]models.SwiftCode, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		sum, err := save(ctx, batch)
+		if err != nil {
+			return err
+		}
+		summary.Add(sum)
+		if onSave != nil {
+			if err := onSave(batch, sum.HQAdded+sum.BranchesAdded); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for sc := range valid {
+		batch = append(batch, sc)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return summary, err
+	}
+
+	// stages report errors before closing their output, so they are visible here
+	select {
+	case err := <-errc:
+		return summary, err
+	default:
+		return summary, nil
+	}
+}
+
+// recoverStage reports a panic of a stage goroutine as its error; the caller's recover
+// doesn't reach it, so without this a bug in reading or validating would end the process
+func recoverStage(stage string, errc chan<- error) {
+	if p := recover(); p != nil {
+		errc <- fmt.Errorf("%s panicked: %v", stage, p)
+	}
+}
@@ -0,0 +1,200 @@
+package initializer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// writeCSV writes rows under the standard header to a temporary file
+func writeCSV(t *testing.T, rows ...string) string {
+	path := filepath.Join(t.TempDir(), "codes.csv")
+	content := "COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME\n" + strings.Join(rows, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportCSV_BranchBeforeHQ(t *testing.T) {
+	path := writeCSV(t,
+		"PL,AAAAPLPW001,Branch,Addr1,POLAND",
+		"PL,BAD,Bad,Addr2,POLAND",
+		"PL,AAAAPLPWXXX,HQ,Addr3,POLAND",
+	)
+	repo := persistence.NewMemoryRepository()
+
+	sum, err := ImportCSV(repo, path, map[string]string{"PL": "POLAND"}, WithBatchSize(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.HQAdded != 1 || sum.BranchesAdded != 1 || sum.BranchesMissingHQ != 0 || sum.Rejected != 1 {
+		t.Errorf("unexpected summary %+v", sum)
+	}
+	hq, err := repo.GetByCode(context.Background(), "AAAAPLPWXXX")
+	if err != nil || len(hq.Branches) != 1 {
+		t.Errorf("HQ = %+v, %v; want one branch", hq, err)
+	}
+}
+
+// batchRepo records the size of every batch and can fail a save
+type batchRepo struct {
+	port.SwiftRepository
+	mu       sync.Mutex
+	maxBatch int
+	saved    int
+	failAt   int // fail the save that would exceed this many codes, 0 never fails
+}
+
+func (r *batchRepo) save(batch []models.SwiftCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failAt > 0 && r.saved+len(batch) > r.failAt {
+		return errors.New("storage down")
+	}
+	r.saved += len(batch)
+	r.maxBatch = max(r.maxBatch, len(batch))
+	return nil
+}
+
+func (r *batchRepo) SaveHeadquarters(_ context.Context, hqs []models.SwiftCode) (models.ImportSummary, error) {
+	return models.ImportSummary{HQAdded: len(hqs)}, r.save(hqs)
+}
+
+func (r *batchRepo) SaveBranches(_ context.Context, brs []models.SwiftCode) (models.ImportSummary, error) {
+	return models.ImportSummary{BranchesAdded: len(brs)}, r.save(brs)
+}
+
+// bankCode returns the i-th 4 letter institution code
+func bankCode(i int) string {
+	code := []byte("AAAA")
+	for p := 3; p >= 0; p-- {
+		code[p] += byte(i % 26)
+		i /= 26
+	}
+	return string(code)
+}
+
+func TestImportCSV_Batches(t *testing.T) {
+	var rows []string
+	for i := 0; i < 2500; i++ {
+		rows = append(rows, "PL,"+bankCode(i)+"PLPWXXX,HQ,Addr,POLAND", "PL,"+bankCode(i)+"PLPW001,Branch,Addr,POLAND")
+	}
+	path := writeCSV(t, rows...)
+	repo := &batchRepo{}
+
+	sum, err := ImportCSV(repo, path, map[string]string{"PL": "POLAND"}, WithBatchSize(100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.HQAdded != 2500 || sum.BranchesAdded != 2500 || repo.saved != 5000 {
+		t.Errorf("unexpected summary %+v, saved %d", sum, repo.saved)
+	}
+	if repo.maxBatch != 100 {
+		t.Errorf("largest batch = %d; want 100", repo.maxBatch)
+	}
+}
+
+func TestImportCSV_StopsOnSaveError(t *testing.T) {
+	var rows []string
+	for i := 0; i < 1000; i++ {
+		rows = append(rows, "PL,"+bankCode(i)+"PLPWXXX,HQ,Addr,POLAND")
+	}
+	path := writeCSV(t, rows...)
+	repo := &batchRepo{failAt: 250}
+
+	if _, err := ImportCSV(repo, path, map[string]string{"PL": "POLAND"}, WithBatchSize(100)); err == nil {
+		t.Fatal("expected error from failing repository")
+	}
+	if repo.saved != 200 {
+		t.Errorf("saved %d codes; want the 2 batches before the failure", repo.saved)
+	}
+}
+
+func TestImportPass_StagePanicFailsImport(t *testing.T) {
+	path := writeCSV(t,
+		"PL,AAAAPLPWXXX,HQ,Addr,POLAND",
+		"PL,BAD,Bad row,Addr,POLAND",
+		"PL,CCCCPLPWXXX,HQ,Addr,POLAND",
+	)
+	repo := persistence.NewMemoryRepository()
+	onReject := func(models.RejectedRow) error { panic("boom") }
+
+	_, err := importPass(context.Background(), repo, path, map[string]string{"PL": "POLAND"}, true, 1, onReject, nil)
+	if err == nil || !strings.Contains(err.Error(), "validator panicked: boom") {
+		t.Errorf("import error = %v; want the validator panic", err)
+	}
+}
+
+func TestImportCSV_SaveHook(t *testing.T) {
+	repo := persistence.NewMemoryRepository()
+	countries := map[string]string{"PL": "POLAND"}
+	if _, err := ImportCSV(repo, writeCSV(t, "PL,AAAAPLPWXXX,Bank A,Addr A,POLAND"), countries); err != nil {
+		t.Fatal(err)
+	}
+
+	var saved []string
+	var added int
+	hook := WithSaveHook(func(batch []models.SwiftCode, n int) error {
+		for _, sc := range batch {
+			saved = append(saved, sc.SwiftCode)
+		}
+		added += n
+		return nil
+	})
+	path := writeCSV(t,
+		"PL,AAAAPLPWXXX,Bank A,Addr A,POLAND",
+		"PL,BBBBPLPWXXX,Bank B,Addr B,POLAND",
+		"PL,BBBBPLPW001,Branch B1,Addr B1,POLAND",
+		"PL,CCCCPLPW001,Orphan,Addr C,POLAND",
+	)
+	if _, err := ImportCSV(repo, path, countries, hook, WithBatchSize(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the existing HQ and the orphan branch are passed but not added
+	if fmt.Sprint(saved) != "[AAAAPLPWXXX BBBBPLPWXXX BBBBPLPW001 CCCCPLPW001]" || added != 2 {
+		t.Errorf("saved %v with %d added; want every new code with 2 added", saved, added)
+	}
+
+	// a sync reports the codes it adds too
+	saved, added = nil, 0
+	if _, err := ImportCSV(repo, writeCSV(t, "PL,DDDDPLPWXXX,Bank D,Addr D,POLAND"), countries, hook, WithSync()); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(saved) != "[DDDDPLPWXXX]" || added != 1 {
+		t.Errorf("sync saved %v with %d added; want DDDDPLPWXXX", saved, added)
+	}
+}
+
+// batchingRepo counts the batches an import runs in
+type batchingRepo struct {
+	port.SwiftRepository
+	batches int
+}
+
+func (r *batchingRepo) Batch(ctx context.Context, fn func(context.Context) error) error {
+	r.batches++
+	return fn(ctx)
+}
+
+func TestImportCSV_RunsInOneBatch(t *testing.T) {
+	path := writeCSV(t, "PL,AAAAPLPWXXX,HQ,Addr,POLAND", "PL,AAAAPLPW001,Branch,Addr,POLAND")
+	for _, opts := range [][]Option{{WithBatchSize(1)}, {WithSync()}} {
+		repo := &batchingRepo{SwiftRepository: persistence.NewMemoryRepository()}
+		if _, err := ImportCSV(repo, path, map[string]string{"PL": "POLAND"}, opts...); err != nil {
+			t.Fatal(err)
+		}
+		if repo.batches != 1 {
+			t.Errorf("import ran in %d batches; want 1", repo.batches)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package initializer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// reportHeader is the header of a CSV reject report, the raw record fields follow the fixed columns
+var reportHeader = []string{"LINE", "REASON", "MESSAGE", "RECORD"}
+
+// rejectReport receives rejected rows one by one, so a report never has to fit in memory
+type rejectReport interface {
+	Write(row models.RejectedRow) error
+	Close() error
+}
+
+// createReport creates the report file, JSON when path ends with .json and CSV otherwise
+func createReport(path string) (rejectReport, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return &jsonReport{f: f, w: bufio.NewWriter(f)}, nil
+	}
+	r := &csvReport{f: f, w: csv.NewWriter(f)}
+	if err := r.w.Write(reportHeader); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// csvReport writes one CSV line per rejected row
+type csvReport struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func (r *csvReport) Write(row models.RejectedRow) error {
+	return r.w.Write(append([]string{strconv.Itoa(row.Line), row.Reason, row.Message}, row.Record...))
+}
+
+func (r *csvReport) Close() error {
+	r.w.Flush()
+	err := r.w.Error()
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// jsonReport writes a JSON array, one element per rejected row
+type jsonReport struct {
+	f     *os.File
+	w     *bufio.Writer
+	count int
+}
+
+func (r *jsonReport) Write(row models.RejectedRow) error {
+	b, err := json.MarshalIndent(row, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if r.count == 0 {
+		sep = "[\n  "
+	}
+	r.count++
+	if _, err := r.w.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = r.w.Write(b)
+	return err
+}
+
+func (r *jsonReport) Close() error {
+	end := "\n]\n"
+	if r.count == 0 {
+		end = "[]\n"
+	}
+	_, err := r.w.WriteString(end)
+	if ferr := r.w.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
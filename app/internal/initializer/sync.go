@@ -0,0 +1,298 @@
+package initializer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+	"github.com/przemekk6973/swift-code-app/app/internal/util"
+)
+
+// syncActor is recorded as who deleted the codes missing from a release
+const syncActor = "import sync"
+
+// syncPlan is the difference between the stored codes and a CSV release
+type syncPlan struct {
+	addHQs         []models.SwiftCode
+	addBranches    []models.SwiftCode
+	updates        []models.SwiftCode
+	removeHQs      []string
+	removeBranches []string // only branches of kept HQs, the others go with their HQ
+	cascaded       []string // branches removed with their HQ
+	// stored versions of the updated and removed codes
+	stored map[string]models.SwiftCode
+	// unchanged, duplicate and missing HQ counters plus branches removed with their HQ
+	summary models.ImportSummary
+}
+
+// syncCSV makes the stored codes match the CSV: new codes are added, changed ones updated and
+// codes missing from the file removed. A dry run only counts the changes.
+// Stored codes are streamed once in code order, the codes of the file and the changes are held in memory.
+// onChange, when set, is told about every update and removal, onSave about every batch of added codes.
+func syncCSV(ctx context.Context, repo port.SwiftRepository, path string, countries map[string]string,
+	batchSize int, dryRun bool, onReject func(models.RejectedRow) error,
+	onChange func(before, after *models.SwiftCode) error,
+	onSave func([]models.SwiftCode, int) error) (models.ImportSummary, error) {
+	plan, err := planSync(ctx, repo, path, countries, onReject)
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	if dryRun {
+		summary := plan.summary
+		summary.HQAdded += len(plan.addHQs)
+		summary.BranchesAdded += len(plan.addBranches)
+		for _, sc := range plan.updates {
+			if sc.IsHeadquarter {
+				summary.HQUpdated++
+			} else {
+				summary.BranchesUpdated++
+			}
+		}
+		summary.HQRemoved += len(plan.removeHQs)
+		summary.BranchesRemoved += len(plan.removeBranches)
+		summary.DryRun = true
+		return summary, nil
+	}
+	return applySync(ctx, repo, plan, batchSize, onChange, onSave)
+}
+
+// planSync reads the CSV once, then walks the stored codes and compares each with its row
+func planSync(ctx context.Context, repo port.SwiftRepository, path string, countries map[string]string,
+	onReject func(models.RejectedRow) error) (syncPlan, error) {
+	plan := syncPlan{stored: make(map[string]models.SwiftCode)}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return plan, fmt.Errorf("cannot open CSV file: %v", err)
+	}
+	defer file.Close()
+	reader, err := util.NewSwiftCSVReader(file)
+	if err != nil {
+		return plan, err
+	}
+
+	// valid rows by code, the first of duplicates, and their codes in file order
+	rows := make(map[string]models.SwiftCode)
+	var order []string
+	// codes of rejected rows are still in the release, they are never removed
+	protected := make(map[string]bool)
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return plan, err
+		}
+		sc, rej := reader.Parse(row, countries)
+		if rej != nil {
+			protected[reader.Code(row)] = true
+			if onReject != nil {
+				if err := onReject(*rej); err != nil {
+					return plan, err
+				}
+			}
+			continue
+		}
+
+		if _, dup := rows[sc.SwiftCode]; dup {
+			if sc.IsHeadquarter {
+				plan.summary.HQSkipped++
+			} else {
+				plan.summary.BranchesDuplicate++
+			}
+			continue
+		}
+		rows[sc.SwiftCode] = sc
+		order = append(order, sc.SwiftCode)
+	}
+
+	// a branch is kept only when its HQ stays, removed are stored codes neither kept nor protected
+	hqStays := func(hqCode string, stored bool) bool {
+		_, seen := rows[hqCode]
+		return seen || (protected[hqCode] && stored)
+	}
+	kept := func(code string, hqStored bool) bool {
+		_, seen := rows[code]
+		return seen && (strings.HasSuffix(code, "XXX") || hqStays(hqCodeOf(code), hqStored))
+	}
+	removed := func(code string, hqStored bool) bool {
+		return !kept(code, hqStored) && !protected[code]
+	}
+
+	// file and protected codes that are stored, the rest of the file is new
+	found := make(map[string]bool)
+	err = repo.Walk(ctx, func(old models.SwiftCode) error {
+		code := old.SwiftCode
+		sc, inFile := rows[code]
+		if inFile || protected[code] {
+			found[code] = true
+		}
+		// the HQ of a stored branch is stored too
+		isKept := kept(code, true)
+		switch {
+		case !inFile:
+		case !isKept:
+			plan.summary.BranchesMissingHQ++
+		case changed(old, sc):
+			plan.updates = append(plan.updates, sc)
+			plan.stored[code] = old
+		case sc.IsHeadquarter:
+			plan.summary.HQSkipped++
+		default:
+			plan.summary.BranchesSkipped++
+		}
+
+		switch {
+		case !removed(code, true):
+		case strings.HasSuffix(code, "XXX"):
+			plan.removeHQs = append(plan.removeHQs, code)
+			plan.stored[code] = old
+		case removed(hqCodeOf(code), true):
+			// deleted together with its HQ
+			plan.summary.BranchesRemoved++
+			plan.cascaded = append(plan.cascaded, code)
+			plan.stored[code] = old
+		default:
+			plan.removeBranches = append(plan.removeBranches, code)
+			plan.stored[code] = old
+		}
+		return nil
+	})
+	if err != nil {
+		return plan, fmt.Errorf("load stored codes: %w", err)
+	}
+
+	// branches are classified after the whole file is read, their HQ may come later
+	for _, code := range order {
+		sc := rows[code]
+		switch {
+		case found[code]:
+		case sc.IsHeadquarter:
+			plan.addHQs = append(plan.addHQs, sc)
+		case kept(code, found[hqCodeOf(code)]):
+			plan.addBranches = append(plan.addBranches, sc)
+		default:
+			plan.summary.BranchesMissingHQ++
+		}
+	}
+	return plan, nil
+}
+
+// applySync removes, updates and then adds codes of the plan, new codes are saved in batches
+func applySync(ctx context.Context, repo port.SwiftRepository, plan syncPlan, batchSize int,
+	onChange func(before, after *models.SwiftCode) error,
+	onSave func([]models.SwiftCode, int) error) (models.ImportSummary, error) {
+	summary := plan.summary
+	notify := func(code string, after *models.SwiftCode) error {
+		if onChange == nil {
+			return nil
+		}
+		before := plan.stored[code]
+		return onChange(&before, after)
+	}
+
+	// a code gone already was removed by someone else, it isn't counted
+	for _, code := range plan.removeBranches {
+		err := repo.Delete(ctx, code, 0, syncActor)
+		switch {
+		case err == nil:
+			summary.BranchesRemoved++
+			err = notify(code, nil)
+		case errors.Is(err, port.ErrNotFound):
+			err = nil
+		}
+		if err != nil {
+			return summary, fmt.Errorf("remove %s: %w", code, err)
+		}
+	}
+	removedHQs := make(map[string]bool)
+	for _, code := range plan.removeHQs {
+		err := repo.Delete(ctx, code, 0, syncActor)
+		switch {
+		case err == nil:
+			summary.HQRemoved++
+			removedHQs[code] = true
+			err = notify(code, nil)
+		case errors.Is(err, port.ErrNotFound):
+			err = nil
+		}
+		if err != nil {
+			return summary, fmt.Errorf("remove %s: %w", code, err)
+		}
+	}
+	for _, code := range plan.cascaded {
+		if !removedHQs[hqCodeOf(code)] {
+			continue
+		}
+		if err := notify(code, nil); err != nil {
+			return summary, fmt.Errorf("remove %s: %w", code, err)
+		}
+	}
+
+	for _, sc := range plan.updates {
+		err := repo.Update(ctx, sc)
+		switch {
+		case err == nil && sc.IsHeadquarter:
+			summary.HQUpdated++
+		case err == nil:
+			summary.BranchesUpdated++
+		case errors.Is(err, port.ErrNotFound):
+			continue
+		default:
+			return summary, fmt.Errorf("update %s: %w", sc.SwiftCode, err)
+		}
+		after := sc
+		if err := notify(sc.SwiftCode, &after); err != nil {
+			return summary, fmt.Errorf("update %s: %w", sc.SwiftCode, err)
+		}
+	}
+
+	for start := 0; start < len(plan.addHQs); start += batchSize {
+		batch := plan.addHQs[start:min(start+batchSize, len(plan.addHQs))]
+		sum, err := repo.SaveHeadquarters(ctx, batch)
+		if err == nil && onSave != nil {
+			err = onSave(batch, sum.HQAdded)
+		}
+		if err != nil {
+			return summary, fmt.Errorf("add HQs: %w", err)
+		}
+		summary.Add(sum)
+	}
+	for start := 0; start < len(plan.addBranches); start += batchSize {
+		batch := plan.addBranches[start:min(start+batchSize, len(plan.addBranches))]
+		sum, err := repo.SaveBranches(ctx, batch)
+		if err == nil && onSave != nil {
+			err = onSave(batch, sum.BranchesAdded)
+		}
+		if err != nil {
+			return summary, fmt.Errorf("add branches: %w", err)
+		}
+		summary.Add(sum)
+	}
+	return summary, nil
+}
+
+// changed reports whether the CSV row differs from the stored code,
+// branches take country name from their HQ so it isn't compared for them
+func changed(stored, sc models.SwiftCode) bool {
+	if sc.IsHeadquarter && stored.CountryName != sc.CountryName {
+		return true
+	}
+	return stored.BankName != sc.BankName ||
+		stored.Address != sc.Address ||
+		stored.TownName != sc.TownName ||
+		stored.CodeType != sc.CodeType ||
+		stored.TimeZone != sc.TimeZone ||
+		stored.CountryISO2 != sc.CountryISO2
+}
+
+// hqCodeOf returns the HQ code of a branch, its first 8 characters followed by XXX
+func hqCodeOf(code string) string {
+	return code[:8] + "XXX"
+}
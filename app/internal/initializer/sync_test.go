@@ -0,0 +1,236 @@
+package initializer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/adapter/persistence"
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+	"github.com/przemekk6973/swift-code-app/app/internal/port"
+)
+
+// seededRepo returns a memory repository with the first directory release imported
+func seededRepo(t *testing.T) port.SwiftRepository {
+	repo := persistence.NewMemoryRepository()
+	path := writeCSV(t,
+		"PL,AAAAPLPWXXX,Bank A,Addr A,POLAND",
+		"PL,AAAAPLPW001,Branch A1,Addr A1,POLAND",
+		"PL,AAAAPLPW002,Branch A2,Addr A2,POLAND",
+		"PL,BBBBPLPWXXX,Bank B,Addr B,POLAND",
+		"PL,BBBBPLPW001,Branch B1,Addr B1,POLAND",
+		"PL,CCCCPLPWXXX,Bank C,Addr C,POLAND",
+	)
+	if _, err := ImportCSV(repo, path, map[string]string{"PL": "POLAND"}); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// nextRelease updates Bank A and Branch A1, drops Branch A2 and Bank B with its branch,
+// adds Bank D with a branch listed first, keeps Bank C behind a rejected row
+func nextRelease(t *testing.T) string {
+	return writeCSV(t,
+		"PL,DDDDPLPW001,Branch D1,Addr D1,POLAND",
+		"PL,AAAAPLPWXXX,Bank A Renamed,Addr A,POLAND",
+		"PL,AAAAPLPW001,Branch A1,Addr A1 New,POLAND",
+		"PL,CCCCPLPWXXX,Bank C,Addr C,POLSKA",
+		"PL,DDDDPLPWXXX,Bank D,Addr D,POLAND",
+		"PL,EEEEPLPW001,Orphan,Addr E,POLAND",
+	)
+}
+
+func TestImportCSV_SyncDryRun(t *testing.T) {
+	repo := seededRepo(t)
+
+	sum, err := ImportCSV(repo, nextRelease(t), map[string]string{"PL": "POLAND"}, WithDryRun())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := models.ImportSummary{
+		HQAdded: 1, HQUpdated: 1, HQRemoved: 1,
+		BranchesAdded: 1, BranchesUpdated: 1, BranchesRemoved: 2, BranchesMissingHQ: 1,
+		Rejected: 1, DryRun: true,
+		RejectedByReason: models.RejectionCounts{CountryNameMismatch: 1},
+	}
+	if *sum != want {
+		t.Errorf("summary = %+v; want %+v", *sum, want)
+	}
+
+	// nothing was written
+	ctx := context.Background()
+	if got, _ := repo.GetByCode(ctx, "AAAAPLPWXXX"); got.BankName != "Bank A" || len(got.Branches) != 2 {
+		t.Errorf("dry run changed HQ: %+v", got)
+	}
+	if _, err := repo.GetByCode(ctx, "BBBBPLPWXXX"); err != nil {
+		t.Errorf("dry run removed HQ: %v", err)
+	}
+	if _, err := repo.GetByCode(ctx, "DDDDPLPWXXX"); err != port.ErrNotFound {
+		t.Errorf("dry run added HQ, lookup error = %v", err)
+	}
+}
+
+func TestImportCSV_Sync(t *testing.T) {
+	repo := seededRepo(t)
+
+	sum, err := ImportCSV(repo, nextRelease(t), map[string]string{"PL": "POLAND"}, WithSync(), WithBatchSize(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.HQAdded != 1 || sum.HQUpdated != 1 || sum.HQRemoved != 1 || sum.BranchesAdded != 1 ||
+		sum.BranchesUpdated != 1 || sum.BranchesRemoved != 2 || sum.DryRun {
+		t.Errorf("unexpected summary %+v", sum)
+	}
+
+	ctx := context.Background()
+	hq, err := repo.GetByCode(ctx, "AAAAPLPWXXX")
+	if err != nil || hq.BankName != "Bank A Renamed" || len(hq.Branches) != 1 || hq.Branches[0].Address != "Addr A1 New" {
+		t.Errorf("HQ A = %+v, %v; want renamed with updated branch A1 only", hq, err)
+	}
+	for _, code := range []string{"AAAAPLPW002", "BBBBPLPWXXX", "BBBBPLPW001", "EEEEPLPW001"} {
+		if _, err := repo.GetByCode(ctx, code); err != port.ErrNotFound {
+			t.Errorf("%s lookup error = %v; want ErrNotFound", code, err)
+		}
+	}
+	if hq, err := repo.GetByCode(ctx, "DDDDPLPWXXX"); err != nil || len(hq.Branches) != 1 {
+		t.Errorf("HQ D = %+v, %v; want added with its branch", hq, err)
+	}
+	// rejected row keeps the stored code
+	if _, err := repo.GetByCode(ctx, "CCCCPLPWXXX"); err != nil {
+		t.Errorf("HQ with rejected row was removed: %v", err)
+	}
+
+	// the same release again changes nothing
+	sum, err = ImportCSV(repo, nextRelease(t), map[string]string{"PL": "POLAND"}, WithSync())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.HQAdded+sum.HQUpdated+sum.HQRemoved+sum.BranchesAdded+sum.BranchesUpdated+sum.BranchesRemoved != 0 ||
+		sum.HQSkipped != 2 || sum.BranchesSkipped != 2 {
+		t.Errorf("repeated sync summary = %+v; want only unchanged codes", sum)
+	}
+
+	// removed codes are only marked as deleted
+	if err := repo.Restore(ctx, "BBBBPLPWXXX"); err != nil {
+		t.Errorf("Restore of HQ removed by sync failed: %v", err)
+	}
+}
+
+func TestImportCSV_SyncChangeHook(t *testing.T) {
+	repo := seededRepo(t)
+
+	changes := make(map[string]string)
+	hook := WithChangeHook(func(before, after *models.SwiftCode) error {
+		switch {
+		case after == nil:
+			changes[before.SwiftCode] = "removed"
+		default:
+			changes[before.SwiftCode] = before.BankName + " -> " + after.BankName
+		}
+		return nil
+	})
+	if _, err := ImportCSV(repo, nextRelease(t), map[string]string{"PL": "POLAND"}, WithSync(), hook); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"AAAAPLPWXXX": "Bank A -> Bank A Renamed",
+		"AAAAPLPW001": "Branch A1 -> Branch A1",
+		"AAAAPLPW002": "removed",
+		"BBBBPLPWXXX": "removed",
+		"BBBBPLPW001": "removed",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v; want %v", changes, want)
+	}
+	for code, change := range want {
+		if changes[code] != change {
+			t.Errorf("%s change = %q; want %q", code, changes[code], change)
+		}
+	}
+
+	// a dry run reports nothing
+	changes = make(map[string]string)
+	if _, err := ImportCSV(seededRepo(t), nextRelease(t), map[string]string{"PL": "POLAND"}, WithDryRun(), hook); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("dry run changes = %v; want none", changes)
+	}
+}
+
+func TestImportCSV_SyncBringsBackRemovedCodes(t *testing.T) {
+	repo := seededRepo(t)
+	withoutB := writeCSV(t,
+		"PL,AAAAPLPWXXX,Bank A,Addr A,POLAND",
+		"PL,AAAAPLPW001,Branch A1,Addr A1,POLAND",
+		"PL,CCCCPLPWXXX,Bank C,Addr C,POLAND",
+	)
+	if _, err := ImportCSV(repo, withoutB, map[string]string{"PL": "POLAND"}, WithSync()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bank B and Branch A2 are back in the next release
+	withB := writeCSV(t,
+		"PL,AAAAPLPWXXX,Bank A,Addr A,POLAND",
+		"PL,AAAAPLPW001,Branch A1,Addr A1,POLAND",
+		"PL,AAAAPLPW002,Branch A2,Addr A2 New,POLAND",
+		"PL,BBBBPLPWXXX,Bank B,Addr B,POLAND",
+		"PL,CCCCPLPWXXX,Bank C,Addr C,POLAND",
+	)
+	sum, err := ImportCSV(repo, withB, map[string]string{"PL": "POLAND"}, WithSync())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.HQAdded != 1 || sum.BranchesAdded != 1 || sum.HQSkipped != 2 || sum.BranchesSkipped != 1 {
+		t.Errorf("summary = %+v; want Bank B and Branch A2 added", sum)
+	}
+
+	ctx := context.Background()
+	if hq, err := repo.GetByCode(ctx, "BBBBPLPWXXX"); err != nil || len(hq.Branches) != 0 {
+		t.Errorf("HQ B = %+v, %v; want back without the branch removed with it", hq, err)
+	}
+	if br, err := repo.GetByCode(ctx, "AAAAPLPW002"); err != nil || br.Address != "Addr A2 New" {
+		t.Errorf("branch A2 = %+v, %v; want back with the new address", br, err)
+	}
+
+	// an insert import brings back a deleted code as well
+	if err := repo.Delete(ctx, "CCCCPLPWXXX", 0, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	sum, err = ImportCSV(repo, withB, map[string]string{"PL": "POLAND"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.HQAdded != 1 {
+		t.Errorf("insert summary = %+v; want HQAdded=1", sum)
+	}
+	if _, err := repo.GetByCode(ctx, "CCCCPLPWXXX"); err != nil {
+		t.Errorf("HQ C after insert import: %v", err)
+	}
+}
+
+// walkOnlyRepo counts walks and fails paged lists, stored codes must be read in one pass
+type walkOnlyRepo struct {
+	port.SwiftRepository
+	walks int
+}
+
+func (r *walkOnlyRepo) Walk(ctx context.Context, fn func(models.SwiftCode) error) error {
+	r.walks++
+	return r.SwiftRepository.Walk(ctx, fn)
+}
+func (r *walkOnlyRepo) List(context.Context, models.SwiftCodeQuery) ([]models.SwiftCode, error) {
+	return nil, errors.New("sync must not page through List")
+}
+
+func TestImportCSV_SyncWalksStoredCodesOnce(t *testing.T) {
+	repo := &walkOnlyRepo{SwiftRepository: seededRepo(t)}
+
+	sum, err := ImportCSV(repo, nextRelease(t), map[string]string{"PL": "POLAND"}, WithSync(), WithBatchSize(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.walks != 1 || sum.HQRemoved != 1 || sum.BranchesRemoved != 2 {
+		t.Errorf("walks = %d, summary %+v; want one walk removing 1 HQ and 2 branches", repo.walks, sum)
+	}
+}
@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// APIKeyRepository looks up API keys by the hash of the key, see util.HashAPIKey
+type APIKeyRepository interface {
+	// FindAPIKey returns the key with the hash, ErrNotFound when there is none
+	FindAPIKey(ctx context.Context, hash string) (models.APIKey, error)
+}
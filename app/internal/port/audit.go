@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// AuditRepository is an append-only log of changes to SWIFT codes, entries are never updated or removed
+type AuditRepository interface {
+	// Append stores an entry
+	Append(ctx context.Context, entry models.AuditEntry) error
+
+	// Find returns entries matching the query, oldest first; no match is not an error
+	Find(ctx context.Context, q models.AuditQuery) ([]models.AuditEntry, error)
+}
@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// SwiftCodeCache keeps the results of looking up SWIFT codes. Entries are grouped by the HQ family of the code,
+// its first 8 characters, so a change to an HQ or any of its branches drops every lookup it affects.
+type SwiftCodeCache interface {
+	// Get returns the cached lookup of code, ok is false when it isn't cached
+	Get(ctx context.Context, code string) (lookup models.CachedLookup, ok bool, err error)
+
+	// Set caches the lookup of code
+	Set(ctx context.Context, code string, lookup models.CachedLookup) error
+
+	// Invalidate drops the lookups of the HQ families
+	Invalidate(ctx context.Context, families ...string) error
+
+	// Clear drops every lookup
+	Clear(ctx context.Context) error
+}
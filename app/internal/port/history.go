@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// HistoryRepository keeps every version of every HQ and branch, versions are never updated or removed
+type HistoryRepository interface {
+	// Record appends versions
+	Record(ctx context.Context, versions []models.SwiftCodeVersion) error
+
+	// History returns every version of code, oldest first; an unknown code has none
+	History(ctx context.Context, code string) ([]models.SwiftCodeVersion, error)
+
+	// Latest returns the latest version of each of codes, keyed by code; codes without history are left out
+	Latest(ctx context.Context, codes []string) (map[string]models.SwiftCodeVersion, error)
+
+	// AsOf returns for every code matching the query its latest version valid at q.AsOf,
+	// skipping codes deleted or not added yet at that time; no match is not an error
+	AsOf(ctx context.Context, q models.HistoryQuery) ([]models.SwiftCodeVersion, error)
+}
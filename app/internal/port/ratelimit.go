@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// RateLimitStore keeps the token buckets of API clients, stores shared by replicas enforce a common quota
+type RateLimitStore interface {
+	// Take removes a token from the bucket under key, a new bucket starts full
+	Take(ctx context.Context, key string, limit models.RateLimit) (models.RateLimitResult, error)
+}
@@ -3,12 +3,18 @@ package port
 import (
 	"context"
 	"errors"
+	"time"
+
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 )
 
-// SwiftRepository defines CRUD for SWIFT codes
+// SwiftRepository defines CRUD for SWIFT codes.
+// Every HQ document has a version, 1 when saved and incremented by each change
+// to the HQ or its branches; GetByCode returns it, for a branch the version of its HQ.
+// Deleted codes are only marked as deleted: every read hides them (and the branches of a deleted HQ),
+// until restored or purged; saving or adding a deleted code again replaces it, it can't be restored then.
 type SwiftRepository interface {
-	// SaveHeadquarters saves HQ list
+	// SaveHeadquarters saves HQ list, live HQs are skipped
 	SaveHeadquarters(ctx context.Context, hqs []models.SwiftCode) (models.ImportSummary, error)
 
 	// SaveBranches saves list of branches
@@ -17,20 +23,54 @@ type SwiftRepository interface {
 	// GetByCode saves SwiftCode (HQ or branch) by code
 	GetByCode(ctx context.Context, code string) (models.SwiftCode, error)
 
-	// GetByCountry downloads all codes by ISO2
-	GetByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, error)
+	// GetByCodes looks many codes up at once, as GetByCode would each, keyed by code;
+	// codes that aren't found are left out
+	GetByCodes(ctx context.Context, codes []string) (map[string]models.SwiftCode, error)
+
+	// List returns HQs and branches (flattened, without nested branches) matching the query,
+	// ordered by SWIFT code; an empty page is not an error
+	List(ctx context.Context, q models.SwiftCodeQuery) ([]models.SwiftCode, error)
+
+	// Walk calls fn for every live HQ and branch, flattened as List returns them, ordered by SWIFT code;
+	// they are read through one cursor, the first error of fn stops the walk and is returned
+	Walk(ctx context.Context, fn func(models.SwiftCode) error) error
 
-	// AddBranch adds branch for existing HQ
+	// Search finds HQs and branches by bank name and address, best matches first,
+	// tolerating typos; query is split into terms that must all match
+	Search(ctx context.Context, query string, limit int) ([]models.SwiftCodeSearchHit, error)
+
+	// AddBranch adds branch for existing HQ, ErrHQNotFound without a live one
 	AddBranch(ctx context.Context, hqCode string, branch models.SwiftBranch) error
 
-	// Delete deletes by SWIFT
-	Delete(ctx context.Context, code string) error
+	// Update replaces bank name, address, town, code type, time zone and country of an HQ or branch,
+	// HQ branches are kept; branches take country name from their HQ.
+	// A non-zero sc.Version must equal the stored version, otherwise ErrVersionMismatch.
+	Update(ctx context.Context, sc models.SwiftCode) error
+
+	// Delete marks an HQ (with its branches) or a branch as deleted now by actor;
+	// a non-zero version must equal the stored one, otherwise ErrVersionMismatch
+	Delete(ctx context.Context, code string, version int64, actor string) error
+
+	// Restore brings back a deleted HQ with the branches deleted together with it, or a deleted branch;
+	// a branch of a deleted HQ can't be restored before its HQ (ErrHQNotFound)
+	Restore(ctx context.Context, code string) error
+
+	// Purge permanently removes HQs and branches deleted before the given time and returns how many
+	Purge(ctx context.Context, before time.Time) (int, error)
 
 	Ping(ctx context.Context) error
 }
 
+// Batcher is implemented by repositories that persist many changes together more cheaply than one by one
+type Batcher interface {
+	// Batch runs fn; changes made with the context passed to fn may be persisted only when it returns.
+	// An error persisting them is returned and undoes them.
+	Batch(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 var (
 	ErrNotFound        = errors.New("swift code not found")
 	ErrHQNotFound      = errors.New("headquarter not found")
 	ErrBranchDuplicate = errors.New("branch already exists")
+	ErrVersionMismatch = errors.New("swift code version changed")
 )
@@ -0,0 +1,136 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// ValidateBIC splits code into its ISO 9362 parts and checks every rule:
+// a 4 letter institution code, a 2 letter country (known to countries, unless it is empty),
+// a 2 character location code and an optional 3 character branch code, which starts with X only as XXX.
+// Letters must be uppercase ASCII.
+func ValidateBIC(code string, countries map[string]string) models.BICValidation {
+	v := models.BICValidation{Code: code, Violations: []models.BICViolation{}}
+	violate := func(rule, format string, args ...interface{}) {
+		v.Violations = append(v.Violations, models.BICViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// split by characters, so a non-ASCII letter is reported in its part rather than shifting the others
+	chars := []rune(code)
+	if len(chars) != 8 && len(chars) != 11 {
+		violate(models.BICRuleLength, "SWIFT code must be 8 or 11 characters, got %d", len(chars))
+	}
+	part := func(from, to int) string {
+		if len(chars) <= from {
+			return ""
+		}
+		return string(chars[from:min(to, len(chars))])
+	}
+
+	v.Institution = models.BICPart{Value: part(0, 4)}
+	v.Institution.Valid = len(v.Institution.Value) == 4 && allOf(v.Institution.Value, isLetter)
+	if !v.Institution.Valid {
+		violate(models.BICRuleInstitution, "institution code must be 4 letters, got %q", v.Institution.Value)
+	}
+
+	v.Country = models.BICPart{Value: part(4, 6)}
+	v.Country.Valid = len(v.Country.Value) == 2 && allOf(v.Country.Value, isLetter)
+	if !v.Country.Valid {
+		violate(models.BICRuleCountry, "country code must be 2 letters, got %q", v.Country.Value)
+	} else if len(countries) > 0 {
+		if name, ok := countries[v.Country.Value]; ok {
+			v.CountryName = name
+		} else {
+			v.Country.Valid = false
+			violate(models.BICRuleUnknownCountry, "unknown country code %s", v.Country.Value)
+		}
+	}
+
+	v.Location = models.BICPart{Value: part(6, 8)}
+	v.Location.Valid = len(v.Location.Value) == 2 && allOf(v.Location.Value, isAlnum)
+	if !v.Location.Valid {
+		violate(models.BICRuleLocation, "location code must be 2 letters or digits, got %q", v.Location.Value)
+	} else {
+		v.Test = v.Location.Value[1] == '0'
+		v.PassiveParticipant = v.Location.Value[1] == '1'
+	}
+
+	if len(chars) > 8 {
+		branch := part(8, 11)
+		v.Branch = &models.BICPart{Value: branch}
+		switch {
+		case len(chars) != 11:
+			// the length violation covers it
+		case !allOf(branch, isAlnum):
+			violate(models.BICRuleBranch, "branch code must be 3 letters or digits, got %q", branch)
+		case branch[0] == 'X' && branch != "XXX":
+			violate(models.BICRuleBranch, "branch code can start with X only as XXX, got %q", branch)
+		default:
+			v.Branch.Valid = true
+		}
+	}
+	v.IsHeadquarter = len(chars) == 8 || part(8, 11) == "XXX"
+
+	v.Valid = len(v.Violations) == 0
+	return v
+}
+
+// sharedCountryCodes lists territories whose banks may use the country code of another country
+// in their SWIFT codes, besides their own: countryISO2 to the other country codes accepted
+var sharedCountryCodes = map[string][]string{
+	"AX": {"FI"},       // Åland Islands
+	"GG": {"GB"},       // Guernsey
+	"JE": {"GB"},       // Jersey
+	"IM": {"GB"},       // Isle of Man
+	"GF": {"FR"},       // French Guiana
+	"GP": {"FR"},       // Guadeloupe
+	"MQ": {"FR"},       // Martinique
+	"RE": {"FR"},       // Réunion
+	"YT": {"FR"},       // Mayotte
+	"BL": {"FR", "GP"}, // Saint Barthélemy
+	"MF": {"FR", "GP"}, // Saint Martin
+	"PM": {"FR"},       // Saint Pierre and Miquelon
+	"PR": {"US"},       // Puerto Rico
+	"GU": {"US"},       // Guam
+	"VI": {"US"},       // U.S. Virgin Islands
+	"AS": {"US"},       // American Samoa
+	"MP": {"US"},       // Northern Mariana Islands
+	"SJ": {"NO"},       // Svalbard and Jan Mayen
+	"CX": {"AU"},       // Christmas Island
+	"CC": {"AU"},       // Cocos (Keeling) Islands
+	"NF": {"AU"},       // Norfolk Island
+	"BQ": {"CW"},       // Caribbean Netherlands
+	"SX": {"CW"},       // Sint Maarten, shares a central bank with Curaçao
+}
+
+// ValidateBICCountry checks that the country segment of a well formed SWIFT code (characters 5-6)
+// is iso2, or a country code the territory iso2 shares (see sharedCountryCodes)
+func ValidateBICCountry(code, iso2 string) error {
+	if len(code) < 6 {
+		return WrapError(ErrBadRequest, "SWIFT code %s has no country code", code)
+	}
+	segment := code[4:6]
+	if segment == iso2 {
+		return nil
+	}
+	for _, shared := range sharedCountryCodes[iso2] {
+		if segment == shared {
+			return nil
+		}
+	}
+	return WrapError(ErrBadRequest, "SWIFT code %s is of country %s, not %s", code, segment, iso2)
+}
+
+func allOf(s string, ok func(byte) bool) bool {
+	for i := 0; i < len(s); i++ {
+		if !ok(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+func isAlnum(c byte) bool { return isLetter(c) || c >= '0' && c <= '9' }
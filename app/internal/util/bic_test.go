@@ -0,0 +1,92 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+func TestValidateBIC(t *testing.T) {
+	countries := map[string]string{"PL": "POLAND", "DE": "GERMANY"}
+
+	v := ValidateBIC("BREXPLPWMBK", countries)
+	if !v.Valid || len(v.Violations) != 0 {
+		t.Fatalf("BREXPLPWMBK = %+v; want valid", v)
+	}
+	if v.Institution.Value != "BREX" || v.Country.Value != "PL" || v.CountryName != "POLAND" || v.Location.Value != "PW" ||
+		v.Branch == nil || v.Branch.Value != "MBK" || v.IsHeadquarter || v.Test || v.PassiveParticipant {
+		t.Errorf("unexpected breakdown %+v", v)
+	}
+
+	if v := ValidateBIC("DEUTDEFF", countries); !v.Valid || v.Branch != nil || !v.IsHeadquarter {
+		t.Errorf("DEUTDEFF = %+v; want valid HQ without branch", v)
+	}
+	if v := ValidateBIC("AIPOPLP1XXX", countries); !v.Valid || !v.PassiveParticipant || v.Test {
+		t.Errorf("AIPOPLP1XXX = %+v; want valid passive participant", v)
+	}
+	if v := ValidateBIC("BREXPLP0", countries); !v.Valid || !v.Test {
+		t.Errorf("BREXPLP0 = %+v; want valid test code", v)
+	}
+	// without countries only the format of the country is checked
+	if v := ValidateBIC("BREXZZPW", nil); !v.Valid || v.CountryName != "" {
+		t.Errorf("BREXZZPW without countries = %+v; want valid", v)
+	}
+}
+
+func TestValidateBIC_Violations(t *testing.T) {
+	countries := map[string]string{"PL": "POLAND"}
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"BREXPLPWXX", []string{models.BICRuleLength}},
+		{"BR3XPLPW", []string{models.BICRuleInstitution}},
+		{"BREXP1PW", []string{models.BICRuleCountry}},
+		{"BREXZZPW", []string{models.BICRuleUnknownCountry}},
+		{"BREXPLP-", []string{models.BICRuleLocation}},
+		{"BREXPLPWX01", []string{models.BICRuleBranch}},
+		{"BREXPLPW0_1", []string{models.BICRuleBranch}},
+		{"brexplpw", []string{models.BICRuleInstitution, models.BICRuleCountry, models.BICRuleLocation}},
+		{"BRÉXPLPW", []string{models.BICRuleInstitution}},
+		{"AB", []string{models.BICRuleLength, models.BICRuleInstitution, models.BICRuleCountry, models.BICRuleLocation}},
+	}
+	for _, tc := range tests {
+		v := ValidateBIC(tc.code, countries)
+		var got []string
+		for _, violation := range v.Violations {
+			if violation.Message == "" {
+				t.Errorf("%s: violation %s has no message", tc.code, violation.Rule)
+			}
+			got = append(got, violation.Rule)
+		}
+		if v.Valid || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ValidateBIC(%q) violations = %v, valid %v; want %v", tc.code, got, v.Valid, tc.want)
+		}
+	}
+}
+
+func TestValidateBICCountry(t *testing.T) {
+	good := [][2]string{
+		{"BREXPLPWXXX", "PL"},
+		{"HSBCGB2L", "JE"}, // Jersey banks may use GB
+		{"BNPAFRPPXXX", "RE"},
+	}
+	for _, tc := range good {
+		if err := ValidateBICCountry(tc[0], tc[1]); err != nil {
+			t.Errorf("ValidateBICCountry(%s, %s) = %v; want nil", tc[0], tc[1], err)
+		}
+	}
+
+	bad := [][2]string{
+		{"BREXPLPWXXX", "DE"},
+		{"HSBCJESH", "GB"}, // the exceptions only go one way
+		{"BREX", "PL"},
+	}
+	for _, tc := range bad {
+		err := ValidateBICCountry(tc[0], tc[1])
+		if e, ok := err.(*AppError); !ok || e.StatusCode != 400 {
+			t.Errorf("ValidateBICCountry(%s, %s) = %v; want 400", tc[0], tc[1], err)
+		}
+	}
+}
@@ -2,31 +2,39 @@ package util
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 )
 
-// LoadSwiftCodes reads CSV from csvPath and returns two lists:
-//   - hqList: records with HQ (ending with "XXX")
-//   - branchList: records with branch codes
-func LoadSwiftCodes(csvPath string, countries map[string]string) ([]models.SwiftCode, []models.SwiftCode, error) {
-	file, err := os.Open(csvPath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("cannot open CSV file: %v", err)
-	}
-	defer file.Close()
+// requiredColumns must be present in the CSV header
+var requiredColumns = []string{"SWIFT CODE", "COUNTRY ISO2 CODE", "NAME", "ADDRESS", "COUNTRY NAME"}
+
+// CSVRow is a raw CSV record with its line number
+type CSVRow struct {
+	Line   int
+	Record []string
+}
 
-	reader := csv.NewReader(file)
+// SwiftCSVReader reads SWIFT code CSV files one row at a time
+type SwiftCSVReader struct {
+	reader  *csv.Reader
+	header  []string
+	indexes map[string]int
+}
+
+// NewSwiftCSVReader reads the header of r and checks the required columns are present
+func NewSwiftCSVReader(r io.Reader) (*SwiftCSVReader, error) {
+	reader := csv.NewReader(r)
 	reader.TrimLeadingSpace = true
 
 	// load header
 	header, err := reader.Read()
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading CSV header: %v", err)
+		return nil, fmt.Errorf("error reading CSV header: %v", err)
 	}
 
 	// map column names to indexes
@@ -35,61 +43,94 @@ func LoadSwiftCodes(csvPath string, countries map[string]string) ([]models.Swift
 		key := strings.ToUpper(strings.TrimSpace(col))
 		indexes[key] = i
 	}
+	for _, col := range requiredColumns {
+		if _, ok := indexes[col]; !ok {
+			return nil, fmt.Errorf("CSV header has no %q column", col)
+		}
+	}
+	return &SwiftCSVReader{reader: reader, header: header, indexes: indexes}, nil
+}
 
-	var hqList []models.SwiftCode
-	var branchList []models.SwiftCode
+// Next returns the next row, or io.EOF at the end of input.
+// Rows with a wrong number of fields are returned too, Parse rejects them.
+func (r *SwiftCSVReader) Next() (CSVRow, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return CSVRow{}, io.EOF
+	}
+	// FieldPos panics for a record that failed to parse, so check the error first
+	if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+		return CSVRow{}, fmt.Errorf("error reading CSV record: %v", err)
+	}
+	line, _ := r.reader.FieldPos(0)
+	return CSVRow{Line: line, Record: record}, nil
+}
 
-	// row processing
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, nil, fmt.Errorf("error reading CSV record: %v", err)
-		}
+// Code returns the normalized SWIFT code of a row, "" when the row is too short to have one
+func (r *SwiftCSVReader) Code(row CSVRow) string {
+	return strings.ToUpper(optionalField(row.Record, r.indexes, "SWIFT CODE"))
+}
 
-		//Field extraction and normalization
-		swiftCode := strings.ToUpper(strings.TrimSpace(record[indexes["SWIFT CODE"]]))
-		countryISO2 := strings.ToUpper(strings.TrimSpace(record[indexes["COUNTRY ISO2 CODE"]]))
-		bankName := strings.TrimSpace(record[indexes["NAME"]])
-		address := strings.TrimSpace(record[indexes["ADDRESS"]])
-		countryName := strings.TrimSpace(record[indexes["COUNTRY NAME"]])
+// Parse validates and normalizes a row; an invalid row is returned as rejected with the reason
+func (r *SwiftCSVReader) Parse(row CSVRow, countries map[string]string) (models.SwiftCode, *models.RejectedRow) {
+	record := row.Record
+	reject := func(reason string, err error) (models.SwiftCode, *models.RejectedRow) {
+		return models.SwiftCode{}, &models.RejectedRow{Line: row.Line, Record: record, Reason: reason, Message: err.Error()}
+	}
+	if len(record) < len(r.header) {
+		return reject(models.RejectMalformedRow, fmt.Errorf("record on line %d: wrong number of fields", row.Line))
+	}
 
-		// validate code and country
-		if err := ValidateSwiftCode(swiftCode); err != nil {
-			continue
-		}
-		if err := ValidateCountryISO2(countryISO2); err != nil {
-			continue
-		}
-		if err := ValidateCountryNameMatch(countryISO2, countryName, countries); err != nil {
-			continue
-		}
+	//Field extraction and normalization
+	swiftCode := strings.ToUpper(strings.TrimSpace(record[r.indexes["SWIFT CODE"]]))
+	countryISO2 := strings.ToUpper(strings.TrimSpace(record[r.indexes["COUNTRY ISO2 CODE"]]))
+	bankName := strings.TrimSpace(record[r.indexes["NAME"]])
+	address := strings.TrimSpace(record[r.indexes["ADDRESS"]])
+	countryName := strings.TrimSpace(record[r.indexes["COUNTRY NAME"]])
+	// optional columns, older exports don't have them
+	townName := optionalField(record, r.indexes, "TOWN NAME")
+	codeType := strings.ToUpper(optionalField(record, r.indexes, "CODE TYPE"))
+	timeZone := optionalField(record, r.indexes, "TIME ZONE")
 
-		// HQ and branch separation
-		isHQ := strings.HasSuffix(swiftCode, "XXX")
-		if isHQ {
-			hqList = append(hqList, models.SwiftCode{
-				SwiftCode:     swiftCode,
-				BankName:      bankName,
-				Address:       address,
-				CountryISO2:   countryISO2,
-				CountryName:   countryName,
-				IsHeadquarter: true,
-				Branches:      []models.SwiftBranch{},
-			})
-		} else {
-			branchList = append(branchList, models.SwiftCode{
-				SwiftCode:     swiftCode,
-				BankName:      bankName,
-				Address:       address,
-				CountryISO2:   countryISO2,
-				CountryName:   countryName,
-				IsHeadquarter: false,
-			})
+	// validate code and country
+	if err := ValidateSwiftCode(swiftCode); err != nil {
+		return reject(models.RejectInvalidSwiftCode, err)
+	}
+	if err := ValidateCountryISO2(countryISO2); err != nil {
+		return reject(models.RejectInvalidCountryISO2, err)
+	}
+	if err := ValidateBICCountry(swiftCode, countryISO2); err != nil {
+		return reject(models.RejectCountryCodeMismatch, err)
+	}
+	if err := ValidateCountryNameMatch(countryISO2, countryName, countries); err != nil {
+		if _, known := countries[countryISO2]; !known {
+			return reject(models.RejectUnknownCountry, err)
 		}
+		return reject(models.RejectCountryNameMismatch, err)
 	}
 
-	return hqList, branchList, nil
+	sc := models.SwiftCode{
+		SwiftCode:     swiftCode,
+		BankName:      bankName,
+		Address:       address,
+		CountryISO2:   countryISO2,
+		CountryName:   countryName,
+		TownName:      townName,
+		CodeType:      codeType,
+		TimeZone:      timeZone,
+		IsHeadquarter: strings.HasSuffix(swiftCode, "XXX"),
+	}
+	if sc.IsHeadquarter {
+		sc.Branches = []models.SwiftBranch{}
+	}
+	return sc, nil
+}
+
+// optionalField returns trimmed value of column, or "" when the CSV has no such column
+func optionalField(record []string, indexes map[string]int, column string) string {
+	i, ok := indexes[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
 }
@@ -1,37 +1,51 @@
 package util
 
 import (
-	"io/ioutil"
-	"os"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
 )
 
-func TestLoadSwiftCodes(t *testing.T) {
-	sample := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
-PL,AABBPLP1XXX,TestHQ1,Address1,POLAND
-PL,AABBPLP1BR1,TestBR1,Address2,POLAND
-DE,CCCCDEFFXXX,TestHQ2,Address3,GERMANY
-`
-	tmp, err := ioutil.TempFile("", "swift_test_*.csv")
+// parseAll parses every row of sample, like an import does
+func parseAll(t *testing.T, sample string, countries map[string]string) (hqList, brList []models.SwiftCode, rejected []models.RejectedRow) {
+	t.Helper()
+	r, err := NewSwiftCSVReader(strings.NewReader(sample))
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer os.Remove(tmp.Name())
-	if _, err := tmp.WriteString(sample); err != nil {
-		t.Fatal(err)
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return hqList, brList, rejected
+		}
+		if err != nil {
+			t.Fatalf("Next error: %v", err)
+		}
+		sc, rej := r.Parse(row, countries)
+		switch {
+		case rej != nil:
+			rejected = append(rejected, *rej)
+		case sc.IsHeadquarter:
+			hqList = append(hqList, sc)
+		default:
+			brList = append(brList, sc)
+		}
 	}
-	tmp.Close()
+}
 
+func TestSwiftCSVReader_Parse(t *testing.T) {
+	sample := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AABBPLP1XXX,TestHQ1,Address1,POLAND
+PL,AABBPLP1BR1,TestBR1,Address2,POLAND
+DE,CCCCDEFFXXX,TestHQ2,Address3,GERMANY
+`
 	// podstawowa mapa krajów
 	countries := map[string]string{"PL": "POLAND", "DE": "GERMANY"}
 
-	hqList, brList, err := LoadSwiftCodes(tmp.Name(), countries)
-	if err != nil {
-		t.Fatalf("LoadSwiftCodes error: %v", err)
-	}
+	hqList, brList, rejected := parseAll(t, sample, countries)
 
 	if len(hqList) != 2 {
 		t.Errorf("expected 2 HQ, got %d", len(hqList))
@@ -39,6 +53,9 @@ DE,CCCCDEFFXXX,TestHQ2,Address3,GERMANY
 	if len(brList) != 1 {
 		t.Errorf("expected 1 branch, got %d", len(brList))
 	}
+	if len(rejected) != 0 {
+		t.Errorf("expected no rejected rows, got %+v", rejected)
+	}
 
 	// checkk first HQ
 	wantHQ := models.SwiftCode{
@@ -68,3 +85,74 @@ DE,CCCCDEFFXXX,TestHQ2,Address3,GERMANY
 		t.Errorf("Branch mismatch:\n got %+v\nwant %+v", brList[0], wantBR)
 	}
 }
+
+func TestSwiftCSVReader_LocationColumns(t *testing.T) {
+	sample := `COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE
+BG,ABIEBGS1XXX,bic11,ABV INVESTMENTS LTD,"TSAR ASEN 20  VARNA, VARNA, 9002", VARNA ,BULGARIA,Europe/Sofia
+`
+	hqList, _, _ := parseAll(t, sample, map[string]string{"BG": "BULGARIA"})
+	if len(hqList) != 1 {
+		t.Fatalf("expected 1 HQ, got %d", len(hqList))
+	}
+	hq := hqList[0]
+	if hq.TownName != "VARNA" || hq.CodeType != "BIC11" || hq.TimeZone != "Europe/Sofia" {
+		t.Errorf("unexpected location fields: %+v", hq)
+	}
+}
+
+func TestSwiftCSVReader_Rejected(t *testing.T) {
+	sample := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AABBPLP1XXX,TestHQ1,Address1,POLAND
+PL,SHORT,TestHQ2,Address2,POLAND
+P1,AABBPLP2XXX,TestHQ3,Address3,POLAND
+FR,AABBFRP1XXX,TestHQ4,Address4,FRANCE
+PL,AABBPLP3XXX,TestHQ5,Address5,GERMANY
+PL,AABBDEP5XXX,TestHQ7,Address7,POLAND
+PL,AABBPLP4XXX,TestHQ6
+`
+	hqList, _, rejected := parseAll(t, sample, map[string]string{"PL": "POLAND"})
+	if len(hqList) != 1 {
+		t.Errorf("expected 1 HQ, got %d", len(hqList))
+	}
+
+	want := []struct {
+		line   int
+		reason string
+	}{
+		{3, models.RejectInvalidSwiftCode},
+		{4, models.RejectInvalidCountryISO2},
+		{5, models.RejectUnknownCountry},
+		{6, models.RejectCountryNameMismatch},
+		{7, models.RejectCountryCodeMismatch},
+		{8, models.RejectMalformedRow},
+	}
+	if len(rejected) != len(want) {
+		t.Fatalf("got %d rejected rows, want %d: %+v", len(rejected), len(want), rejected)
+	}
+	for i, w := range want {
+		got := rejected[i]
+		if got.Line != w.line || got.Reason != w.reason || got.Message == "" || len(got.Record) == 0 {
+			t.Errorf("rejected[%d] = %+v; want line %d, reason %s", i, got, w.line, w.reason)
+		}
+	}
+	if rejected[0].Record[1] != "SHORT" {
+		t.Errorf("raw record not kept: %v", rejected[0].Record)
+	}
+}
+
+func TestSwiftCSVReader_MalformedQuote(t *testing.T) {
+	sample := `COUNTRY ISO2 CODE,SWIFT CODE,NAME,ADDRESS,COUNTRY NAME
+PL,AABBPLP1XXX,TestHQ,Address,POLAND
+"PL"x,AABBPLP2XXX,TestHQ2,Address2,POLAND
+`
+	r, err := NewSwiftCSVReader(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("first row: %v", err)
+	}
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("bare quote err = %v; want a read error", err)
+	}
+}
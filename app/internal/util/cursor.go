@@ -0,0 +1,23 @@
+package util
+
+import (
+	"encoding/base64"
+)
+
+// EncodeCursor turns the last SWIFT code of a page into an opaque pagination cursor
+func EncodeCursor(code string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(code))
+}
+
+// DecodeCursor returns the SWIFT code stored in a cursor made by EncodeCursor
+func DecodeCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", WrapError(ErrBadRequest, "invalid cursor")
+	}
+	code := string(raw)
+	if err := ValidateSwiftCode(code); err != nil {
+		return "", WrapError(ErrBadRequest, "invalid cursor")
+	}
+	return code, nil
+}
@@ -0,0 +1,22 @@
+package util
+
+import "testing"
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := EncodeCursor("AAAAPLPWXXX")
+	code, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor error: %v", err)
+	}
+	if code != "AAAAPLPWXXX" {
+		t.Errorf("DecodeCursor = %q; want %q", code, "AAAAPLPWXXX")
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{"not base64!", EncodeCursor("short")} {
+		if _, err := DecodeCursor(cursor); err == nil {
+			t.Errorf("expected error for cursor %q, got nil", cursor)
+		}
+	}
+}
@@ -30,10 +30,15 @@ func WrapError(base *AppError, format string, args ...interface{}) *AppError {
 
 // base errors
 var (
-	ErrBadRequest = NewError("bad request", http.StatusBadRequest)
-	ErrNotFound   = NewError("not found", http.StatusNotFound)
-	ErrConflict   = NewError("conflict", http.StatusConflict)
-	ErrInternal   = NewError("internal server error", http.StatusInternalServerError)
+	ErrBadRequest           = NewError("bad request", http.StatusBadRequest)
+	ErrUnauthorized         = NewError("unauthorized", http.StatusUnauthorized)
+	ErrForbidden            = NewError("forbidden", http.StatusForbidden)
+	ErrNotFound             = NewError("not found", http.StatusNotFound)
+	ErrConflict             = NewError("conflict", http.StatusConflict)
+	ErrPreconditionFailed   = NewError("precondition failed", http.StatusPreconditionFailed)
+	ErrPreconditionRequired = NewError("precondition required", http.StatusPreconditionRequired)
+	ErrTooManyRequests      = NewError("too many requests", http.StatusTooManyRequests)
+	ErrInternal             = NewError("internal server error", http.StatusInternalServerError)
 )
 
 // StatusCodeFromError returns HTTP status for any error
@@ -51,6 +56,16 @@ func BadRequest(format string, args ...interface{}) *AppError {
 	return WrapError(ErrBadRequest, format, args...)
 }
 
+// Unauthorized creates AppError with 401 code
+func Unauthorized(format string, args ...interface{}) *AppError {
+	return WrapError(ErrUnauthorized, format, args...)
+}
+
+// Forbidden creates AppError with 403 code
+func Forbidden(format string, args ...interface{}) *AppError {
+	return WrapError(ErrForbidden, format, args...)
+}
+
 // NotFound creates AppError with 404 code
 func NotFound(format string, args ...interface{}) *AppError {
 	return WrapError(ErrNotFound, format, args...)
@@ -61,6 +76,21 @@ func Conflict(format string, args ...interface{}) *AppError {
 	return WrapError(ErrConflict, format, args...)
 }
 
+// PreconditionFailed creates AppError with 412 code
+func PreconditionFailed(format string, args ...interface{}) *AppError {
+	return WrapError(ErrPreconditionFailed, format, args...)
+}
+
+// PreconditionRequired creates AppError with 428 code
+func PreconditionRequired(format string, args ...interface{}) *AppError {
+	return WrapError(ErrPreconditionRequired, format, args...)
+}
+
+// TooManyRequests creates AppError with 429 code
+func TooManyRequests(format string, args ...interface{}) *AppError {
+	return WrapError(ErrTooManyRequests, format, args...)
+}
+
 // Internal creates AppError with 500 code
 func Internal(format string, args ...interface{}) *AppError {
 	return WrapError(ErrInternal, format, args...)
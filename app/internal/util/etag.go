@@ -0,0 +1,43 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ETag formats the stored version of a SWIFT code as a strong entity tag
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// IfMatchVersion returns the version a write must find, taken from the If-Match header;
+// 0 for "*", which matches any version. A missing header is 428, a single tag is required,
+// and a tag that isn't a strong ETag of this API can never match, so it is 412.
+func IfMatchVersion(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, PreconditionRequired("If-Match header is required, use the ETag from GET or *")
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, BadRequest("If-Match must contain a single ETag")
+	}
+	v, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || v < 1 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, PreconditionFailed("If-Match %s doesn't match the current version", header)
+	}
+	return v, nil
+}
+
+// MatchesETag reports whether an If-None-Match header matches etag, comparing weakly as RFC 9110 says
+func MatchesETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header  string
+		version int64
+		status  int
+	}{
+		{`"3"`, 3, 0},
+		{` "12" `, 12, 0},
+		{`*`, 0, 0},
+		{``, 0, http.StatusPreconditionRequired},
+		{`"1", "2"`, 0, http.StatusBadRequest},
+		{`W/"3"`, 0, http.StatusPreconditionFailed},
+		{`3`, 0, http.StatusPreconditionFailed},
+		{`"abc"`, 0, http.StatusPreconditionFailed},
+		{`"0"`, 0, http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		version, err := IfMatchVersion(tt.header)
+		if tt.status == 0 {
+			if err != nil || version != tt.version {
+				t.Errorf("IfMatchVersion(%q) = %d, %v; want %d", tt.header, version, err, tt.version)
+			}
+			continue
+		}
+		if StatusCodeFromError(err) != tt.status {
+			t.Errorf("IfMatchVersion(%q) error = %v; want status %d", tt.header, err, tt.status)
+		}
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	etag := ETag(7)
+	if etag != `"7"` {
+		t.Fatalf("ETag(7) = %s", etag)
+	}
+	for header, want := range map[string]bool{
+		`"7"`:       true,
+		`W/"7"`:     true,
+		`"6", "7"`:  true,
+		`*`:         true,
+		`"6"`:       false,
+		``:          false,
+		`"7-other"`: false,
+	} {
+		if got := MatchesETag(header, etag); got != want {
+			t.Errorf("MatchesETag(%q) = %v; want %v", header, got, want)
+		}
+	}
+}
@@ -6,3 +6,57 @@ const ParamSwiftCode = "swift-code"
 
 // ParamCountryISO2 is the segment name in Gin path for country code ISO2
 const ParamCountryISO2 = "countryISO2code"
+
+// ParamImportID is the segment name in Gin path for the import job ID
+const ParamImportID = "id"
+
+// HeaderActor names who makes a change, recorded on deleted codes and in the audit log
+const HeaderActor = "X-Actor"
+
+// HeaderRequestID carries the request ID, taken from the client or generated, and is echoed back
+const HeaderRequestID = "X-Request-ID"
+
+// HeaderAPIKey carries the API key of the client, see HashAPIKey
+const HeaderAPIKey = "X-API-Key"
+
+// AnonymousActor is recorded when a request doesn't name its actor
+const AnonymousActor = "anonymous"
+
+// FormFile is the multipart field carrying the uploaded CSV
+const FormFile = "file"
+
+// Query parameters of the list endpoint
+const (
+	QueryCountryISO2   = "countryISO2"
+	QueryIsHeadquarter = "isHeadquarter"
+	QueryBankName      = "bankName"
+	QueryTown          = "town"
+	QueryTimeZone      = "timeZone"
+	QueryCursor        = "cursor"
+	QueryLimit         = "limit"
+)
+
+// QueryAsOf is the RFC 3339 point in time of lookups in the version history
+const QueryAsOf = "asOf"
+
+// Query parameters of the audit endpoint, from and to are RFC 3339 timestamps
+const (
+	QuerySwiftCode = "swiftCode"
+	QueryFrom      = "from"
+	QueryTo        = "to"
+)
+
+// QuerySearch is the free text query parameter of the search endpoint
+const QuerySearch = "q"
+
+// MaxSearchQueryLength is the longest accepted search query
+const MaxSearchQueryLength = 200
+
+// DefaultPageSize is used when no limit is given, MaxPageSize is the largest accepted limit
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// MaxLookupCodes is the largest number of codes accepted by one batch lookup
+const MaxLookupCodes = 1000
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/przemekk6973/swift-code-app/app/internal/domain/models"
+)
+
+// ParseRateLimit reads a limit written as requests/period, e.g. "600/1m" or "10000/24h"
+func ParseRateLimit(s string) (models.RateLimit, error) {
+	n, p, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return models.RateLimit{}, fmt.Errorf("rate limit %q: want requests/period, e.g. 600/1m", s)
+	}
+	limit, err := strconv.Atoi(n)
+	if err != nil || limit < 1 {
+		return models.RateLimit{}, fmt.Errorf("rate limit %q: requests must be a positive number", s)
+	}
+	period, err := time.ParseDuration(p)
+	if err != nil || period < time.Second {
+		return models.RateLimit{}, fmt.Errorf("rate limit %q: period must be a duration of at least 1s", s)
+	}
+	return models.RateLimit{Limit: limit, Period: period}, nil
+}
+
+// ParseTrustedProxies reads a comma-separated list of proxy IPs and CIDR ranges, e.g. "10.0.0.0/8, 192.0.2.10";
+// an empty list trusts no proxy
+func ParseTrustedProxies(s string) ([]string, error) {
+	var proxies []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return nil, fmt.Errorf("trusted proxy %q: want an IP or CIDR range", p)
+		}
+		proxies = append(proxies, p)
+	}
+	return proxies, nil
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	l, err := ParseRateLimit(" 600/1m ")
+	if err != nil || l.Limit != 600 || l.Period != time.Minute {
+		t.Errorf("ParseRateLimit(600/1m) = %+v, %v; want 600 per minute", l, err)
+	}
+	for _, s := range []string{"", "600", "0/1m", "-1/1m", "x/1m", "600/", "600/1ms", "600/minute"} {
+		if _, err := ParseRateLimit(s); err == nil {
+			t.Errorf("ParseRateLimit(%q) succeeded; want error", s)
+		}
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	got, err := ParseTrustedProxies(" 10.0.0.0/8, 192.0.2.10,,2001:db8::/32 ")
+	if want := []string{"10.0.0.0/8", "192.0.2.10", "2001:db8::/32"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTrustedProxies = %v, %v; want %v", got, err, want)
+	}
+	if got, err := ParseTrustedProxies(""); err != nil || got != nil {
+		t.Errorf("ParseTrustedProxies(\"\") = %v, %v; want none", got, err)
+	}
+	for _, s := range []string{"proxy.local", "10.0.0.0/33", "192.0.2"} {
+		if _, err := ParseTrustedProxies(s); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) succeeded; want error", s)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import "context"
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+	subjectKey
+)
+
+// WithActor returns ctx carrying who makes the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns who makes the request, AnonymousActor when ctx doesn't say
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// WithRequestID returns ctx carrying the ID of the request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the ID of the request, empty outside of one
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithSubject returns ctx carrying the authenticated caller of the request
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey, subject)
+}
+
+// Subject returns the authenticated caller of the request, empty when it isn't authenticated
+func Subject(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey).(string)
+	return subject
+}
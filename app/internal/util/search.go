@@ -0,0 +1,120 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchField is a text searched for query terms, matches are multiplied by Weight
+type SearchField struct {
+	Text   string
+	Weight float64
+}
+
+// SearchTerms splits a search query into upper-cased words
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToUpper(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchScore ranks how well fields match all terms: every term must match some word
+// exactly, as a prefix or within a few typos, otherwise the score is 0.
+// A field containing the whole query as a phrase gets a bonus, a field equal to it a bigger one.
+func SearchScore(terms []string, fields ...SearchField) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	words := make([][]string, len(fields))
+	for i, f := range fields {
+		words[i] = SearchTerms(f.Text)
+	}
+
+	var total float64
+	for _, term := range terms {
+		var best float64
+		for i, f := range fields {
+			for _, word := range words[i] {
+				if s := termScore(term, word) * f.Weight; s > best {
+					best = s
+				}
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	score := total / float64(len(terms))
+
+	phrase := strings.Join(terms, " ")
+	var bonus float64
+	for i, f := range fields {
+		switch text := strings.Join(words[i], " "); {
+		case text == phrase:
+			bonus = max(bonus, f.Weight)
+		case strings.Contains(text, phrase):
+			bonus = max(bonus, 0.5*f.Weight)
+		}
+	}
+	return score + bonus
+}
+
+// MaxTypos is the edit distance tolerated for a term: none for short terms, more for longer ones
+func MaxTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// termScore scores a single term against a single word
+func termScore(term, word string) float64 {
+	switch {
+	case term == word:
+		return 1
+	case len(term) >= 2 && strings.HasPrefix(word, term):
+		return 0.8
+	}
+	max := MaxTypos(term)
+	if max == 0 {
+		return 0
+	}
+	// compare with the word cut to the term length too, so typos in a prefix still match
+	d := editDistance(term, word)
+	if r := []rune(word); len(r) > len([]rune(term)) {
+		if p := editDistance(term, string(r[:len([]rune(term))])); p < d {
+			d = p
+		}
+	}
+	if d > max {
+		return 0
+	}
+	return 0.6 - 0.1*float64(d-1)
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := SearchTerms("  Bank, of-Warsaw 24 ")
+	want := []string{"BANK", "OF", "WARSAW", "24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchTerms = %v; want %v", got, want)
+	}
+}
+
+func TestSearchScore(t *testing.T) {
+	name := SearchField{Text: "Bank Polska Kasa Opieki", Weight: 1}
+	addr := SearchField{Text: "Grzybowska 53/57, WARSZAWA", Weight: 0.5}
+
+	exact := SearchScore(SearchTerms("polska"), name, addr)
+	prefix := SearchScore(SearchTerms("pols"), name, addr)
+	typo := SearchScore(SearchTerms("plska"), name, addr)
+	if !(exact > prefix && prefix > typo && typo > 0) {
+		t.Errorf("want exact > prefix > typo > 0, got %v, %v, %v", exact, prefix, typo)
+	}
+	if byName, byAddr := SearchScore(SearchTerms("bank"), name, addr), SearchScore(SearchTerms("warszawa"), name, addr); byName <= byAddr {
+		t.Errorf("name match %v should outrank address match %v", byName, byAddr)
+	}
+	if phrase, words := SearchScore(SearchTerms("kasa opieki"), name), SearchScore(SearchTerms("opieki kasa"), name); phrase <= words {
+		t.Errorf("phrase match %v should outrank same words %v", phrase, words)
+	}
+	for _, q := range []string{"bank gdansk", "pko", "", "xyzxyz"} {
+		if s := SearchScore(SearchTerms(q), name, addr); s != 0 {
+			t.Errorf("SearchScore(%q) = %v; want 0", q, s)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"BANK", "BANK", 0},
+		{"BANK", "BNAK", 2},
+		{"WARSAW", "WARSZAWA", 2},
+		{"ŁÓDŹ", "LODZ", 3},
+	}
+	for _, tc := range cases {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d; want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
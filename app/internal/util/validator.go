@@ -2,19 +2,13 @@ package util
 
 import (
 	"strings"
-	"unicode"
 )
 
-// ValidateSwiftCode checks if SWIFT code is 8 or 11 characters long and contains only letters and digits
+// ValidateSwiftCode checks the ISO 9362 structure of SWIFT code, see ValidateBIC,
+// and returns the first rule it breaks; the country isn't looked up
 func ValidateSwiftCode(code string) error {
-	length := len(code)
-	if length != 8 && length != 11 {
-		return WrapError(ErrBadRequest, "SWIFT code must be 8 or 11 characters, got %d", length)
-	}
-	for _, r := range code {
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
-			return WrapError(ErrBadRequest, "SWIFT code can only contain letters and digits")
-		}
+	if v := ValidateBIC(code, nil); !v.Valid {
+		return WrapError(ErrBadRequest, "%s", v.Violations[0].Message)
 	}
 	return nil
 }
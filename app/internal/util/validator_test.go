@@ -21,6 +21,10 @@ func TestValidateSwiftCode(t *testing.T) {
 		"ABCDEFGHIJKLM", // too long
 		"ABCDEFGH!@#",   // forbidden characters
 		"ABCDEFGHXX",    // wrong amount of characters
+		"ABCDÉFGH",      // non-ASCII letter
+		"abcdefgh",      // lowercase
+		"AB12EFGH",      // digits in the institution code
+		"ABCDEFGHX12",   // branch starting with X
 	}
 	for _, code := range invalid {
 		if err := ValidateSwiftCode(code); err == nil {